@@ -7,15 +7,20 @@ import (
 	"io"
 	"io/ioutil"
 	stdlog "log"
-	"net"
 	"net/http"
-	"net/rpc"
 	"time"
 
 	golog "github.com/ipfs/go-log"
 	"github.com/qri-io/apiutil"
+	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/graphql"
 	"github.com/qri-io/qri/lib"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/rpc"
 	"github.com/qri-io/qri/version"
 )
 
@@ -28,10 +33,8 @@ var APIVersion = version.String
 const LocalHostIP = "127.0.0.1"
 
 func init() {
-	// We don't use the log package, and the net/rpc package spits out some complaints b/c
-	// a few methods don't conform to the proper signature (comment this out & run 'qri connect' to see errors)
-	// so we're disabling the log package for now. This is potentially very stupid.
-	// TODO (b5): remove dep on net/rpc package entirely
+	// We don't use the log package, but some dependencies write to it, so we're
+	// disabling the log package for now. This is potentially very stupid.
 	stdlog.SetOutput(ioutil.Discard)
 
 	golog.SetLogLevel("qriapi", "info")
@@ -41,11 +44,25 @@ func init() {
 // Create one with New, start it up with Serve
 type Server struct {
 	*lib.Instance
+	limiter    *rateLimiter
+	signatures *apitoken.Verifier
+	tenants    *tenantRoutes
+	// tenant is set on servers that serve a tenant's routes, which leave out
+	// routes that read or write files on the host
+	tenant bool
 }
 
 // New creates a new qri server from a p2p node & configuration
 func New(inst *lib.Instance) (s Server) {
-	return Server{Instance: inst}
+	s = Server{
+		Instance:   inst,
+		signatures: apitoken.NewVerifier(),
+		tenants:    &tenantRoutes{muxes: map[string]tenantMux{}},
+	}
+	if cfg := inst.Config(); cfg != nil && cfg.API != nil {
+		s.limiter = newRateLimiter(cfg.API.RateLimit, cfg.API.RateLimitBurst)
+	}
+	return s
 }
 
 // Serve starts the server. It will block while the server is running
@@ -58,8 +75,7 @@ func (s Server) Serve(ctx context.Context) (err error) {
 	}
 
 	server := &http.Server{}
-	mux := NewServerRoutes(s)
-	server.Handler = mux
+	server.Handler = s.Handler()
 
 	go s.ServeRPC(ctx)
 	go s.ServeWebsocket(ctx)
@@ -135,34 +151,35 @@ func (s Server) Serve(ctx context.Context) (err error) {
 	return StartServer(cfg.API, server)
 }
 
-// ServeRPC checks for a configured RPC port, and registers a listner if so
+// ServeRPC listens for calls from qri commands on the configured RPC
+// socket, if RPC is enabled
 func (s Server) ServeRPC(ctx context.Context) {
 	cfg := s.Config()
-	if !cfg.RPC.Enabled || cfg.RPC.Port == 0 {
+	if !cfg.RPC.Enabled {
 		return
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", LocalHostIP, cfg.RPC.Port))
+	socket := rpc.SocketPath(s.RepoPath(), cfg.RPC.Socket)
+	listener, srv, err := rpc.Listen(socket)
 	if err != nil {
-		log.Infof("RPC listen on port %d error: %s", cfg.RPC.Port, err)
+		log.Infof("RPC listen on %s error: %s", socket, err)
 		return
 	}
+	srv.Bus = s.Bus()
+	srv.Topics = StreamTopics
 
 	for _, rcvr := range lib.Receivers(s.Instance) {
-		if err := rpc.Register(rcvr); err != nil {
+		if err := srv.Register(rcvr); err != nil {
 			log.Errorf("cannot start RPC: error registering RPC receiver %s: %s", rcvr.CoreRequestsName(), err.Error())
+			listener.Close()
 			return
 		}
 	}
 
-	go func() {
-		<-ctx.Done()
-		log.Info("closing RPC")
-		listener.Close()
-	}()
-
-	rpc.Accept(listener)
-	return
+	if err := srv.Serve(ctx, listener); err != nil {
+		log.Infof("RPC error: %s", err)
+	}
+	log.Info("closed RPC")
 }
 
 // HandleIPFSPath responds to IPFS Hash requests with raw data
@@ -226,85 +243,255 @@ func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{ "meta": { "code": 200, "status": "ok", "versionzz":"` + APIVersion + `" }, "data": [] }`))
 }
 
+// Handler serves the API, sending requests with tenant api tokens to the
+// routes of that tenant
+func (s Server) Handler() http.Handler {
+	return s.tenantHandler(NewServerRoutes(s))
+}
+
 // NewServerRoutes returns a Muxer that has all API routes
 func NewServerRoutes(s Server) *http.ServeMux {
+	return s.routes().mux()
+}
+
+// routes lists every route the server mounts, with the metadata that
+// describes them in the OpenAPI document served at /openapi.json
+func (s Server) routes() *routeTable {
 	node := s.Node()
 	cfg := s.Config()
 
-	m := http.NewServeMux()
+	t := &routeTable{}
+
+	t.handle("/health", s.scopedMiddleware(noAuth, HealthCheckHandler),
+		get("check the node is running", nil).raw("application/json"))
+	t.handle("/openapi.json", s.scopedMiddleware(noAuth, t.OpenAPIHandler),
+		get("OpenAPI document describing this API", nil).raw("application/json"))
+	t.handle("/metrics", s.middleware(s.MetricsHandler()),
+		get("metrics in Prometheus text format", nil).raw("text/plain"))
+	t.handle("/events", s.middleware(s.EventsHandler),
+		get("stream events over a websocket or server-sent events", nil,
+			queryParam("topics", "string", "comma separated event topics to subscribe to"),
+			queryParam("since", "integer", "replay journaled events after this sequence number"),
+		).raw("text/event-stream"))
+	if s.tenant {
+		for _, pattern := range hostRoutes {
+			t.handle(pattern, s.scopedMiddleware(noAuth, hostRouteHandler))
+		}
+	}
 
-	m.Handle("/health", s.middleware(HealthCheckHandler))
-	m.Handle("/ipfs/", s.middleware(s.HandleIPFSPath))
-	m.Handle("/ipns/", s.middleware(s.HandleIPNSPath))
+	// the IPFS gateway serves any content the host node can reach
+	if !s.tenant {
+		t.handle("/ipfs/", s.middleware(s.HandleIPFSPath),
+			get("fetch raw data by IPFS hash", nil, pathParam("hash", "IPFS content hash")).raw("application/octet-stream"))
+		t.handle("/ipns/", s.middleware(s.HandleIPNSPath),
+			get("resolve an IPNS name & fetch its data", nil, pathParam("name", "IPNS name")).raw("application/octet-stream"))
+	}
 
 	proh := NewProfileHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/me", s.middleware(proh.ProfileHandler))
-	m.Handle("/profile", s.middleware(proh.ProfileHandler))
-	m.Handle("/profile/photo", s.middleware(proh.ProfilePhotoHandler))
-	m.Handle("/profile/poster", s.middleware(proh.PosterHandler))
+	profileOps := []operation{
+		get("get this node's profile", &config.ProfilePod{}),
+		post("update this node's profile", &config.ProfilePod{}, &config.ProfilePod{}),
+	}
+	t.handle("/me", s.middleware(proh.ProfileHandler), profileOps...)
+	t.handle("/profile", s.middleware(proh.ProfileHandler), profileOps...)
+	t.handle("/profile/photo", s.middleware(proh.ProfilePhotoHandler),
+		get("get a profile photo", nil,
+			queryParam("peername", "string", "peername of the profile"),
+			queryParam("id", "string", "profile ID"),
+		).raw("image/jpeg"),
+		post("set this node's profile photo", &lib.FileParams{}, &config.ProfilePod{}).also("PUT"))
+	t.handle("/profile/poster", s.middleware(proh.PosterHandler),
+		get("get a profile poster", nil,
+			queryParam("peername", "string", "peername of the profile"),
+			queryParam("id", "string", "profile ID"),
+		).raw("image/jpeg"),
+		post("set this node's profile poster", &lib.FileParams{}, &config.ProfilePod{}).also("PUT"))
 
 	ph := NewPeerHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/peers", s.middleware(ph.PeersHandler))
-	m.Handle("/peers/", s.middleware(ph.PeerHandler))
-	m.Handle("/connect/", s.middleware(ph.ConnectToPeerHandler))
-	m.Handle("/connections", s.middleware(ph.ConnectionsHandler))
+	t.handle("/peers", s.middleware(ph.PeersHandler),
+		get("list peers", []*config.ProfilePod{},
+			queryParam("cached", "boolean", "include offline peers from the repo"),
+		).paged())
+	t.handle("/peers/", s.middleware(ph.PeerHandler),
+		get("get a peer's profile", &config.ProfilePod{}, pathParam("profileID", "base58 profile ID")))
+	t.handle("/connect/", s.scopedMiddleware(apitoken.ScopeAdmin, ph.ConnectToPeerHandler),
+		get("connect to a peer", &config.ProfilePod{}, pathParam("peer", "peername, profile ID or multiaddress")))
+	t.handle("/connections", s.middleware(ph.ConnectionsHandler),
+		get("list connected peer addresses", []string{}))
 
 	if cfg.Remote != nil && cfg.Remote.Enabled {
 		log.Info("running in `remote` mode")
 
 		remh := NewRemoteHandlers(s.Instance)
-		m.Handle("/remote/dsync", s.middleware(remh.DsyncHandler))
-		m.Handle("/remote/logsync", s.middleware(remh.LogsyncHandler))
-		m.Handle("/remote/refs", s.middleware(remh.RefsHandler))
+		// remote endpoints are called by other peers, which check their own
+		// signatures
+		t.handle("/remote/dsync", s.scopedMiddleware(noAuth, remh.DsyncHandler),
+			post("sync dataset blocks with a peer", nil, nil).also("PUT", "GET", "DELETE").raw("application/octet-stream"))
+		t.handle("/remote/logsync", s.scopedMiddleware(noAuth, remh.LogsyncHandler),
+			post("sync logbooks with a peer", nil, nil).also("PUT", "GET", "DELETE").raw("application/octet-stream"))
+		t.handle("/remote/refs", s.scopedMiddleware(noAuth, remh.RefsHandler),
+			get("list references published to this remote", nil).raw("application/json"))
 	}
 
 	dsh := NewDatasetHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/list", s.middleware(dsh.ListHandler))
-	m.Handle("/list/", s.middleware(dsh.PeerListHandler))
-	m.Handle("/save", s.middleware(dsh.SaveHandler))
-	m.Handle("/save/", s.middleware(dsh.SaveHandler))
-	m.Handle("/remove/", s.middleware(dsh.RemoveHandler))
-	m.Handle("/me/", s.middleware(dsh.GetHandler))
-	m.Handle("/add/", s.middleware(dsh.AddHandler))
-	m.Handle("/rename", s.middleware(dsh.RenameHandler))
-	m.Handle("/export/", s.middleware(dsh.ZipDatasetHandler))
-	m.Handle("/diff", s.middleware(dsh.DiffHandler))
-	m.Handle("/body/", s.middleware(dsh.BodyHandler))
-	m.Handle("/stats/", s.middleware(dsh.StatsHandler))
-	m.Handle("/unpack/", s.middleware(dsh.UnpackHandler))
+	t.handle("/list", s.middleware(dsh.ListHandler),
+		get("list this node's datasets", []dsref.VersionInfo{},
+			queryParam("term", "string", "filter datasets by name"),
+		).paged())
+	t.handle("/list/", s.middleware(dsh.PeerListHandler),
+		get("list a peer's datasets", []dsref.VersionInfo{}, pathParam("peername", "peername")).paged())
+	saveOp := post("save a new version of a dataset", &dataset.Dataset{}, &reporef.DatasetRef{},
+		queryParam("new", "boolean", "create a new dataset"),
+		queryParam("private", "boolean", "don't publish the new version"),
+		queryParam("dry_run", "boolean", "don't store the new version"),
+		queryParam("return_body", "boolean", "include the body in the response"),
+		queryParam("force", "boolean", "save even if nothing changed"),
+		queryParam("no_render", "boolean", "don't render the viz"),
+		queryParam("bodypath", "string", "path or URL of a new body"),
+		queryParam("recall", "string", "components to recall from the previous version"),
+		queryParam("drop", "string", "components to drop from the previous version"),
+		queryParam("secrets", "string", "JSON object of secrets for the transform"),
+	).also("PUT")
+	t.handle("/save", s.middleware(dsh.SaveHandler), saveOp)
+	saveOp.params = withRef(saveOp.params...)
+	t.handle("/save/", s.middleware(dsh.SaveHandler), saveOp)
+	t.handle("/remove/", s.middleware(dsh.RemoveHandler),
+		del("remove dataset versions", &lib.RemoveResponse{}, withRef(
+			queryParam("all", "boolean", "remove all versions"),
+			queryParam("keep-files", "boolean", "keep files in the linked working directory"),
+			queryParam("force", "boolean", "remove even if the working directory has changes"),
+		)...).also("POST"))
+	t.handle("/me/", s.middleware(dsh.GetHandler),
+		get("get one of this node's datasets", &reporef.DatasetRef{}, pathParam("name", "dataset name")))
+	t.handle("/add/", s.middleware(dsh.AddHandler),
+		post("add a dataset from a peer", nil, &reporef.DatasetRef{}, withRef(
+			queryParam("dir", "string", "working directory to link the dataset to"),
+		)...).also("PUT"))
+	t.handle("/rename", s.middleware(dsh.RenameHandler),
+		post("rename a dataset", &RenameReqParams{}, &dsref.VersionInfo{}).also("PUT"))
+	t.handle("/export/", s.middleware(dsh.ZipDatasetHandler),
+		get("export a dataset", nil, withRef(
+			queryParam("format", "string", "format of the exported dataset"),
+			queryParam("zipped", "boolean", "zip the export, defaults to true"),
+		)...).raw("application/zip"))
+	t.handle("/diff", s.middleware(dsh.DiffHandler),
+		post("diff two datasets", &lib.DiffParams{}, &lib.DiffResponse{},
+			queryParam("left_path", "string", "reference or path of the left side"),
+			queryParam("right_path", "string", "reference or path of the right side"),
+			queryParam("selector", "string", "component to diff"),
+		).also("GET"))
+	t.handle("/body/", s.middleware(dsh.BodyHandler),
+		get("read body entries. The Accept header picks between JSON, NDJSON, CSV & XLSX", []interface{}{}, withRef(
+			queryParam("offset", "integer", "entry to start from"),
+			queryParam("limit", "integer", "number of entries to read"),
+			queryParam("all", "boolean", "read all entries"),
+			queryParam("cursor", "string", "cursor from the X-Qri-Next-Cursor header of a previous page"),
+			queryParam("download", "boolean", "download the whole body as a file"),
+			queryParam("format", "string", "format of a downloaded body"),
+		)...))
+	t.handle("/stats/", s.middleware(dsh.StatsHandler),
+		get("get summary statistics of a dataset body", []map[string]interface{}{}, refParams...))
+	t.handle("/unpack/", s.middleware(dsh.UnpackHandler),
+		post("unpack a zipped dataset", nil, &dataset.Dataset{}))
+	t.handle("/batch", s.scopedMiddleware(apitoken.ScopeWrite, dsh.BatchHandler),
+		post("save, remove, rename & publish datasets in a batch", &lib.BatchParams{}, []lib.BatchResult{}))
 
 	remClientH := NewRemoteClientHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/publish/", s.middleware(remClientH.PublishHandler))
-	m.Handle("/feeds", s.middleware(remClientH.FeedsHandler))
-	m.Handle("/preview/", s.middleware(remClientH.DatasetPreviewHandler))
-
-	fsih := NewFSIHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/status/", s.middleware(fsih.StatusHandler("/status")))
-	m.Handle("/whatchanged/", s.middleware(fsih.WhatChangedHandler("/whatchanged")))
-	m.Handle("/init/", s.middleware(fsih.InitHandler("/init")))
-	m.Handle("/checkout/", s.middleware(fsih.CheckoutHandler("/checkout")))
-	m.Handle("/restore/", s.middleware(fsih.RestoreHandler("/restore")))
-	m.Handle("/fsi/write/", s.middleware(fsih.WriteHandler("/fsi/write")))
+	t.handle("/publish/", s.publishMiddleware(remClientH.PublishHandler),
+		get("list published datasets", []dsref.VersionInfo{}).paged(),
+		post("publish a dataset to a remote", nil, "ok", withRef(
+			queryParam("remote", "string", "name of the remote"),
+		)...),
+		del("unpublish a dataset from a remote", "ok", withRef(
+			queryParam("remote", "string", "name of the remote"),
+		)...))
+	t.handle("/feeds", s.middleware(remClientH.FeedsHandler),
+		get("get dataset feeds from a remote", map[string][]dsref.VersionInfo{},
+			queryParam("remote", "string", "name of the remote"),
+		))
+	t.handle("/preview/", s.middleware(remClientH.DatasetPreviewHandler),
+		get("preview a dataset on a remote", &dataset.Dataset{}, withRef(
+			queryParam("remote", "string", "name of the remote"),
+		)...))
+
+	// working directories are on the host, tenants can't link datasets to them
+	if !s.tenant {
+		fsih := NewFSIHandlers(s.Instance, cfg.API.ReadOnly)
+		t.handle("/status/", s.middleware(fsih.StatusHandler("/status")),
+			get("get the status of a linked working directory", []lib.StatusItem{}, refParams...))
+		t.handle("/whatchanged/", s.middleware(fsih.WhatChangedHandler("/whatchanged")),
+			get("list what changed in a dataset version", []lib.StatusItem{}, refParams...))
+		t.handle("/init/", s.middleware(fsih.InitHandler("/init")),
+			post("initialize a dataset in a working directory", nil, &reporef.DatasetRef{},
+				queryParam("dir", "string", "working directory"),
+				queryParam("name", "string", "dataset name"),
+				queryParam("format", "string", "body format"),
+				queryParam("mkdir", "string", "directory to create"),
+				queryParam("sourcebodypath", "string", "path of an existing body"),
+			))
+		t.handle("/checkout/", s.middleware(fsih.CheckoutHandler("/checkout")),
+			post("check a dataset out to a working directory", nil, "", withRef(
+				queryParam("dir", "string", "working directory"),
+				queryParam("components", "string", "comma separated components to check out"),
+				queryParam("body_format", "string", "format to write the body in"),
+			)...))
+		t.handle("/restore/", s.middleware(fsih.RestoreHandler("/restore")),
+			post("restore working directory files from a version", nil, "", withRef(
+				queryParam("path", "string", "version to restore from"),
+				queryParam("dir", "string", "working directory"),
+				queryParam("component", "string", "component to restore"),
+			)...))
+		t.handle("/fsi/write/", s.middleware(fsih.WriteHandler("/fsi/write")),
+			post("write dataset components to a working directory", &dataset.Dataset{}, []lib.StatusItem{}, refParams...))
+	}
 
 	renderh := NewRenderHandlers(node.Repo)
-	m.Handle("/render", s.middleware(renderh.RenderHandler))
-	m.Handle("/render/", s.middleware(renderh.RenderHandler))
+	renderOp := post("render a dataset viz or readme", &dataset.Dataset{}, nil,
+		queryParam("viz", "boolean", "render the viz component"),
+		queryParam("fsi", "boolean", "render from the linked working directory"),
+	).also("GET").raw("text/html")
+	t.handle("/render", s.middleware(renderh.RenderHandler), renderOp)
+	renderOp.params = withRef(renderOp.params...)
+	t.handle("/render/", s.middleware(renderh.RenderHandler), renderOp)
 
 	lh := NewLogHandlers(s.Instance)
-	m.Handle("/history/", s.middleware(lh.LogHandler))
+	t.handle("/history/", s.middleware(lh.LogHandler),
+		get("list the versions of a dataset", []DatasetLogItem{}, withRef(
+			queryParam("local", "boolean", "only list versions stored locally"),
+			queryParam("remote", "string", "fetch the log from a remote"),
+		)...).paged())
 
 	rch := NewRegistryClientHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/registry/profile/new", s.middleware(rch.CreateProfileHandler))
-	m.Handle("/registry/profile/prove", s.middleware(rch.ProveProfileKeyHandler))
+	t.handle("/registry/profile/new", s.scopedMiddleware(apitoken.ScopeAdmin, rch.CreateProfileHandler),
+		post("create a profile on the registry", &lib.RegistryProfile{}, &lib.RegistryProfile{}))
+	t.handle("/registry/profile/prove", s.scopedMiddleware(apitoken.ScopeAdmin, rch.ProveProfileKeyHandler),
+		post("prove a key belongs to a registry profile", &lib.RegistryProfile{}, &lib.RegistryProfile{}))
 
 	sh := NewSearchHandlers(s.Instance)
-	m.Handle("/search", s.middleware(sh.SearchHandler))
+	t.handle("/search", s.middleware(sh.SearchHandler),
+		get("search the registry for datasets", []lib.SearchResult{},
+			queryParam("q", "string", "search query"),
+		).paged())
 
 	sqlh := NewSQLHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle("/sql", s.middleware(sqlh.QueryHandler("/sql")))
+	t.handle("/sql", s.middleware(sqlh.QueryHandler("/sql")),
+		post("run an SQL query", &lib.SQLQueryParams{}, nil,
+			queryParam("query", "string", "SQL query"),
+			queryParam("output_format", "string", "json, csv or tabular"),
+		).also("GET"))
+
+	gqlh := NewGraphQLHandlers(s.Instance, cfg.API.ReadOnly)
+	t.handle("/graphql", s.readMiddleware(gqlh.GraphQLHandler),
+		post("run a GraphQL query", &graphql.Request{}, &graphql.Response{},
+			queryParam("query", "string", "GraphQL query"),
+			queryParam("operationName", "string", "operation to run"),
+			queryParam("variables", "string", "JSON object of variables"),
+		).also("GET").raw("application/json"))
 
 	rh := NewRootHandler(dsh, ph)
-	m.Handle("/", s.datasetRefMiddleware(s.middleware(rh.Handler)))
+	t.handle("/", s.datasetRefMiddleware(s.middleware(rh.Handler)),
+		get("get a peer's profile", &config.ProfilePod{}, pathParam("peername", "peername")),
+		get("get a dataset", &reporef.DatasetRef{}, refParams...))
 
-	return m
+	return t
 }
@@ -238,7 +238,6 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"GET", "/export/", 403},
 		{"POST", "/diff", 403},
 		{"GET", "/diff", 403},
-		{"GET", "/body/", 403},
 		{"POST", "/registry/", 403},
 		{"GET", "/checkout", 403},
 		{"GET", "/status", 403},
@@ -255,6 +254,7 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"GET", "/profile/poster?peername=me", 200},
 		{"GET", "/peer/movies", 200},
 		{"GET", "/history/peer/movies", 200},
+		{"GET", "/body/peer/movies", 200},
 	}
 
 	for i, c := range cases {
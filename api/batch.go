@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/lib"
+)
+
+// BatchHandler runs a batch of dataset operations
+func (h *DatasetHandlers) BatchHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, "/batch")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "POST":
+		h.batchHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *DatasetHandlers) batchHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.BatchParams{}
+	if err := json.NewDecoder(r.Body).Decode(p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding batch: %s", err))
+		return
+	}
+	if len(p.Ops) == 0 {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("batch has no operations"))
+		return
+	}
+
+	// publishing on its own requires publish scope, batches are no different
+	if scope, ok := scopeFromCtx(r.Context()); ok && !scope.Allows(apitoken.ScopePublish) {
+		for _, op := range p.Ops {
+			if op.Op == lib.BatchOpPublish {
+				util.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("publish operations require %s scope", apitoken.ScopePublish))
+				return
+			}
+		}
+	}
+
+	if isTenantRequest(r.Context()) {
+		for _, op := range p.Ops {
+			if op.Op == lib.BatchOpSave && op.Save != nil && saveHostPath(op.Save) {
+				util.WriteErrResponse(w, http.StatusForbidden, errHostPath)
+				return
+			}
+		}
+	}
+
+	res := []lib.BatchResult{}
+	if err := h.BatchContext(r.Context(), p, &res); err != nil {
+		reqLog.Infof(r.Context(), "error running batch: %s", err)
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
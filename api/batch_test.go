@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestBatchHandler(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	inst := newTestInstanceWithProfileFromNode(node)
+	h := NewDatasetHandlers(inst, false)
+
+	body := `{"ops":[
+		{"op":"rename","rename":{"Current":{"username":"peer","name":"movies"},"Next":{"username":"peer","name":"films"}}},
+		{"op":"remove","remove":{"Ref":"peer/not_a_dataset","Revision":{"Field":"ds","Gen":-1}}}
+	]}`
+	w := httptest.NewRecorder()
+	h.BatchHandler(w, httptest.NewRequest("POST", "/batch", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	res := struct {
+		Data []lib.BatchResult `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(res.Data))
+	}
+	if res.Data[0].Error != "" || res.Data[0].Rename == nil {
+		t.Errorf("expected rename to succeed, got %+v", res.Data[0])
+	}
+	if res.Data[1].Error == "" {
+		t.Errorf("expected removing a missing dataset to fail")
+	}
+
+	w = httptest.NewRecorder()
+	h.BatchHandler(w, httptest.NewRequest("POST", "/batch", strings.NewReader(`{"ops":[]}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected an empty batch to be a bad request, got status %d", w.Code)
+	}
+
+	publish := `{"ops":[{"op":"publish","publish":{"Ref":"peer/films"}}]}`
+	r := httptest.NewRequest("POST", "/batch", strings.NewReader(publish))
+	r = r.WithContext(context.WithValue(r.Context(), ScopeCtxKey, apitoken.ScopeWrite))
+	w = httptest.NewRecorder()
+	h.BatchHandler(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected publishing with write scope to be forbidden, got status %d", w.Code)
+	}
+
+	h = NewDatasetHandlers(inst, true)
+	w = httptest.NewRecorder()
+	h.BatchHandler(w, httptest.NewRequest("POST", "/batch", strings.NewReader(body)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected read-only server to refuse batches, got status %d", w.Code)
+	}
+}
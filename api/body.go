@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+)
+
+// media types the body endpoint can respond with
+const (
+	mtJSON   = "application/json"
+	mtNDJSON = "application/x-ndjson"
+	mtCSV    = "text/csv"
+	mtXLSX   = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// bodyMediaTypes maps accepted media types & wildcards to the type we respond
+// with
+var bodyMediaTypes = map[string]string{
+	"*/*":                mtJSON,
+	"application/*":      mtJSON,
+	mtJSON:               mtJSON,
+	"application/ndjson": mtNDJSON,
+	mtNDJSON:             mtNDJSON,
+	"text/*":             mtCSV,
+	mtCSV:                mtCSV,
+	mtXLSX:               mtXLSX,
+}
+
+// negotiateBodyMediaType picks the supported media type with the highest
+// quality value from an Accept header. Ties go to the type listed first. An
+// empty header accepts JSON. It returns "" when no listed type is supported
+func negotiateBodyMediaType(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return mtJSON
+	}
+
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		supported, ok := bodyMediaTypes[mt]
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if qs, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qs, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ {
+			best, bestQ = supported, q
+		}
+	}
+	return best
+}
+
+// encodeBodyCursor creates an opaque paging token. Cursors embed the version
+// of the body they were issued for, which keeps a cursor pointing at the same
+// entries for as long as that version of the body exists. Working directory
+// bodies change version whenever the body file is edited
+func encodeBodyCursor(version string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", offset, version)))
+}
+
+// decodeBodyCursor parses a token created by encodeBodyCursor
+func decodeBodyCursor(token string) (version string, offset int, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	parts := strings.SplitN(string(data), ":", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	if offset, err = strconv.Atoi(parts[0]); err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("invalid cursor")
+	}
+	return parts[1], offset, nil
+}
+
+// streamBody writes the entries of a dataset body straight from an entry
+// reader to the response. Paged responses that may have more entries set an
+// X-Qri-Next-Cursor header & a "next" Link header
+func (h DatasetHandlers) streamBody(w http.ResponseWriter, r *http.Request, p *lib.GetParams) {
+	mediaType := negotiateBodyMediaType(r.Header.Get("Accept"))
+	if mediaType == "" {
+		util.WriteErrResponse(w, http.StatusNotAcceptable, fmt.Errorf("body can be served as %s, %s, %s, or %s", mtJSON, mtNDJSON, mtCSV, mtXLSX))
+		return
+	}
+
+	br, err := h.OpenBody(r.Context(), p.Refstr)
+	if err != nil {
+		if err == repo.ErrNoHistory {
+			util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer br.Close()
+
+	bodyPath := br.Dataset.BodyPath
+	if token := r.FormValue("cursor"); token != "" {
+		version, offset, err := decodeBodyCursor(token)
+		if err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		if version != br.Version {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("cursor doesn't match the current version of this dataset"))
+			return
+		}
+		p.Offset = offset
+		p.All = false
+	}
+	if !p.All && p.Limit <= 0 {
+		p.Limit = util.DefaultPageSize
+	}
+
+	var (
+		entries dsio.EntryReader = br
+		nextURL string
+	)
+	if !p.All {
+		entries = &dsio.PagedReader{Reader: br, Limit: p.Limit, Offset: p.Offset}
+		// a known entry count lets us skip handing out a cursor for an empty page
+		total := 0
+		if br.Dataset.Structure != nil && br.FSIPath == "" {
+			total = br.Dataset.Structure.Entries
+		}
+		if next := p.Offset + p.Limit; total == 0 || next < total {
+			cursor := encodeBodyCursor(br.Version, next)
+			nextURL = cursorURL(r, cursor)
+			w.Header().Set("X-Qri-Next-Cursor", cursor)
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", nextURL))
+		}
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	switch mediaType {
+	case mtNDJSON:
+		err = writeNDJSON(w, entries)
+	case mtCSV:
+		err = writeEntries(w, entries, &dataset.Structure{
+			Format:       dataset.CSVDataFormat.String(),
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Schema:       entries.Structure().Schema,
+		})
+	case mtXLSX:
+		err = writeEntries(w, entries, &dataset.Structure{
+			Format: dataset.XLSXDataFormat.String(),
+			Schema: entries.Structure().Schema,
+		})
+	default:
+		err = writeJSONBodyPage(w, bodyPath, nextURL, entries)
+	}
+	if err != nil {
+		// headers have already been sent, the best we can do is stop writing
+		reqLog.Infof(r.Context(), "error streaming body: %s", err)
+	}
+}
+
+// cursorURL is the request URL with paging params replaced by a cursor
+func cursorURL(r *http.Request, cursor string) string {
+	u := *r.URL
+	q := u.Query()
+	for _, key := range []string{"page", "offset", "all"} {
+		q.Del(key)
+	}
+	q.Set("cursor", cursor)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// writeEntries copies entries to w, encoded according to st
+func writeEntries(w io.Writer, entries dsio.EntryReader, st *dataset.Structure) error {
+	ew, err := dsio.NewEntryWriter(st, w)
+	if err != nil {
+		return err
+	}
+	if err := dsio.Copy(entries, ew); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// writeNDJSON writes one JSON value per line. Entries of object bodies are
+// written as single-key objects
+func writeNDJSON(w io.Writer, entries dsio.EntryReader) error {
+	tlt, err := dsio.GetTopLevelType(entries.Structure())
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for {
+		ent, err := entries.ReadEntry()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var v interface{} = ent.Value
+		if tlt == "object" {
+			v = map[string]interface{}{ent.Key: ent.Value}
+		}
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+}
+
+// writeJSONBodyPage streams entries inside the same envelope
+// util.WritePageResponse writes for a DataResponse. Pagination only has a
+// nextUrl when there may be more entries
+func writeJSONBodyPage(w http.ResponseWriter, bodyPath, nextURL string, entries dsio.EntryReader) error {
+	path, err := json.Marshal(bodyPath)
+	if err != nil {
+		return err
+	}
+	pagination := []byte("{}")
+	if nextURL != "" {
+		if pagination, err = json.Marshal(map[string]string{"nextUrl": nextURL}); err != nil {
+			return err
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, `{"data":{"path":%s,"data":`, path); err != nil {
+		return err
+	}
+	if err := writeEntries(w, entries, &dataset.Structure{
+		Format: dataset.JSONDataFormat.String(),
+		Schema: entries.Structure().Schema,
+	}); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, `},"meta":{"code":%d},"pagination":%s}`, http.StatusOK, pagination)
+	return err
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateBodyMediaType(t *testing.T) {
+	cases := []struct {
+		accept, expect string
+	}{
+		{"", mtJSON},
+		{"*/*", mtJSON},
+		{"text/csv", mtCSV},
+		{"text/*", mtCSV},
+		{"application/x-ndjson", mtNDJSON},
+		{"application/ndjson", mtNDJSON},
+		{mtXLSX, mtXLSX},
+		{"text/html, text/csv;q=0.5, application/json;q=0.9", mtJSON},
+		{"text/csv, application/json", mtCSV},
+		{"text/csv;q=0, application/x-ndjson;q=0.1", mtNDJSON},
+		{"text/html", ""},
+		{"image/png, text/csv;q=0", ""},
+	}
+
+	for _, c := range cases {
+		if got := negotiateBodyMediaType(c.accept); got != c.expect {
+			t.Errorf("accept %q: expected %q, got %q", c.accept, c.expect, got)
+		}
+	}
+}
+
+func TestBodyCursor(t *testing.T) {
+	token := encodeBodyCursor("/map/QmBody", 200)
+	path, offset, err := decodeBodyCursor(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/map/QmBody" || offset != 200 {
+		t.Errorf("cursor mismatch. got path %q offset %d", path, offset)
+	}
+
+	for _, bad := range []string{"not a cursor!", encodeBodyCursor("", -1), "Zm9v"} {
+		if _, _, err := decodeBodyCursor(bad); err == nil {
+			t.Errorf("expected %q to be an invalid cursor", bad)
+		}
+	}
+}
+
+func TestBodyHandlerStreaming(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	h := NewDatasetHandlers(run.Inst, true)
+
+	call := func(accept, reqURL string) *http.Response {
+		req := httptest.NewRequest("GET", reqURL, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		h.BodyHandler(w, req)
+		return w.Result()
+	}
+	readBody := func(res *http.Response) string {
+		data, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	res := call("text/csv", "/body/peer/movies?pageSize=2")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 in read-only mode, got %d: %s", res.StatusCode, readBody(res))
+	}
+	if ct := res.Header.Get("Content-Type"); ct != mtCSV {
+		t.Errorf("expected content type %q, got %q", mtCSV, ct)
+	}
+	expect := "movie_title,duration\nAvatar ,178\nPirates of the Caribbean: At World's End ,169\n"
+	if got := readBody(res); got != expect {
+		t.Errorf("csv body mismatch.\nwant: %q\ngot:  %q", expect, got)
+	}
+
+	cursor := res.Header.Get("X-Qri-Next-Cursor")
+	if cursor == "" {
+		t.Fatal("expected a next cursor header")
+	}
+	if !strings.Contains(res.Header.Get("Link"), `rel="next"`) {
+		t.Errorf("expected a next link header, got %q", res.Header.Get("Link"))
+	}
+
+	res = call("application/x-ndjson", "/body/peer/movies?pageSize=2&cursor="+url.QueryEscape(cursor))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", res.StatusCode, readBody(res))
+	}
+	lines := strings.Split(strings.TrimSpace(readBody(res)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 ndjson lines, got %d: %v", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[0], `["Spectre `) {
+		t.Errorf("expected cursor to continue from the third entry, got %s", lines[0])
+	}
+
+	res = call("application/json", "/body/peer/cities?cursor="+url.QueryEscape(cursor))
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a cursor for another body to be rejected, got status %d", res.StatusCode)
+	}
+
+	res = call("image/png", "/body/peer/movies")
+	if res.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("expected status 406, got %d", res.StatusCode)
+	}
+
+	// following nextUrl pages through the body
+	next := "/body/peer/movies?pageSize=2"
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		res = call("application/json", next)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("page %d: expected status 200, got %d: %s", i, res.StatusCode, readBody(res))
+		}
+		page := struct {
+			Data struct {
+				Data [][]interface{} `json:"data"`
+			} `json:"data"`
+			Pagination struct {
+				NextURL string `json:"nextUrl"`
+			} `json:"pagination"`
+		}{}
+		if err := json.Unmarshal([]byte(readBody(res)), &page); err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Data.Data) != 2 {
+			t.Fatalf("page %d: expected 2 entries, got %d", i, len(page.Data.Data))
+		}
+		first := fmt.Sprint(page.Data.Data[0])
+		if seen[first] {
+			t.Fatalf("page %d: nextUrl returned a page that was already seen, starting with %s", i, first)
+		}
+		seen[first] = true
+		if page.Pagination.NextURL == "" {
+			t.Fatalf("page %d: expected a nextUrl", i)
+		}
+		next = page.Pagination.NextURL
+	}
+}
+
+func TestBodyDownload(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	h := NewDatasetHandlers(run.Inst, false)
+	req := httptest.NewRequest("GET", "/body/peer/movies?download=true&format=json&limit=2&offset=1", nil)
+	w := httptest.NewRecorder()
+	h.BodyHandler(w, req)
+	res := w.Result()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", res.StatusCode, data)
+	}
+	if cd := res.Header.Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment; filename=") {
+		t.Errorf("expected an attachment, got Content-Disposition %q", cd)
+	}
+	entries := [][]interface{}{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("expected a json array body: %s\n%s", err, data)
+	}
+	if len(entries) != 2 || entries[0][0] != "Pirates of the Caribbean: At World's End " {
+		t.Errorf("expected 2 entries starting at the second, got: %v", entries)
+	}
+
+	req = httptest.NewRequest("GET", "/body/peer/movies?download=true&format=yaml", nil)
+	w = httptest.NewRecorder()
+	h.BodyHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected an unknown format to be a bad request, got status %d", w.Code)
+	}
+}
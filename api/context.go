@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/qri-io/qri/apitoken"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
@@ -55,3 +56,22 @@ func HTTPPathToQriPath(path string) string {
 	path = strings.TrimPrefix(path, "/")
 	return path
 }
+
+// ScopeCtxKey is the key for the scope a request is authorized for. It's
+// only set when the API requires authorization
+const ScopeCtxKey QriCtxKey = "scope"
+
+// scopeFromCtx returns the scope a request is authorized for, if any
+func scopeFromCtx(ctx context.Context) (apitoken.Scope, bool) {
+	scope, ok := ctx.Value(ScopeCtxKey).(apitoken.Scope)
+	return scope, ok
+}
+
+// TenantCtxKey marks requests a tenant's routes are serving
+const TenantCtxKey QriCtxKey = "tenant"
+
+// isTenantRequest reports whether a request is served by a tenant's routes
+func isTenantRequest(ctx context.Context) bool {
+	tenant, _ := ctx.Value(TenantCtxKey).(bool)
+	return tenant
+}
@@ -14,6 +14,7 @@ import (
 
 	util "github.com/qri-io/apiutil"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/qri/base/archive"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/lib"
@@ -146,16 +147,13 @@ func (h *DatasetHandlers) RenameHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// BodyHandler gets the contents of a dataset
+// BodyHandler streams the body of a dataset in a format negotiated from the
+// request Accept header
 func (h *DatasetHandlers) BodyHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "OPTIONS":
 		util.EmptyOkHandler(w, r)
 	case "GET":
-		if h.ReadOnly {
-			readOnlyResponse(w, "/body/")
-			return
-		}
 		h.bodyHandler(w, r)
 	default:
 		util.NotFoundHandler(w, r)
@@ -221,7 +219,7 @@ func (h *DatasetHandlers) zipDatasetHandler(w http.ResponseWriter, r *http.Reque
 
 	var fileWritten string
 	req := lib.NewExportRequests(h.node, nil)
-	err = req.Export(&params, &fileWritten)
+	err = req.ExportContext(r.Context(), &params, &fileWritten)
 	if err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
@@ -265,13 +263,13 @@ func (h *DatasetHandlers) listHandler(w http.ResponseWriter, r *http.Request) {
 	args.Term = r.FormValue("term")
 
 	res := []dsref.VersionInfo{}
-	if err := h.List(&args, &res); err != nil {
-		log.Infof("error listing datasets: %s", err.Error())
+	if err := h.ListContext(r.Context(), &args, &res); err != nil {
+		reqLog.Infof(r.Context(), "error listing datasets: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
 	if err := util.WritePageResponse(w, res, r, args.Page()); err != nil {
-		log.Infof("error list datasests response: %s", err.Error())
+		reqLog.Infof(r.Context(), "error list datasests response: %s", err)
 	}
 }
 
@@ -285,7 +283,7 @@ func (h *DatasetHandlers) getHandler(w http.ResponseWriter, r *http.Request) {
 		Refstr: HTTPPathToQriPath(r.URL.Path),
 	}
 	res := lib.GetResult{}
-	err := h.Get(&p, &res)
+	err := h.GetContext(r.Context(), &p, &res)
 	if err != nil {
 		if err == repo.ErrNoHistory {
 			util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
@@ -329,8 +327,14 @@ func (h *DatasetHandlers) diffHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// paths that aren't dataset references are body files
+	if isTenantRequest(r.Context()) && (req.WorkingDir != "" || !dsref.IsRefString(req.LeftPath) || (req.RightPath != "" && !dsref.IsRefString(req.RightPath))) {
+		util.WriteErrResponse(w, http.StatusForbidden, errHostPath)
+		return
+	}
+
 	res := &lib.DiffResponse{}
-	if err := h.Diff(req, res); err != nil {
+	if err := h.DiffContext(r.Context(), req, res); err != nil {
 		fmt.Println(err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("error generating diff: %s", err.Error()))
 		return
@@ -340,7 +344,7 @@ func (h *DatasetHandlers) diffHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *DatasetHandlers) peerListHandler(w http.ResponseWriter, r *http.Request) {
-	log.Info(r.URL.Path)
+	reqLog.Infof(r.Context(), "%s", r.URL.Path)
 	p := lib.ListParamsFromRequest(r)
 	p.OrderBy = "created"
 
@@ -363,13 +367,13 @@ func (h *DatasetHandlers) peerListHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	res := []dsref.VersionInfo{}
-	if err := h.List(&p, &res); err != nil {
-		log.Infof("error listing peer's datasets: %s", err.Error())
+	if err := h.ListContext(r.Context(), &p, &res); err != nil {
+		reqLog.Infof(r.Context(), "error listing peer's datasets: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
 	if err := util.WritePageResponse(w, res, r, p.Page()); err != nil {
-		log.Infof("error list datasests response: %s", err.Error())
+		reqLog.Infof(r.Context(), "error list datasests response: %s", err)
 	}
 }
 
@@ -391,8 +395,13 @@ func (h *DatasetHandlers) addHandler(w http.ResponseWriter, r *http.Request) {
 		LinkDir: r.FormValue("dir"),
 	}
 
+	if isTenantRequest(r.Context()) && p.LinkDir != "" {
+		util.WriteErrResponse(w, http.StatusForbidden, errHostPath)
+		return
+	}
+
 	res := reporef.DatasetRef{}
-	err = h.Add(p, &res)
+	err = h.AddContext(r.Context(), p, &res)
 	if err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
@@ -457,6 +466,9 @@ func (h *DatasetHandlers) saveHandler(w http.ResponseWriter, r *http.Request) {
 		BodyPath:     r.FormValue("bodypath"),
 		Recall:       r.FormValue("recall"),
 		Drop:         r.FormValue("drop"),
+		RecordHTTP:   r.FormValue("record") == "true",
+		ReplayHTTP:   r.FormValue("replay") == "true",
+		Cascade:      r.FormValue("cascade") == "true",
 
 		ConvertFormatToPrev: true,
 		ScriptOutput:        scriptOutput,
@@ -473,7 +485,12 @@ func (h *DatasetHandlers) saveHandler(w http.ResponseWriter, r *http.Request) {
 		p.Secrets = ds.Transform.Secrets
 	}
 
-	if err := h.Save(p, res); err != nil {
+	if isTenantRequest(r.Context()) && saveHostPath(p) {
+		util.WriteErrResponse(w, http.StatusForbidden, errHostPath)
+		return
+	}
+
+	if err := h.SaveContext(r.Context(), p, res); err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -496,8 +513,8 @@ func (h *DatasetHandlers) removeHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	res := lib.RemoveResponse{}
-	if err := h.Remove(&p, &res); err != nil {
-		log.Infof("error deleting dataset: %s", err.Error())
+	if err := h.RemoveContext(r.Context(), &p, &res); err != nil {
+		reqLog.Infof(r.Context(), "error deleting dataset: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -541,8 +558,8 @@ func (h DatasetHandlers) renameHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	res := &dsref.VersionInfo{}
-	if err := h.Rename(p, res); err != nil {
-		log.Infof("error renaming dataset: %s", err.Error())
+	if err := h.RenameContext(r.Context(), p, res); err != nil {
+		reqLog.Infof(r.Context(), "error renaming dataset: %s", err)
 		util.WriteErrResponse(w, http.StatusBadRequest, err)
 		return
 	}
@@ -624,8 +641,23 @@ func (h DatasetHandlers) bodyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := &lib.GetResult{}
-	if err := h.Get(p, result); err != nil {
+	if r.FormValue("download") == "true" {
+		h.bodyDownloadHandler(w, r, p)
+		return
+	}
+	h.streamBody(w, r, p)
+}
+
+// bodyDownloadHandler writes a body as a file attachment, streaming entries
+// from the stored body into the requested format
+func (h DatasetHandlers) bodyDownloadHandler(w http.ResponseWriter, r *http.Request, p *lib.GetParams) {
+	if _, err := dataset.ParseDataFormatString(p.Format); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	br, err := h.OpenBody(r.Context(), p.Refstr)
+	if err != nil {
 		if err == repo.ErrNoHistory {
 			util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
 			return
@@ -633,29 +665,34 @@ func (h DatasetHandlers) bodyHandler(w http.ResponseWriter, r *http.Request) {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
+	defer br.Close()
 
-	download := r.FormValue("download") == "true"
-	if download {
-		filename, err := archive.GenerateFilename(result.Dataset, p.Format)
-		if err != nil {
-			util.WriteErrResponse(w, http.StatusInternalServerError, err)
-			return
-		}
-		w.Header().Set("Content-Type", extensionToMimeType("."+p.Format))
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-		w.Write(result.Bytes)
+	filename, err := archive.GenerateFilename(br.Dataset, p.Format)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	page := util.PageFromRequest(r)
-	path := result.Dataset.BodyPath
+	// an empty format keeps the body's own format & config
+	st := &dataset.Structure{}
+	st.Assign(br.Structure(), &dataset.Structure{
+		Format: p.Format,
+		Schema: br.Structure().Schema,
+	})
+	if p.FormatConfig != nil {
+		st.FormatConfig = p.FormatConfig.Map()
+	}
 
-	dataResponse := DataResponse{
-		Path: path,
-		Data: json.RawMessage(result.Bytes),
+	var entries dsio.EntryReader = br
+	if !p.All {
+		entries = &dsio.PagedReader{Reader: br, Limit: p.Limit, Offset: p.Offset}
 	}
-	if err := util.WritePageResponse(w, dataResponse, r, page); err != nil {
-		log.Infof("error writing response: %s", err.Error())
+
+	w.Header().Set("Content-Type", extensionToMimeType("."+p.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	if err := writeEntries(w, entries, st); err != nil {
+		// headers have already been sent, the best we can do is stop writing
+		reqLog.Infof(r.Context(), "error streaming body download: %s", err)
 	}
 }
 
@@ -665,7 +702,7 @@ func (h DatasetHandlers) statsHandler(w http.ResponseWriter, r *http.Request) {
 		Selector: "stats",
 	}
 	res := lib.GetResult{}
-	err := h.Get(&p, &res)
+	err := h.GetContext(r.Context(), &p, &res)
 	if err != nil {
 		if err == repo.ErrNoHistory {
 			util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
@@ -677,12 +714,12 @@ func (h DatasetHandlers) statsHandler(w http.ResponseWriter, r *http.Request) {
 
 	statsMap := &[]map[string]interface{}{}
 	if err := json.Unmarshal(res.Bytes, statsMap); err != nil {
-		log.Errorf("error unmarshalling stats: %s", err)
+		reqLog.Errorf(r.Context(), "error unmarshalling stats: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("error writing stats"))
 		return
 	}
 	if err := util.WriteResponse(w, statsMap); err != nil {
-		log.Infof("error writing response: %s", err.Error())
+		reqLog.Infof(r.Context(), "error writing response: %s", err)
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	util "github.com/qri-io/apiutil"
 	"github.com/qri-io/dataset"
@@ -62,7 +63,7 @@ func (h *FSIHandlers) statusHandler(routePrefix string) http.HandlerFunc {
 
 		res := []lib.StatusItem{}
 		alias := ref.AliasString()
-		err = h.StatusForAlias(&alias, &res)
+		err = h.StatusForAliasContext(r.Context(), &alias, &res)
 		if err == fsi.ErrNoLink {
 			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("no working directory: %s", alias))
 			return
@@ -106,7 +107,7 @@ func (h *FSIHandlers) whatChangedHandler(routePrefix string) http.HandlerFunc {
 
 		res := []lib.StatusItem{}
 		refStr := ref.String()
-		err = h.WhatChanged(&refStr, &res)
+		err = h.WhatChangedContext(r.Context(), &refStr, &res)
 		if err != nil {
 			if err == repo.ErrNoHistory {
 				util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
@@ -168,7 +169,7 @@ func (h *FSIHandlers) initHandler(routePrefix string) http.HandlerFunc {
 			Refstr: name,
 		}
 		res := lib.GetResult{}
-		err := h.dsm.Get(&gp, &res)
+		err := h.dsm.GetContext(r.Context(), &gp, &res)
 		if err != nil {
 			if err == repo.ErrNotFound {
 				util.NotFoundHandler(w, r)
@@ -238,7 +239,7 @@ func (h *FSIHandlers) writeHandler(routePrefix string) http.HandlerFunc {
 		}
 
 		out := []lib.StatusItem{}
-		if err := h.Write(p, &out); err != nil {
+		if err := h.WriteContext(r.Context(), p, &out); err != nil {
 			util.WriteErrResponse(w, http.StatusBadRequest, err)
 			return
 		}
@@ -279,9 +280,16 @@ func (h *FSIHandlers) checkoutHandler(routePrefix string) http.HandlerFunc {
 			Dir: r.FormValue("dir"),
 			Ref: ref.String(),
 		}
+		if comps := r.FormValue("components"); comps != "" {
+			p.Components = strings.Split(comps, ",")
+		}
+		if bodyLimit, err := util.ReqParamInt("body_limit", r); err == nil {
+			p.BodyLimit = bodyLimit
+		}
+		p.BodyFormat = r.FormValue("body_format")
 
 		var res string
-		if err := h.Checkout(p, &res); err != nil {
+		if err := h.CheckoutContext(r.Context(), p, &res); err != nil {
 			util.WriteErrResponse(w, http.StatusInternalServerError, err)
 			return
 		}
@@ -328,7 +336,7 @@ func (h *FSIHandlers) restoreHandler(routePrefix string) http.HandlerFunc {
 		}
 
 		var res string
-		if err := h.Restore(p, &res); err != nil {
+		if err := h.RestoreContext(r.Context(), p, &res); err != nil {
 			util.WriteErrResponse(w, http.StatusInternalServerError, err)
 			return
 		}
@@ -182,7 +182,9 @@ func TestNoHistory(t *testing.T) {
 	}
 
 	// Expected response for body of the dataset
-	expectBody = `{"data":{"path":"fsi_init_dir/body.csv","data":[["one","two",3],["four","five",6]]},"meta":{"code":200},"pagination":{"nextUrl":"/body/peer/test_ds?page=2"}}`
+	// working directory bodies have no known entry count, so always get a cursor
+	cursor := encodeBodyCursor(filepath.Join(workDir, "body.csv"), lib.DefaultPageSize)
+	expectBody = `{"data":{"path":"fsi_init_dir/body.csv","data":[["one","two",3],["four","five",6]]},"meta":{"code":200},"pagination":{"nextUrl":"/body/peer/test_ds?cursor=` + cursor + `"}}`
 
 	// Body with no history, but fsi working directory has body
 	gotStatusCode, gotBodyString = APICall("/body/peer/test_ds", dsHandler.BodyHandler)
@@ -0,0 +1,567 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/graphql"
+	"github.com/qri-io/qri/lib"
+)
+
+const (
+	// DefaultGraphQLMaxDepth is the default limit on how deeply GraphQL queries
+	// can nest selections
+	DefaultGraphQLMaxDepth = 8
+	// DefaultGraphQLMaxCost is the default limit on the estimated cost of a
+	// GraphQL query
+	DefaultGraphQLMaxCost = 1000
+)
+
+// GraphQLHandlers serves read-only GraphQL queries over datasets, their
+// history, peers & profiles
+type GraphQLHandlers struct {
+	ReadOnly bool
+	// MaxDepth limits how deeply queries can nest. 0 means no limit
+	MaxDepth int
+	// MaxCost limits the estimated cost of a query. 0 means no limit
+	MaxCost int
+
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandlers allocates a GraphQLHandlers pointer
+func NewGraphQLHandlers(inst *lib.Instance, readOnly bool) *GraphQLHandlers {
+	return &GraphQLHandlers{
+		ReadOnly: readOnly,
+		MaxDepth: DefaultGraphQLMaxDepth,
+		MaxCost:  DefaultGraphQLMaxCost,
+		schema:   newGraphQLSchema(inst),
+	}
+}
+
+// GraphQLHandler executes GraphQL queries sent as GET query parameters or
+// POST bodies
+func (h *GraphQLHandlers) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	if h.ReadOnly {
+		readOnlyResponse(w, "/graphql")
+		return
+	}
+
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET", "POST":
+		h.graphqlHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *GraphQLHandlers) graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	req := graphql.Request{}
+	switch {
+	case r.Method == "GET":
+		req.Query = r.FormValue("query")
+		req.OperationName = r.FormValue("operationName")
+		if vars := r.FormValue("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid variables: %s", err))
+				return
+			}
+		}
+	case strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql"):
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		req.Query = string(data)
+	default:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %s", err))
+			return
+		}
+	}
+
+	if req.Query == "" {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+
+	res := graphql.Do(r.Context(), h.schema, req, func(o *graphql.Options) {
+		o.MaxDepth = h.MaxDepth
+		o.MaxCost = h.MaxCost
+	})
+
+	// requests that fail before execution have no data
+	status := http.StatusOK
+	if res.Data == nil {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		reqLog.Infof(r.Context(), "error writing graphql response: %s", err)
+	}
+}
+
+// gqlDataset is the source value of the Dataset type. ref is the reference
+// the dataset was loaded with, so fields that load more data read the same
+// version, or the same working directory
+type gqlDataset struct {
+	ref string
+	res *lib.GetResult
+}
+
+// datasetField resolves a field from a gqlDataset
+func datasetField(fn func(d *gqlDataset) interface{}) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(*gqlDataset)), nil
+	}
+}
+
+// componentScript resolves the script of a readme, transform or viz
+func componentScript(p gql.ResolveParams) (interface{}, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch c := p.Source.(type) {
+	case *dataset.Readme:
+		data, err = readScript(c.ScriptFile(), c.ScriptBytes)
+	case *dataset.Transform:
+		data, err = readScript(c.ScriptFile(), c.ScriptBytes)
+	case *dataset.Viz:
+		data, err = readScript(c.ScriptFile(), c.ScriptBytes)
+	}
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func readScript(f qfs.File, script []byte) ([]byte, error) {
+	if f == nil {
+		return script, nil
+	}
+	return ioutil.ReadAll(f)
+}
+
+// versionRef is a reference to the exact version info describes
+func versionRef(info *dsref.VersionInfo) string {
+	return dsref.Ref{Username: info.Username, Name: info.Name, Path: info.Path}.String()
+}
+
+func pageArgs(limit int) gql.FieldConfigArgument {
+	return gql.FieldConfigArgument{
+		"offset": {Type: gql.Int, DefaultValue: 0},
+		"limit":  {Type: gql.Int, DefaultValue: limit},
+	}
+}
+
+func newGraphQLSchema(inst *lib.Instance) *graphql.Schema {
+	dsm := lib.NewDatasetMethods(inst)
+	logm := lib.NewLogMethods(inst)
+	peerm := lib.NewPeerMethods(inst)
+	prom := lib.NewProfileMethods(inst)
+
+	getDataset := func(ctx context.Context, ref string) (interface{}, error) {
+		res := &lib.GetResult{}
+		if err := dsm.GetContext(ctx, &lib.GetParams{Refstr: ref}, res); err != nil {
+			return nil, err
+		}
+		return &gqlDataset{ref: ref, res: res}, nil
+	}
+
+	profile := gql.NewObject(gql.ObjectConfig{
+		Name: "Profile",
+		Fields: gql.Fields{
+			"id":          {Type: gql.NewNonNull(gql.String)},
+			"peername":    {Type: gql.String},
+			"name":        {Type: gql.String},
+			"description": {Type: gql.String},
+			"email":       {Type: gql.String},
+			"homeurl":     {Type: gql.String},
+			"color":       {Type: gql.String},
+			"thumb":       {Type: gql.String},
+			"photo":       {Type: gql.String},
+			"poster":      {Type: gql.String},
+			"twitter":     {Type: gql.String},
+			"type":        {Type: gql.String},
+			"created":     {Type: gql.String},
+			"updated":     {Type: gql.String},
+			"online":      {Type: gql.Boolean},
+		},
+	})
+
+	user := gql.NewObject(gql.ObjectConfig{
+		Name: "User",
+		Fields: gql.Fields{
+			"id":    {Type: gql.String},
+			"name":  {Type: gql.String},
+			"email": {Type: gql.String},
+		},
+	})
+
+	commit := gql.NewObject(gql.ObjectConfig{
+		Name: "Commit",
+		Fields: gql.Fields{
+			"title":     {Type: gql.String},
+			"message":   {Type: gql.String},
+			"timestamp": {Type: gql.String},
+			"author":    {Type: user},
+			"signature": {Type: gql.String},
+			"path":      {Type: gql.String},
+		},
+	})
+
+	meta := gql.NewObject(gql.ObjectConfig{
+		Name: "Meta",
+		Fields: gql.Fields{
+			"title":              {Type: gql.String},
+			"description":        {Type: gql.String},
+			"keywords":           {Type: gql.NewList(gql.String)},
+			"theme":              {Type: gql.NewList(gql.String)},
+			"language":           {Type: gql.NewList(gql.String)},
+			"accessURL":          {Type: gql.String},
+			"downloadURL":        {Type: gql.String},
+			"homeURL":            {Type: gql.String},
+			"readmeURL":          {Type: gql.String},
+			"accrualPeriodicity": {Type: gql.String},
+			"identifier":         {Type: gql.String},
+			"version":            {Type: gql.String},
+			"license":            {Type: graphql.JSON},
+			"citations":          {Type: graphql.JSON},
+			"contributors":       {Type: graphql.JSON},
+			"path":               {Type: gql.String},
+		},
+	})
+
+	structure := gql.NewObject(gql.ObjectConfig{
+		Name: "Structure",
+		Fields: gql.Fields{
+			"format":       {Type: gql.String},
+			"formatConfig": {Type: graphql.JSON},
+			"length":       {Type: gql.Int},
+			"entries":      {Type: gql.Int},
+			"depth":        {Type: gql.Int},
+			"errCount":     {Type: gql.Int},
+			"checksum":     {Type: gql.String},
+			"encoding":     {Type: gql.String},
+			"compression":  {Type: gql.String},
+			"strict":       {Type: gql.Boolean},
+			"schema":       {Type: graphql.JSON},
+			"path":         {Type: gql.String},
+		},
+	})
+
+	readme := gql.NewObject(gql.ObjectConfig{
+		Name: "Readme",
+		Fields: gql.Fields{
+			"format": {Type: gql.String},
+			"script": {Type: gql.String, Resolve: componentScript},
+			"path":   {Type: gql.String},
+		},
+	})
+
+	transform := gql.NewObject(gql.ObjectConfig{
+		Name: "Transform",
+		Fields: gql.Fields{
+			"syntax":        {Type: gql.String},
+			"syntaxVersion": {Type: gql.String},
+			"config":        {Type: graphql.JSON},
+			"script":        {Type: gql.String, Resolve: componentScript},
+			"path":          {Type: gql.String},
+		},
+	})
+
+	viz := gql.NewObject(gql.ObjectConfig{
+		Name: "Viz",
+		Fields: gql.Fields{
+			"format": {Type: gql.String},
+			"script": {Type: gql.String, Resolve: componentScript},
+			"path":   {Type: gql.String},
+		},
+	})
+
+	logEntry := gql.NewObject(gql.ObjectConfig{
+		Name: "LogEntry",
+		Fields: gql.Fields{
+			"timestamp": {Type: gql.String},
+			"author":    {Type: gql.String},
+			"action":    {Type: gql.String},
+			"note":      {Type: gql.String},
+		},
+	})
+
+	// DatasetInfo & Dataset refer to each other, Dataset fields are read once
+	// the schema is built
+	var dsFields gql.Fields
+	ds := gql.NewObject(gql.ObjectConfig{
+		Name:   "Dataset",
+		Fields: gql.FieldsThunk(func() gql.Fields { return dsFields }),
+	})
+
+	infoFields := gql.Fields{
+		"username":  {Type: gql.String},
+		"profileID": {Type: gql.String},
+		"name":      {Type: gql.String},
+		"path":      {Type: gql.String},
+		"published": {Type: gql.Boolean},
+		"foreign":   {Type: gql.Boolean},
+		"metaTitle": {Type: gql.String},
+		"themeList": {Type: gql.String},
+		"bodySize":  {Type: gql.Int},
+		"bodyRows":  {Type: gql.Int},
+		"bodyFormat": {Type: gql.String, Resolve: func(p gql.ResolveParams) (interface{}, error) {
+			return p.Source.(*dsref.VersionInfo).BodyFormat, nil
+		}},
+		"numErrors":   {Type: gql.Int},
+		"commitTime":  {Type: gql.String},
+		"numVersions": {Type: gql.Int},
+		"fsiPath":     {Type: gql.String},
+		"dataset": {
+			Type:        ds,
+			Description: "the full dataset at this version",
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				return getDataset(p.Context, versionRef(p.Source.(*dsref.VersionInfo)))
+			},
+		},
+	}
+	info := gql.NewObject(gql.ObjectConfig{Name: "DatasetInfo", Fields: infoFields})
+
+	historyFields := gql.Fields{}
+	for name, f := range infoFields {
+		f := *f
+		if f.Resolve != nil {
+			resolve := f.Resolve
+			f.Resolve = func(p gql.ResolveParams) (interface{}, error) {
+				item := p.Source.(lib.DatasetLogItem)
+				p.Source = &item.VersionInfo
+				return resolve(p)
+			}
+		}
+		historyFields[name] = &f
+	}
+	historyFields["commitTitle"] = &gql.Field{Type: gql.String}
+	historyFields["commitMessage"] = &gql.Field{Type: gql.String}
+	historyItem := gql.NewObject(gql.ObjectConfig{Name: "HistoryItem", Fields: historyFields})
+
+	dsFields = gql.Fields{
+		"username": {Type: gql.String, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Ref.Username
+		})},
+		"profileID": {Type: gql.String, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Ref.ProfileID
+		})},
+		"name": {Type: gql.String, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Ref.Name
+		})},
+		"path": {Type: gql.String, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Ref.Path
+		})},
+		"fsiPath": {Type: gql.String, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.FSIPath
+		})},
+		"published": {Type: gql.Boolean, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Published
+		})},
+		"commit": {Type: commit, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Dataset.Commit
+		})},
+		"meta": {Type: meta, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Dataset.Meta
+		})},
+		"structure": {Type: structure, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Dataset.Structure
+		})},
+		"readme": {Type: readme, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Dataset.Readme
+		})},
+		"transform": {Type: transform, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Dataset.Transform
+		})},
+		"viz": {Type: viz, Resolve: datasetField(func(d *gqlDataset) interface{} {
+			return d.res.Dataset.Viz
+		})},
+		"body": {
+			Type:        graphql.JSON,
+			Description: "a page of body entries. all ignores offset & limit",
+			Args: gql.FieldConfigArgument{
+				"offset": {Type: gql.Int, DefaultValue: 0},
+				"limit":  {Type: gql.Int, DefaultValue: 50},
+				"all":    {Type: gql.Boolean, DefaultValue: false},
+			},
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				d := p.Source.(*gqlDataset)
+				res := &lib.GetResult{}
+				params := &lib.GetParams{
+					Refstr:   d.ref,
+					Selector: "body",
+					Format:   "json",
+					Offset:   p.Args["offset"].(int),
+					Limit:    p.Args["limit"].(int),
+					All:      p.Args["all"].(bool),
+				}
+				if err := dsm.GetContext(p.Context, params, res); err != nil {
+					return nil, err
+				}
+				return res.Bytes, nil
+			},
+		},
+		"stats": {
+			Type:        graphql.JSON,
+			Description: "summary statistics of the body",
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				d := p.Source.(*gqlDataset)
+				res := &lib.StatsResponse{}
+				ref := dsref.Ref{Username: d.res.Ref.Username, Name: d.res.Ref.Name, Path: d.res.Ref.Path}
+				if err := dsm.StatsContext(p.Context, &lib.StatsParams{Ref: ref.String()}, res); err != nil {
+					return nil, err
+				}
+				return res.StatsBytes, nil
+			},
+		},
+		"history": {
+			Type: gql.NewList(historyItem),
+			Args: pageArgs(25),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				d := p.Source.(*gqlDataset)
+				params := &lib.LogParams{
+					Ref: d.res.Ref.Alias(),
+					ListParams: lib.ListParams{
+						Offset: p.Args["offset"].(int),
+						Limit:  p.Args["limit"].(int),
+					},
+				}
+				res := []lib.DatasetLogItem{}
+				if err := logm.LogContext(p.Context, params, &res); err != nil {
+					return nil, err
+				}
+				return res, nil
+			},
+		},
+		"logbook": {
+			Type: gql.NewList(logEntry),
+			Args: pageArgs(25),
+			Resolve: func(p gql.ResolveParams) (interface{}, error) {
+				d := p.Source.(*gqlDataset)
+				params := &lib.RefListParams{
+					Ref:    d.res.Ref.Alias(),
+					Offset: p.Args["offset"].(int),
+					Limit:  p.Args["limit"].(int),
+				}
+				res := []lib.LogEntry{}
+				if err := logm.LogbookContext(p.Context, params, &res); err != nil {
+					return nil, err
+				}
+				return res, nil
+			},
+		},
+	}
+
+	datasetsArgs := pageArgs(25)
+	datasetsArgs["term"] = &gql.ArgumentConfig{Type: gql.String, DefaultValue: ""}
+
+	peersArgs := pageArgs(25)
+	peersArgs["cached"] = &gql.ArgumentConfig{Type: gql.Boolean, DefaultValue: false}
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"dataset": {
+				Type: ds,
+				Args: gql.FieldConfigArgument{"ref": {Type: gql.NewNonNull(gql.String)}},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return getDataset(p.Context, p.Args["ref"].(string))
+				},
+			},
+			"datasets": {
+				Type: gql.NewList(gql.NewNonNull(info)),
+				Args: datasetsArgs,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					params := &lib.ListParams{
+						Term:   p.Args["term"].(string),
+						Offset: p.Args["offset"].(int),
+						Limit:  p.Args["limit"].(int),
+					}
+					res := []dsref.VersionInfo{}
+					if err := dsm.ListContext(p.Context, params, &res); err != nil {
+						return nil, err
+					}
+					infos := make([]*dsref.VersionInfo, len(res))
+					for i := range res {
+						infos[i] = &res[i]
+					}
+					return infos, nil
+				},
+			},
+			"me": {
+				Type: profile,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					in := true
+					res := &config.ProfilePod{}
+					if err := prom.GetProfile(&in, res); err != nil {
+						return nil, err
+					}
+					return res, nil
+				},
+			},
+			"peers": {
+				Type: gql.NewList(gql.NewNonNull(profile)),
+				Args: peersArgs,
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					params := &lib.PeerListParams{
+						Offset: p.Args["offset"].(int),
+						Limit:  p.Args["limit"].(int),
+						Cached: p.Args["cached"].(bool),
+					}
+					res := []*config.ProfilePod{}
+					if err := peerm.List(params, &res); err != nil {
+						return nil, err
+					}
+					return res, nil
+				},
+			},
+			"peer": {
+				Type: profile,
+				Args: gql.FieldConfigArgument{"peername": {Type: gql.NewNonNull(gql.String)}},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					res := &config.ProfilePod{}
+					if err := peerm.Info(&lib.PeerInfoParams{Peername: p.Args["peername"].(string)}, res); err != nil {
+						return nil, err
+					}
+					return res, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(query, map[string]int{
+		"Query.dataset":       5,
+		"Query.datasets":      5,
+		"Query.peers":         5,
+		"Query.peer":          5,
+		"DatasetInfo.dataset": 5,
+		"HistoryItem.dataset": 5,
+		"Dataset.body":        10,
+		"Dataset.stats":       10,
+		"Dataset.history":     5,
+		"Dataset.logbook":     5,
+	})
+	if err != nil {
+		// the schema is fixed, an invalid one is a bug
+		panic(err)
+	}
+	return schema
+}
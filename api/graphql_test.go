@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGraphQLHandler(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	inst := newTestInstanceWithProfileFromNode(node)
+	h := NewGraphQLHandlers(inst, false)
+
+	query := `query ($ref: String!) {
+		dataset(ref: $ref) {
+			name
+			commit { title }
+			structure { format entries }
+			body(limit: 2)
+			history(limit: 1) { path commitTitle }
+		}
+		datasets(limit: 1) { name dataset { name } }
+		me { peername }
+	}`
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": map[string]interface{}{"ref": "me/movies"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.GraphQLHandler(w, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	res := struct {
+		Data struct {
+			Dataset struct {
+				Name      string
+				Structure struct{ Format string }
+				Body      []interface{}
+				History   []struct{ Path string }
+			}
+			Datasets []struct{ Name string }
+			Me       struct{ Peername string }
+		}
+		Errors []interface{}
+	}{}
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) != 0 {
+		t.Errorf("expected no errors, got %v", res.Errors)
+	}
+	if res.Data.Dataset.Name != "movies" {
+		t.Errorf("expected dataset name movies, got %q", res.Data.Dataset.Name)
+	}
+	if res.Data.Dataset.Structure.Format != "csv" {
+		t.Errorf("expected structure format csv, got %q", res.Data.Dataset.Structure.Format)
+	}
+	if len(res.Data.Dataset.Body) != 2 {
+		t.Errorf("expected 2 body entries, got %d", len(res.Data.Dataset.Body))
+	}
+	if len(res.Data.Dataset.History) != 1 || res.Data.Dataset.History[0].Path == "" {
+		t.Errorf("expected one history item with a path, got %v", res.Data.Dataset.History)
+	}
+	if len(res.Data.Datasets) != 1 {
+		t.Errorf("expected 1 listed dataset, got %d", len(res.Data.Datasets))
+	}
+	if res.Data.Me.Peername == "" {
+		t.Errorf("expected a peername for me")
+	}
+
+	w = httptest.NewRecorder()
+	h.GraphQLHandler(w, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(`{ dataset(ref: "me/movies") { isbn } }`), nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid queries to be bad requests, got status %d", w.Code)
+	}
+
+	h.MaxCost = 10
+	w = httptest.NewRecorder()
+	h.GraphQLHandler(w, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(`{ datasets { name } }`), nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected queries over the cost limit to be bad requests, got status %d", w.Code)
+	}
+
+	h.ReadOnly = true
+	w = httptest.NewRecorder()
+	h.GraphQLHandler(w, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected read-only server to refuse queries, got status %d", w.Code)
+	}
+}
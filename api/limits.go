@@ -0,0 +1,252 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/config"
+)
+
+// rateLimiterSweepInterval is how often idle clients are dropped
+const rateLimiterSweepInterval = time.Minute
+
+// rateLimiter limits the rate of requests from each client with a token
+// bucket per client. Buckets start full, and refill at rate tokens per second
+// up to burst
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	lk        sync.Mutex
+	clients   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a limiter, returning nil if rate is 0
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		clients: map[string]*bucket{},
+	}
+}
+
+// allow takes a token for client, returning false & how long until a token
+// is available if the client is out of tokens
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > rateLimiterSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops clients whose buckets have refilled, they're no different from
+// clients we haven't seen
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, b := range l.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.clients, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// rateLimit responds with 429 Too Many Requests if the client that sent r has
+// made too many requests, returning false
+func (s Server) rateLimit(w http.ResponseWriter, r *http.Request) bool {
+	if s.limiter == nil {
+		return true
+	}
+	ok, wait := s.limiter.allow(s.clientID(r))
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		util.WriteErrResponse(w, http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded, try again in %s", wait.Round(time.Millisecond)))
+	}
+	return ok
+}
+
+// clientID identifies the client that sent a request for rate limiting.
+// Requests with a valid api token are identified by the token, others by
+// IP address. Requests from a trusted proxy are identified by the rightmost
+// X-Forwarded-For address that isn't itself a trusted proxy
+func (s Server) clientID(r *http.Request) string {
+	if secret := requestToken(r); secret != "" {
+		if tokens := s.Instance.APITokens(); tokens != nil {
+			if t, err := tokens.Verify(secret); err == nil {
+				return "token:" + t.ID
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	proxies := trustedProxies(s.Config().API.TrustedProxies)
+	if !isTrustedProxy(proxies, host) {
+		return "ip:" + host
+	}
+	// each proxy appends the address it received the request from, walk
+	// back from the right until reaching one a trusted proxy didn't send
+	addrs := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(addrs[i])
+		if addr == "" {
+			break
+		}
+		host = addr
+		if !isTrustedProxy(proxies, addr) {
+			break
+		}
+	}
+	return "ip:" + host
+}
+
+// trustedProxies parses the configured trusted proxies, skipping invalid
+// entries, which config validation reports
+func trustedProxies(entries []string) []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, e := range entries {
+		if ipnet, err := config.ParseTrustedProxy(e); err == nil {
+			proxies = append(proxies, ipnet)
+		}
+	}
+	return proxies
+}
+
+// isTrustedProxy checks if addr is in one of the trusted proxy ranges
+func isTrustedProxy(proxies []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, p := range proxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitBody caps the size of a request body, responding with 413 Request
+// Entity Too Large for bodies over max bytes. Bodies with a declared length
+// are rejected before the handler runs, returning false. Otherwise the
+// handler reads a body that fails past max bytes, and error responses it
+// writes are replaced with a 413
+func limitBody(max int64, w http.ResponseWriter, r *http.Request) (http.ResponseWriter, bool) {
+	if max <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return w, true
+	}
+	if r.ContentLength > max {
+		util.WriteErrResponse(w, http.StatusRequestEntityTooLarge, errBodyTooLarge(max))
+		return w, false
+	}
+	body := &limitedBody{ReadCloser: r.Body, remaining: max}
+	r.Body = body
+	return &limitedBodyWriter{ResponseWriter: w, body: body, max: max}, true
+}
+
+func errBodyTooLarge(max int64) error {
+	return fmt.Errorf("request body is larger than the %d byte limit", max)
+}
+
+// errReadTooLarge is returned by reads past the end of a limited body
+var errReadTooLarge = fmt.Errorf("request body too large")
+
+// limitedBody fails reads once more than remaining bytes are read
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errReadTooLarge
+	}
+	// read one byte past the limit to tell a body that's exactly the limit
+	// from one that's over
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		b.exceeded = true
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, errReadTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// limitedBodyWriter replaces error responses to requests with oversized
+// bodies with a 413
+type limitedBodyWriter struct {
+	http.ResponseWriter
+	body     *limitedBody
+	max      int64
+	rejected bool
+}
+
+func (w *limitedBodyWriter) WriteHeader(code int) {
+	if w.body.exceeded && code >= 400 {
+		w.rejected = true
+		util.WriteErrResponse(w.ResponseWriter, http.StatusRequestEntityTooLarge, errBodyTooLarge(w.max))
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *limitedBodyWriter) Write(p []byte) (int, error) {
+	if w.rejected {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush supports handlers that stream responses
+func (w *limitedBodyWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.rejected {
+		f.Flush()
+	}
+}
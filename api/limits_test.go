@@ -0,0 +1,142 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/apitoken"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("expected request %d within burst to be allowed", i)
+		}
+	}
+	ok, wait := l.allow("a")
+	if ok {
+		t.Fatal("expected request past burst to be limited")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms, got %s", wait)
+	}
+	if ok, _ := l.allow("b"); !ok {
+		t.Error("expected other clients to be unaffected")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Error("expected a token to refill after 500ms")
+	}
+
+	now = now.Add(2 * rateLimiterSweepInterval)
+	l.allow("c")
+	if _, ok := l.clients["a"]; ok {
+		t.Error("expected idle clients to be swept")
+	}
+
+	if newRateLimiter(0, 10) != nil {
+		t.Error("expected a zero rate to disable limiting")
+	}
+}
+
+func TestLimitsMiddleware(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	cfg := run.Inst.Config()
+	cfg.API.RateLimit = 1
+	cfg.API.RateLimitBurst = 2
+	cfg.API.MaxBodySize = 8
+	s := New(run.Inst)
+
+	readBody := func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	post := func(body, remoteAddr, token string, chunked bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/test", strings.NewReader(body))
+		r.RemoteAddr = remoteAddr
+		if chunked {
+			r.ContentLength = -1
+		}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		s.scopedMiddleware(noAuth, readBody)(w, r)
+		return w
+	}
+
+	if w := post("12345678", "10.0.0.1:1000", "", false); w.Code != http.StatusOK {
+		t.Errorf("expected a body at the limit to be accepted, got status %d", w.Code)
+	}
+	if w := post("123456789", "10.0.0.1:1001", "", false); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized body to be rejected, got status %d", w.Code)
+	}
+	w := post("123456789", "10.0.0.2:1000", "", true)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized body of unknown length to be rejected, got status %d", w.Code)
+	}
+
+	w = post("", "10.0.0.1:1002", "", false)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected third request from an address to be rate limited, got status %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After of 1 second, got %q", w.Header().Get("Retry-After"))
+	}
+
+	// requests with a token are limited by token, not address
+	secret, _, err := run.Inst.APITokens().Create("limited", apitoken.ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := post("", "10.0.0.1:1003", secret, false); w.Code != http.StatusOK {
+		t.Errorf("expected token request from a limited address to be allowed, got status %d", w.Code)
+	}
+}
+
+func TestClientIDTrustedProxies(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	cfg := run.Inst.Config()
+	cfg.API.TrustedProxies = []string{"10.0.0.1", "192.168.0.0/16"}
+	s := New(run.Inst)
+
+	cases := []struct {
+		remoteAddr, forwardedFor, expect string
+	}{
+		{"10.0.0.9:1000", "", "ip:10.0.0.9"},
+		{"10.0.0.9:1000", "1.2.3.4", "ip:10.0.0.9"},
+		{"10.0.0.1:1000", "", "ip:10.0.0.1"},
+		{"10.0.0.1:1000", "1.2.3.4", "ip:1.2.3.4"},
+		{"10.0.0.1:1000", "6.6.6.6, 1.2.3.4", "ip:1.2.3.4"},
+		{"10.0.0.1:1000", "6.6.6.6, 1.2.3.4, 192.168.1.1", "ip:1.2.3.4"},
+		{"10.0.0.1:1000", "192.168.1.2, 192.168.1.1", "ip:192.168.1.2"},
+	}
+	for i, c := range cases {
+		r := httptest.NewRequest("GET", "/test", nil)
+		r.RemoteAddr = c.remoteAddr
+		if c.forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", c.forwardedFor)
+		}
+		if got := s.clientID(r); got != c.expect {
+			t.Errorf("case %d: expected %q, got %q", i, c.expect, got)
+		}
+	}
+}
@@ -72,7 +72,7 @@ func (h *LogHandlers) logHandler(w http.ResponseWriter, r *http.Request) {
 			Ref:        args.String(),
 			ListParams: lp,
 		}
-		if err := h.lm.Log(params, &res); err != nil {
+		if err := h.lm.LogContext(r.Context(), params, &res); err != nil {
 			if err == repo.ErrNoHistory {
 				util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
 				return
@@ -87,7 +87,7 @@ func (h *LogHandlers) logHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		} else {
 			if err := util.WritePageResponse(w, res, r, params.Page()); err != nil {
-				log.Infof("error list dataset history response: %s", err.Error())
+				reqLog.Infof(r.Context(), "error list dataset history response: %s", err)
 			}
 			return
 		}
@@ -96,7 +96,7 @@ func (h *LogHandlers) logHandler(w http.ResponseWriter, r *http.Request) {
 		Ref:        args.String(),
 		RemoteName: remoteName,
 	}
-	if err := h.rm.Fetch(p, &res); err != nil {
+	if err := h.rm.FetchContext(r.Context(), p, &res); err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/metrics"
+)
+
+// MetricsHandler serves metrics in Prometheus text format
+func (s Server) MetricsHandler() http.HandlerFunc {
+	h := metrics.Handler(s.nodeMetrics())
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "OPTIONS":
+			util.EmptyOkHandler(w, r)
+		case "GET":
+			h.ServeHTTP(w, r)
+		default:
+			util.NotFoundHandler(w, r)
+		}
+	}
+}
+
+// nodeMetrics gathers measurements of this server's node when metrics are
+// requested
+func (s Server) nodeMetrics() prometheus.Gatherer {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		metrics.GaugeFunc("p2p", "connected_peers", "Connected peers", func() float64 {
+			node := s.Node()
+			if node == nil || !node.Online || node.Host() == nil {
+				return 0
+			}
+			return float64(len(node.Host().Network().Peers()))
+		}),
+		metrics.GaugeFunc("p2p", "connected_qri_peers", "Connected peers running qri", func() float64 {
+			node := s.Node()
+			if node == nil || !node.Online {
+				return 0
+			}
+			return float64(len(node.ConnectedQriProfiles()))
+		}),
+		metrics.GaugeFunc("repo", "datasets", "Datasets in the repo", func() float64 {
+			n, err := s.Repo().RefCount()
+			if err != nil {
+				log.Debugf("counting datasets: %s", err)
+			}
+			return float64(n)
+		}),
+		metrics.GaugeFunc("dscache", "refs", "Dataset references in the dscache", func() float64 {
+			if c := s.Repo().Dscache(); !c.IsEmpty() {
+				return float64(c.Root.RefsLength())
+			}
+			return 0
+		}),
+		metrics.GaugeFunc("dscache", "size_bytes", "Size of the dscache", func() float64 {
+			if c := s.Repo().Dscache(); !c.IsEmpty() {
+				return float64(len(c.Buffer))
+			}
+			return 0
+		}),
+	)
+	return reg
+}
+
+// instrument records request counts & latencies for a route
+func instrument(route string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, r)
+		metrics.ObserveRequest(route, r.Method, sw.code, time.Since(start))
+	})
+}
+
+// statusWriter records the status code of a response
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush supports handlers that stream responses
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack supports websocket connections
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response doesn't support hijacking")
+	}
+	w.code = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	s := New(newTestInstanceWithProfileFromNode(node))
+	mux := s.routes().mux()
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/list", nil))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	expect := []string{
+		`qri_api_requests_total{code="200",method="GET",route="/list"}`,
+		`qri_repo_datasets`,
+		`qri_p2p_connected_peers`,
+		`qri_dscache_refs`,
+	}
+	for _, e := range expect {
+		if !strings.Contains(body, e) {
+			t.Errorf("expected metrics to include %q", e)
+		}
+	}
+}
@@ -4,15 +4,53 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/apitoken"
 )
 
-// middleware handles request logging
+// noAuth marks routes that don't require authorization
+const noAuth = apitoken.Scope("")
+
+// middleware handles request IDs & logging, rate & request size limits.
+// When the API requires authorization, GET requests need a token with read
+// scope, other requests need write scope
 func (s Server) middleware(handler http.HandlerFunc) http.HandlerFunc {
+	return s.scopedMiddleware(apitoken.ScopeRead, handler)
+}
+
+// scopedMiddleware is middleware that requires at least the given scope for
+// every request to the route, or no authorization if scope is noAuth
+func (s Server) scopedMiddleware(scope apitoken.Scope, handler http.HandlerFunc) http.HandlerFunc {
+	write := scope
+	if scope != noAuth && !scope.Allows(apitoken.ScopeWrite) {
+		write = apitoken.ScopeWrite
+	}
+	return s.authMiddleware(scope, write, handler)
+}
+
+// readMiddleware is middleware for routes that only read, whatever the
+// request method. POST requests to these routes need read scope
+func (s Server) readMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return s.authMiddleware(apitoken.ScopeRead, apitoken.ScopeRead, handler)
+}
+
+// publishMiddleware is middleware for routes that list published datasets on
+// GET requests, and publish or unpublish them with other methods
+func (s Server) publishMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return s.authMiddleware(apitoken.ScopeRead, apitoken.ScopePublish, handler)
+}
+
+// authMiddleware requires scope for requests to a route. GET requests need
+// readScope, requests with other methods need writeScope
+func (s Server) authMiddleware(readScope, writeScope apitoken.Scope, handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Infof("%s %s %s", r.Method, r.URL.Path, time.Now())
+		rw, r := withRequestID(w, r)
+		defer rw.finish()
+		w = rw
+		reqLog.Infof(r.Context(), "%s %s %s", r.Method, r.URL.Path, time.Now())
 
 		// If this server is operating behind a proxy, but we still want to force
 		// users to use https, cfg.ProxyForceHttps == true will listen for the common
@@ -33,6 +71,39 @@ func (s Server) middleware(handler http.HandlerFunc) http.HandlerFunc {
 		// }
 		s.addCORSHeaders(w, r)
 
+		if r.Method != "OPTIONS" {
+			if !s.rateLimit(w, r) {
+				return
+			}
+			var ok bool
+			if w, ok = limitBody(s.Config().API.MaxBodySize, w, r); !ok {
+				return
+			}
+		}
+
+		required := readScope
+		if r.Method != "GET" {
+			required = writeScope
+		}
+		// tenant routes are always authorized, tenants are only reachable
+		// with a token
+		if required != noAuth && (s.tenant || s.Config().API.RequireAuth) && r.Method != "OPTIONS" {
+			granted, err := s.authorize(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				util.WriteErrResponse(w, http.StatusUnauthorized, err)
+				return
+			}
+			if !granted.Allows(required) {
+				util.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("this request requires %s scope", required))
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), ScopeCtxKey, granted))
+		}
+		if s.tenant {
+			r = r.WithContext(context.WithValue(r.Context(), TenantCtxKey, true))
+		}
+
 		if ok := s.readOnlyCheck(r); ok {
 			handler(w, r)
 		} else {
@@ -41,6 +112,42 @@ func (s Server) middleware(handler http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// authorize returns the scope a request is authorized for. Requests carry
+// either an api token, or a signature from the profile keypair which grants
+// admin scope. Browsers can't set headers on websocket & event stream
+// requests, so tokens can also be sent as an "access_token" query param
+func (s Server) authorize(r *http.Request) (apitoken.Scope, error) {
+	if secret := requestToken(r); secret != "" {
+		tokens := s.Instance.APITokens()
+		if tokens == nil {
+			return "", apitoken.ErrInvalidToken
+		}
+		t, err := tokens.Verify(secret)
+		if err != nil {
+			return "", err
+		}
+		return t.Scope, nil
+	}
+
+	if pk := s.Repo().PrivateKey(); pk != nil && s.signatures != nil {
+		err := s.signatures.VerifyRequest(r, pk.GetPublic(), time.Now())
+		if err == nil {
+			return apitoken.ScopeAdmin, nil
+		} else if err != apitoken.ErrNoSignature {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("authorization required")
+}
+
+// requestToken returns the api token secret sent with a request, if any
+func requestToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	}
+	return r.URL.Query().Get("access_token")
+}
+
 func (s *Server) readOnlyCheck(r *http.Request) bool {
 	return !s.Config().API.ReadOnly || r.Method == "GET" || r.Method == "OPTIONS"
 }
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/logging"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	s := New(run.Inst)
+	s.Config().API.RequireAuth = true
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	readSecret, _, err := run.Inst.APITokens().Create("reader", apitoken.ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSecret, _, err := run.Inst.APITokens().Create("writer", apitoken.ScopeWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		description string
+		scope       apitoken.Scope
+		method      string
+		token       string
+		sign        bool
+		expect      int
+	}{
+		{"no token", apitoken.ScopeRead, "GET", "", false, http.StatusUnauthorized},
+		{"bad token", apitoken.ScopeRead, "GET", "qri_abc_def", false, http.StatusUnauthorized},
+		{"read token get", apitoken.ScopeRead, "GET", readSecret, false, http.StatusOK},
+		{"read token post", apitoken.ScopeRead, "POST", readSecret, false, http.StatusForbidden},
+		{"write token post", apitoken.ScopeRead, "POST", writeSecret, false, http.StatusOK},
+		{"write token publish", apitoken.ScopePublish, "POST", writeSecret, false, http.StatusForbidden},
+		{"preflight", apitoken.ScopeAdmin, "OPTIONS", "", false, http.StatusOK},
+		{"public route", noAuth, "POST", "", false, http.StatusOK},
+		{"profile signature", apitoken.ScopeAdmin, "POST", "", true, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, "/test", nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if c.sign {
+			if err := apitoken.SignRequest(req, run.Node.Repo.PrivateKey(), time.Now()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w := httptest.NewRecorder()
+		s.scopedMiddleware(c.scope, ok)(w, req)
+		if w.Code != c.expect {
+			t.Errorf("case %q: expected status %d, got: %d", c.description, c.expect, w.Code)
+		}
+	}
+
+	// signatures can only be used once
+	req := httptest.NewRequest("POST", "/test", strings.NewReader("body"))
+	if err := apitoken.SignRequest(req, run.Node.Repo.PrivateKey(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	replay := httptest.NewRequest("POST", "/test", strings.NewReader("body"))
+	replay.Header = req.Header.Clone()
+	w := httptest.NewRecorder()
+	s.scopedMiddleware(apitoken.ScopeAdmin, ok)(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected signed request to be authorized, got status: %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	s.scopedMiddleware(apitoken.ScopeAdmin, ok)(w, replay)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected replayed signature to be unauthorized, got status: %d", w.Code)
+	}
+
+	// tenant routes check scopes whether or not the host requires them
+	s.Config().API.RequireAuth = false
+	ts := Server{Instance: run.Inst, tenant: true}
+	req = httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+readSecret)
+	w = httptest.NewRecorder()
+	ts.middleware(ok)(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected tenant route to check scope, got status: %d", w.Code)
+	}
+	req = httptest.NewRequest("GET", "/test", nil)
+	w = httptest.NewRecorder()
+	ts.middleware(ok)(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected tenant route to require a token, got status: %d", w.Code)
+	}
+	s.Config().API.RequireAuth = true
+
+	// listing published datasets only reads
+	req = httptest.NewRequest("GET", "/publish/", nil)
+	req.Header.Set("Authorization", "Bearer "+readSecret)
+	w = httptest.NewRecorder()
+	s.publishMiddleware(ok)(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected read token to list published datasets, got status: %d", w.Code)
+	}
+	req = httptest.NewRequest("DELETE", "/publish/", nil)
+	req.Header.Set("Authorization", "Bearer "+writeSecret)
+	w = httptest.NewRecorder()
+	s.publishMiddleware(ok)(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected unpublishing to require publish scope, got status: %d", w.Code)
+	}
+
+	// tokens can be passed as a query param for streaming requests
+	req = httptest.NewRequest("GET", "/test?access_token="+readSecret, nil)
+	w = httptest.NewRecorder()
+	s.middleware(ok)(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected query param token to authorize, got status: %d", w.Code)
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	s := New(run.Inst)
+	var got string
+	fail := func(w http.ResponseWriter, r *http.Request) {
+		got = logging.RequestID(r.Context())
+		util.WriteErrResponse(w, http.StatusNotFound, fmt.Errorf("not found"))
+	}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(logging.RequestIDHeader, "client-id")
+	w := httptest.NewRecorder()
+	s.middleware(fail)(w, req)
+	if got != "client-id" {
+		t.Errorf("expected handler context to carry the client request ID, got %q", got)
+	}
+	if id := w.Header().Get(logging.RequestIDHeader); id != "client-id" {
+		t.Errorf("expected response header to carry the request ID, got %q", id)
+	}
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	res := struct {
+		Meta map[string]interface{}
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Meta["requestID"] != "client-id" {
+		t.Errorf("expected error response meta to include the request ID, got %v", res.Meta)
+	}
+
+	// invalid IDs are replaced
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(logging.RequestIDHeader, "bad id %s")
+	w = httptest.NewRecorder()
+	s.middleware(fail)(w, req)
+	if got == "" || got == "bad id %s" {
+		t.Errorf("expected invalid request ID to be replaced, got %q", got)
+	}
+	if w.Header().Get(logging.RequestIDHeader) != got {
+		t.Errorf("expected response header %q to match handler request ID %q", w.Header().Get(logging.RequestIDHeader), got)
+	}
+}
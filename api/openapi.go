@@ -0,0 +1,397 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	util "github.com/qri-io/apiutil"
+)
+
+// route is a pattern mounted on the server mux, along with the operations
+// it serves. Every route must describe at least one operation so the
+// OpenAPI document stays in step with what the server actually mounts
+type route struct {
+	pattern string
+	handler http.Handler
+	ops     []operation
+}
+
+// operation describes a request a route accepts & the response it writes
+type operation struct {
+	methods []string
+	summary string
+	params  []param
+	// body is a value of the JSON request body type, nil for no body
+	body interface{}
+	// response is a value of the type written to the "data" field of the
+	// response envelope
+	response interface{}
+	// paginated responses include a "pagination" field
+	paginated bool
+	// contentType is set for responses that aren't wrapped in the JSON
+	// response envelope
+	contentType string
+}
+
+// param is a path or query parameter
+type param struct {
+	name        string
+	in          string
+	typ         string
+	description string
+}
+
+func get(summary string, response interface{}, params ...param) operation {
+	return operation{methods: []string{"GET"}, summary: summary, response: response, params: params}
+}
+
+func post(summary string, body, response interface{}, params ...param) operation {
+	return operation{methods: []string{"POST"}, summary: summary, body: body, response: response, params: params}
+}
+
+func del(summary string, response interface{}, params ...param) operation {
+	return operation{methods: []string{"DELETE"}, summary: summary, response: response, params: params}
+}
+
+// also adds methods the operation is served on
+func (o operation) also(methods ...string) operation {
+	o.methods = append(append([]string{}, o.methods...), methods...)
+	return o
+}
+
+// paged marks an operation as writing a paginated response
+func (o operation) paged() operation {
+	o.paginated = true
+	o.params = append(append([]param{}, o.params...), pageParams...)
+	return o
+}
+
+// raw marks an operation as writing a response body of contentType, rather
+// than a JSON response envelope
+func (o operation) raw(contentType string) operation {
+	o.contentType = contentType
+	return o
+}
+
+func pathParam(name, description string) param {
+	return param{name: name, in: "path", typ: "string", description: description}
+}
+
+func queryParam(name, typ, description string) param {
+	return param{name: name, in: "query", typ: typ, description: description}
+}
+
+var (
+	refParams = []param{
+		pathParam("peername", "username of the dataset owner, or \"me\""),
+		pathParam("name", "dataset name"),
+	}
+	pageParams = []param{
+		queryParam("page", "integer", "page number, starting at 1"),
+		queryParam("pageSize", "integer", "number of results per page"),
+	}
+)
+
+// withRef prepends dataset reference path parameters to params
+func withRef(params ...param) []param {
+	return append(append([]param{}, refParams...), params...)
+}
+
+// routeTable collects the routes of a server
+type routeTable struct {
+	routes []*route
+
+	once sync.Once
+	doc  []byte
+}
+
+// handle adds a route to the table
+func (t *routeTable) handle(pattern string, handler http.Handler, ops ...operation) {
+	t.routes = append(t.routes, &route{pattern: pattern, handler: handler, ops: ops})
+}
+
+// mux mounts all routes on a ServeMux, recording request metrics
+func (t *routeTable) mux() *http.ServeMux {
+	m := http.NewServeMux()
+	for _, r := range t.routes {
+		m.Handle(r.pattern, instrument(r.pattern, r.handler))
+	}
+	return m
+}
+
+// OpenAPIHandler serves an OpenAPI 3 document describing the routes
+func (t *routeTable) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		t.once.Do(func() {
+			var err error
+			if t.doc, err = json.Marshal(openAPIDocument(t.routes)); err != nil {
+				reqLog.Errorf(r.Context(), "encoding OpenAPI document: %s", err)
+			}
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(t.doc)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// specPath converts a mux pattern to an OpenAPI path. Path parameters are
+// appended to the pattern in order
+func specPath(pattern string, params []param) string {
+	p := strings.TrimSuffix(pattern, "/")
+	for _, prm := range params {
+		if prm.in == "path" {
+			p += "/{" + prm.name + "}"
+		}
+	}
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// openAPIDocument builds an OpenAPI 3 document from a set of routes
+func openAPIDocument(routes []*route) map[string]interface{} {
+	s := newSchemas()
+	// envelope schemas are added first, so generated types with the same
+	// name are qualified by package
+	s.defs["ResponseMeta"] = objectSchema(map[string]interface{}{
+		"code":  schemaType("integer"),
+		"error": schemaType("string"),
+	})
+	s.defs["Pagination"] = objectSchema(map[string]interface{}{
+		"nextUrl": schemaType("string"),
+	})
+
+	paths := map[string]map[string]interface{}{}
+
+	for _, rt := range routes {
+		for _, o := range rt.ops {
+			p := specPath(rt.pattern, o.params)
+			if paths[p] == nil {
+				paths[p] = map[string]interface{}{}
+			}
+			for _, m := range o.methods {
+				paths[p][strings.ToLower(m)] = s.operation(o)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":       "Qri API",
+			"description": "Qri API used to communicate with a Qri node",
+			"version":     APIVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": s.defs,
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "error response",
+					"content":     jsonContent(objectSchema(map[string]interface{}{"meta": schemaRef("ResponseMeta")})),
+				},
+			},
+		},
+	}
+}
+
+func objectSchema(props map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+func schemaType(typ string) map[string]interface{} {
+	return map[string]interface{}{"type": typ}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// schemas generates JSON schemas from Go types, collecting named struct
+// types as reusable components
+type schemas struct {
+	defs  map[string]interface{}
+	types map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		defs:  map[string]interface{}{},
+		types: map[reflect.Type]string{},
+	}
+}
+
+func (s *schemas) operation(o operation) map[string]interface{} {
+	op := map[string]interface{}{"summary": o.summary}
+
+	if len(o.params) > 0 {
+		params := make([]map[string]interface{}, len(o.params))
+		for i, p := range o.params {
+			params[i] = map[string]interface{}{
+				"name":        p.name,
+				"in":          p.in,
+				"description": p.description,
+				"required":    p.in == "path",
+				"schema":      schemaType(p.typ),
+			}
+		}
+		op["parameters"] = params
+	}
+
+	if o.body != nil {
+		op["requestBody"] = map[string]interface{}{
+			"content": jsonContent(s.of(o.body)),
+		}
+	}
+
+	var content map[string]interface{}
+	switch {
+	case o.contentType == "application/json":
+		content = jsonContent(s.of(o.response))
+	case o.contentType != "":
+		content = map[string]interface{}{
+			o.contentType: map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string", "format": "binary"},
+			},
+		}
+	default:
+		envelope := map[string]interface{}{
+			"meta": schemaRef("ResponseMeta"),
+			"data": s.of(o.response),
+		}
+		if o.paginated {
+			envelope["pagination"] = schemaRef("Pagination")
+		}
+		content = jsonContent(objectSchema(envelope))
+	}
+
+	op["responses"] = map[string]interface{}{
+		"200":     map[string]interface{}{"description": "OK", "content": content},
+		"default": map[string]interface{}{"$ref": "#/components/responses/Error"},
+	}
+	return op
+}
+
+// of returns the schema of v's type. nil values have an empty schema, which
+// matches any value
+func (s *schemas) of(v interface{}) map[string]interface{} {
+	if v == nil {
+		return map[string]interface{}{}
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (s *schemas) schema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawJSONType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.schema(t.Elem())
+	case reflect.Bool:
+		return schemaType("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schemaType("integer")
+	case reflect.Float32, reflect.Float64:
+		return schemaType("number")
+	case reflect.String:
+		return schemaType("string")
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// byte slices encode as base64 strings
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		name, ok := s.types[t]
+		if !ok {
+			name = s.name(t)
+			s.types[t] = name
+			// register the name before generating properties, so types that
+			// refer to themselves terminate
+			s.defs[name] = map[string]interface{}{}
+			s.defs[name] = s.object(t)
+		}
+		return schemaRef(name)
+	}
+	// interfaces & anything else can hold any value
+	return map[string]interface{}{}
+}
+
+// name picks a component name for t, qualifying it with its package name
+// when another type already has the plain name
+func (s *schemas) name(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := s.defs[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	return name
+}
+
+func (s *schemas) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	s.fields(t, props)
+	return objectSchema(props)
+}
+
+func (s *schemas) fields(t reflect.Type, props map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// embedded structs without a tag have their fields promoted
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			s.fields(ft, props)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		switch ft.Kind() {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = s.schema(f.Type)
+	}
+}
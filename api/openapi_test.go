@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestRoutesHaveOpenAPISpec(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	cfg := config.DefaultConfigForTesting()
+	pro, _ := node.Repo.Profile()
+	cfg.Profile, _ = pro.Encode()
+	// mount remote routes too
+	cfg.Remote = &config.Remote{Enabled: true}
+	s := New(lib.NewInstanceFromConfigAndNode(cfg, node))
+
+	routes := s.routes()
+	w := httptest.NewRecorder()
+	routes.mux().ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	doc := struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.0.0" {
+		t.Errorf("expected an OpenAPI 3.0.0 document, got version %q", doc.OpenAPI)
+	}
+
+	for _, r := range routes.routes {
+		if len(r.ops) == 0 {
+			t.Errorf("route %q is mounted without an OpenAPI spec", r.pattern)
+			continue
+		}
+		for _, o := range r.ops {
+			if o.summary == "" {
+				t.Errorf("route %q has an operation without a summary", r.pattern)
+			}
+			p := specPath(r.pattern, o.params)
+			for _, m := range o.methods {
+				if _, ok := doc.Paths[p][strings.ToLower(m)]; !ok {
+					t.Errorf("document is missing %s %s", m, p)
+				}
+			}
+		}
+	}
+}
+
+func TestSpecPath(t *testing.T) {
+	cases := []struct {
+		pattern string
+		params  []param
+		expect  string
+	}{
+		{"/list", nil, "/list"},
+		{"/body/", withRef(queryParam("limit", "integer", "")), "/body/{peername}/{name}"},
+		{"/", []param{pathParam("peername", "")}, "/{peername}"},
+		{"/", nil, "/"},
+	}
+	for _, c := range cases {
+		if got := specPath(c.pattern, c.params); got != c.expect {
+			t.Errorf("specPath(%q): expected %q, got %q", c.pattern, c.expect, got)
+		}
+	}
+}
@@ -97,7 +97,7 @@ func (h *PeerHandlers) listPeersHandler(w http.ResponseWriter, r *http.Request)
 	}
 	res := []*config.ProfilePod{}
 	if err := h.List(p, &res); err != nil {
-		log.Infof("list peers: %s", err.Error())
+		reqLog.Infof(r.Context(), "list peers: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -111,7 +111,7 @@ func (h *PeerHandlers) listConnectionsHandler(w http.ResponseWriter, r *http.Req
 	peers := []string{}
 
 	if err := h.ConnectedIPFSPeers(&listParams.Limit, &peers); err != nil {
-		log.Infof("error showing connected peers: %s", err.Error())
+		reqLog.Infof(r.Context(), "error showing connected peers: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -132,7 +132,7 @@ func (h *PeerHandlers) peerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	res := &config.ProfilePod{}
 	if err := h.Info(p, res); err != nil {
-		log.Infof("error getting peer info: %s", err.Error())
+		reqLog.Infof(r.Context(), "error getting peer info: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -149,8 +149,8 @@ func (h *PeerHandlers) connectToPeerHandler(w http.ResponseWriter, r *http.Reque
 	pcpod := lib.NewPeerConnectionParamsPod(arg)
 
 	res := &config.ProfilePod{}
-	if err := h.ConnectToPeer(pcpod, res); err != nil {
-		log.Infof("error connecting to peer: %s", err.Error())
+	if err := h.ConnectToPeerContext(r.Context(), pcpod, res); err != nil {
+		reqLog.Infof(r.Context(), "error connecting to peer: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -48,7 +48,7 @@ func (h *ProfileHandlers) getProfileHandler(w http.ResponseWriter, r *http.Reque
 	args := true
 	res := &config.ProfilePod{}
 	if err := h.GetProfile(&args, res); err != nil {
-		log.Infof("error getting profile: %s", err.Error())
+		reqLog.Infof(r.Context(), "error getting profile: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -90,7 +90,7 @@ func (h *ProfileHandlers) getProfilePhotoHandler(w http.ResponseWriter, r *http.
 	req.Peername = r.FormValue("peername")
 	req.ID = r.FormValue("id")
 
-	if err := h.ProfilePhoto(req, &data); err != nil {
+	if err := h.ProfilePhotoContext(r.Context(), req, &data); err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -118,8 +118,8 @@ func (h *ProfileHandlers) setProfilePhotoHandler(w http.ResponseWriter, r *http.
 	}
 
 	res := &config.ProfilePod{}
-	if err := h.SetProfilePhoto(p, res); err != nil {
-		log.Infof("error initializing dataset: %s", err.Error())
+	if err := h.SetProfilePhotoContext(r.Context(), p, res); err != nil {
+		reqLog.Infof(r.Context(), "error initializing dataset: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -146,7 +146,7 @@ func (h *ProfileHandlers) getPosterHandler(w http.ResponseWriter, r *http.Reques
 	req.Peername = r.FormValue("peername")
 	req.ID = r.FormValue("id")
 
-	if err := h.PosterPhoto(req, &data); err != nil {
+	if err := h.PosterPhotoContext(r.Context(), req, &data); err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -173,8 +173,8 @@ func (h *ProfileHandlers) setPosterHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	res := &config.ProfilePod{}
-	if err := h.SetPosterPhoto(p, res); err != nil {
-		log.Infof("error initializing dataset: %s", err.Error())
+	if err := h.SetPosterPhotoContext(r.Context(), p, res); err != nil {
+		reqLog.Infof(r.Context(), "error initializing dataset: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
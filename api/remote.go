@@ -57,14 +57,14 @@ func (h *RemoteClientHandlers) PublishHandler(w http.ResponseWriter, r *http.Req
 	var res dsref.Ref
 	switch r.Method {
 	case "POST":
-		if err := h.Publish(p, &res); err != nil {
+		if err := h.PublishContext(r.Context(), p, &res); err != nil {
 			util.WriteErrResponse(w, http.StatusInternalServerError, err)
 			return
 		}
 		util.WriteResponse(w, "ok")
 		return
 	case "DELETE":
-		if err := h.Unpublish(p, &res); err != nil {
+		if err := h.UnpublishContext(r.Context(), p, &res); err != nil {
 			util.WriteErrResponse(w, http.StatusInternalServerError, err)
 			return
 		}
@@ -90,8 +90,8 @@ func (h *RemoteClientHandlers) FeedsHandler(w http.ResponseWriter, r *http.Reque
 func (h *RemoteClientHandlers) feedsHandler(w http.ResponseWriter, r *http.Request) {
 	res := map[string][]dsref.VersionInfo{}
 	remName := r.FormValue("remote")
-	if err := h.Feeds(&remName, &res); err != nil {
-		log.Infof("home error: %s", err.Error())
+	if err := h.FeedsContext(r.Context(), &remName, &res); err != nil {
+		reqLog.Infof(r.Context(), "home error: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -117,7 +117,7 @@ func (h *RemoteClientHandlers) previewHandler(w http.ResponseWriter, r *http.Req
 		Ref:        strings.TrimPrefix(r.URL.Path, "/preview/"),
 	}
 	res := &dataset.Dataset{}
-	if err := h.Preview(p, res); err != nil {
+	if err := h.PreviewContext(r.Context(), p, res); err != nil {
 		util.WriteErrResponse(w, http.StatusBadRequest, err)
 		return
 	}
@@ -133,13 +133,13 @@ func (h *RemoteClientHandlers) listPublishedHandler(w http.ResponseWriter, r *ht
 	dsm := lib.NewDatasetMethods(h.inst)
 
 	res := []dsref.VersionInfo{}
-	if err := dsm.List(&args, &res); err != nil {
-		log.Infof("error listing datasets: %s", err.Error())
+	if err := dsm.ListContext(r.Context(), &args, &res); err != nil {
+		reqLog.Infof(r.Context(), "error listing datasets: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
 	if err := util.WritePageResponse(w, res, r, args.Page()); err != nil {
-		log.Infof("error list datasests response: %s", err.Error())
+		reqLog.Infof(r.Context(), "error list datasests response: %s", err)
 	}
 }
 
@@ -45,10 +45,16 @@ func (h *RenderHandlers) RenderHandler(w http.ResponseWriter, r *http.Request) {
 		p.Dataset = ds
 	}
 
+	p.UseFSI = r.FormValue("fsi") == "true"
+	if isTenantRequest(r.Context()) && (p.UseFSI || datasetHostPath(p.Dataset)) {
+		apiutil.WriteErrResponse(w, http.StatusForbidden, errHostPath)
+		return
+	}
+
 	// Old style viz component rendering
 	if r.FormValue("viz") == "true" {
 		data := []byte{}
-		if err := h.RenderViz(p, &data); err != nil {
+		if err := h.RenderVizContext(r.Context(), p, &data); err != nil {
 			apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
 			return
 		}
@@ -58,9 +64,8 @@ func (h *RenderHandlers) RenderHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Readme component rendering
-	p.UseFSI = r.FormValue("fsi") == "true"
 	var text string
-	if err := h.RenderReadme(p, &text); err != nil {
+	if err := h.RenderReadmeContext(r.Context(), p, &text); err != nil {
 		apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
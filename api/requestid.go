@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/qri-io/qri/logging"
+)
+
+// reqLog logs lines tagged with the request ID of a context
+var reqLog = logging.NewLogger("qriapi")
+
+// withRequestID tags a request with an ID, using a valid X-Request-Id header
+// sent by the client or creating one. The ID is set as a response header, and
+// added to the meta of error responses
+func withRequestID(w http.ResponseWriter, r *http.Request) (*requestIDWriter, *http.Request) {
+	id := r.Header.Get(logging.RequestIDHeader)
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	w.Header().Set(logging.RequestIDHeader, id)
+	r = r.WithContext(logging.WithRequestID(r.Context(), id))
+	return &requestIDWriter{ResponseWriter: w, id: id}, r
+}
+
+// requestIDWriter adds a request ID to JSON error responses
+type requestIDWriter struct {
+	http.ResponseWriter
+	id string
+	// code is an error status waiting for the response body
+	code int
+}
+
+func (w *requestIDWriter) WriteHeader(code int) {
+	if code >= 400 && w.code == 0 {
+		w.code = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *requestIDWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		return w.ResponseWriter.Write(p)
+	}
+	code := w.code
+	w.code = 0
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+	if body, ok := addRequestID(p, w.id); ok {
+		if _, err := w.ResponseWriter.Write(body); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// addRequestID adds a requestID field to the meta of a JSON response
+// envelope, returning false if body isn't one
+func addRequestID(body []byte, id string) ([]byte, bool) {
+	env := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, false
+	}
+	meta := map[string]interface{}{}
+	if err := json.Unmarshal(env["meta"], &meta); err != nil {
+		return nil, false
+	}
+	meta["requestID"] = id
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, false
+	}
+	env["meta"] = data
+	if body, err = json.MarshalIndent(env, "", "  "); err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// Flush supports handlers that stream responses
+func (w *requestIDWriter) Flush() {
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+		w.code = 0
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack supports websocket connections
+func (w *requestIDWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response doesn't support hijacking")
+	}
+	return hj.Hijack()
+}
+
+// finish writes an error status no body was written for
+func (w *requestIDWriter) finish() {
+	if w.code != 0 {
+		w.ResponseWriter.WriteHeader(w.code)
+		w.code = 0
+	}
+}
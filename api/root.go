@@ -56,7 +56,7 @@ func (mh *RootHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		Refstr: ref.String(),
 	}
 	res := lib.GetResult{}
-	err := mh.dsh.Get(&p, &res)
+	err := mh.dsh.GetContext(r.Context(), &p, &res)
 	if err != nil {
 		if err == repo.ErrNotFound {
 			util.NotFoundHandler(w, r)
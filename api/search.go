@@ -50,7 +50,7 @@ func (h *SearchHandlers) searchHandler(w http.ResponseWriter, r *http.Request) {
 	results := []lib.SearchResult{}
 
 	if err := h.SearchMethods.Search(sp, &results); err != nil {
-		log.Infof("search error: %s", err.Error())
+		reqLog.Infof(r.Context(), "search error: %s", err)
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
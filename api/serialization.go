@@ -12,6 +12,7 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
 	"github.com/qri-io/qri/base/fill"
+	"github.com/qri-io/qri/startf"
 	"gopkg.in/yaml.v2"
 )
 
@@ -99,9 +100,9 @@ func formFileDataset(r *http.Request, ds *dataset.Dataset) (err error) {
 		if ds.Transform == nil {
 			ds.Transform = &dataset.Transform{}
 		}
-		ds.Transform.Syntax = "starlark"
 		ds.Transform.ScriptBytes = tfData
 		ds.Transform.ScriptPath = tfHeader.Filename
+		ds.Transform.Syntax = startf.TransformSyntax(&dataset.Transform{ScriptPath: tfHeader.Filename})
 	}
 
 	vizFile, vizHeader, err := r.FormFile("viz")
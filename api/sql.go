@@ -1,25 +1,48 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	util "github.com/qri-io/apiutil"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/sql"
 )
 
+// sqlTruncatedHeader is a response trailer set to "true" when results were
+// cut off at the row limit
+const sqlTruncatedHeader = "X-Qri-Truncated"
+
 // SQLHandlers connects HTTP requests to the FSI subsystem
 type SQLHandlers struct {
 	lib.SQLMethods
 	ReadOnly bool
+	// SQLReadOnly allows queries when ReadOnly is set
+	SQLReadOnly bool
+	// Timeout limits how long a query runs. 0 means no limit
+	Timeout time.Duration
+	// MaxRows truncates results after this many rows. 0 means no limit.
+	// Truncated responses set an X-Qri-Truncated trailer, and JSON responses
+	// set "truncated" in meta
+	MaxRows int
 }
 
 // NewSQLHandlers creates handlers that talk to qri's filesystem integration
 func NewSQLHandlers(inst *lib.Instance, readOnly bool) SQLHandlers {
-	return SQLHandlers{
+	h := SQLHandlers{
 		SQLMethods: *lib.NewSQLMethods(inst),
 		ReadOnly:   readOnly,
 	}
+	if cfg := inst.Config(); cfg != nil && cfg.API != nil {
+		h.SQLReadOnly = cfg.API.SQLReadOnly
+		h.Timeout = time.Duration(cfg.API.SQLTimeoutMs) * time.Millisecond
+		h.MaxRows = cfg.API.SQLMaxRows
+	}
+	return h
 }
 
 // QueryHandler runs an SQL query over HTTP
@@ -27,10 +50,7 @@ func (h *SQLHandlers) QueryHandler(routePrefix string) http.HandlerFunc {
 	handleQuery := h.queryHandler(routePrefix)
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO (b5) - SQL queries are a "read only" thing, but feels like they
-		// should have separate configuration to enable when the API is in readonly
-		// mode
-		if h.ReadOnly {
+		if h.ReadOnly && !h.SQLReadOnly {
 			readOnlyResponse(w, routePrefix)
 			return
 		}
@@ -46,6 +66,15 @@ func (h *SQLHandlers) QueryHandler(routePrefix string) http.HandlerFunc {
 	}
 }
 
+// sqlContentTypes maps query output formats to response content types
+var sqlContentTypes = map[string]string{
+	"json":                "application/json",
+	"csv":                 "text/csv",
+	"tabbed":              "text/tab-separated-values",
+	"table":               "text/plain; charset=utf-8",
+	"table_row_separated": "text/plain; charset=utf-8",
+}
+
 func (h *SQLHandlers) queryHandler(routePrefix string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		p := &lib.SQLQueryParams{
@@ -65,18 +94,98 @@ func (h *SQLHandlers) queryHandler(routePrefix string) http.HandlerFunc {
 			}
 		}
 
-		var res []byte
-		if err := h.Exec(p, &res); err != nil {
-			util.WriteErrResponse(w, http.StatusUnprocessableEntity, err)
+		contentType, ok := sqlContentTypes[p.OutputFormat]
+		if !ok {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid output format: %q", p.OutputFormat))
 			return
 		}
+		if h.MaxRows > 0 && (p.MaxRows <= 0 || p.MaxRows > h.MaxRows) {
+			p.MaxRows = h.MaxRows
+		}
+
+		ctx := r.Context()
+		if h.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+			defer cancel()
+		}
 
-		if p.OutputFormat == "json" {
-			util.WriteResponse(w, json.RawMessage(res))
+		sw := &sqlResponseWriter{w: w, contentType: contentType, json: p.OutputFormat == "json"}
+		err := h.ExecStream(ctx, sw, p)
+		truncated := errors.Is(err, sql.ErrRowLimit)
+		if truncated {
+			err = nil
+		}
+		if err != nil && !sw.started {
+			status := http.StatusUnprocessableEntity
+			if err == context.DeadlineExceeded {
+				status = http.StatusServiceUnavailable
+				err = fmt.Errorf("query exceeded the %s time limit", h.Timeout)
+			}
+			util.WriteErrResponse(w, status, err)
+			return
+		}
+		if err != nil {
+			// results are partially written, the best we can do is stop
+			reqLog.Infof(r.Context(), "error streaming sql results: %s", err)
 			return
 		}
+		sw.finish(truncated)
+	}
+}
+
+// sqlResponseWriter holds off on sending headers until a query produces
+// output, so queries that fail up front can still respond with an error
+// status. JSON results are wrapped in the standard response envelope
+type sqlResponseWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	json        bool
+	started     bool
+}
+
+func (sw *sqlResponseWriter) start() error {
+	sw.started = true
+	sw.w.Header().Set("Content-Type", sw.contentType)
+	sw.w.Header().Set("Trailer", sqlTruncatedHeader)
+	sw.w.WriteHeader(http.StatusOK)
+	if sw.json {
+		_, err := sw.w.Write([]byte(`{"data":`))
+		return err
+	}
+	return nil
+}
+
+func (sw *sqlResponseWriter) Write(p []byte) (int, error) {
+	if !sw.started {
+		if err := sw.start(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := sw.w.Write(p)
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
 
-		// TODO (b5) - set Content-Type header based on output format
-		w.Write(res)
+// finish completes a successful response, marking results cut off at the
+// row limit as truncated
+func (sw *sqlResponseWriter) finish(truncated bool) {
+	if !sw.started {
+		sw.start()
+		if sw.json {
+			sw.w.Write([]byte(`null`))
+		}
+	}
+	if truncated {
+		sw.w.Header().Set(sqlTruncatedHeader, "true")
+	}
+	if sw.json {
+		if truncated {
+			fmt.Fprintf(sw.w, `,"meta":{"code":%d,"truncated":true}}`, http.StatusOK)
+			return
+		}
+		fmt.Fprintf(sw.w, `,"meta":{"code":%d}}`, http.StatusOK)
 	}
 }
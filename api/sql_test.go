@@ -1,6 +1,10 @@
 package api
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -23,3 +27,63 @@ func TestSQLHandler(t *testing.T) {
 	}
 	runHandlerTestCases(t, "sql", h.QueryHandler("/sql"), jsonCases, true)
 }
+
+func TestSQLHandlerStreaming(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	inst := newTestInstanceWithProfileFromNode(node)
+	h := NewSQLHandlers(inst, true)
+	query := "/sql?output_format=csv&query=select%20m.movie_title%20from%20me/movies%20m"
+
+	w := httptest.NewRecorder()
+	h.QueryHandler("/sql")(w, httptest.NewRequest("GET", query, nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected read-only server to refuse queries, got status %d", w.Code)
+	}
+
+	h.SQLReadOnly = true
+	h.MaxRows = 2
+	w = httptest.NewRecorder()
+	h.QueryHandler("/sql")(w, httptest.NewRequest("GET", query, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("expected text/csv content type, got %q", ct)
+	}
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 3 {
+		t.Errorf("expected a header and 2 rows, got %d lines: %q", len(lines), w.Body.String())
+	}
+	if got := w.Result().Trailer.Get(sqlTruncatedHeader); got != "true" {
+		t.Errorf("expected truncated results to set a %s trailer, got %q", sqlTruncatedHeader, got)
+	}
+
+	w = httptest.NewRecorder()
+	h.QueryHandler("/sql")(w, httptest.NewRequest("GET", "/sql?query=select%20m.movie_title%20from%20me/movies%20m", nil))
+	res := struct {
+		Data []interface{}
+		Meta struct {
+			Truncated bool
+		}
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decoding json response: %s\n%s", err, w.Body.String())
+	}
+	if len(res.Data) != 2 || !res.Meta.Truncated {
+		t.Errorf("expected 2 rows marked as truncated, got %d rows, truncated: %t", len(res.Data), res.Meta.Truncated)
+	}
+
+	h.MaxRows = 0
+	w = httptest.NewRecorder()
+	h.QueryHandler("/sql")(w, httptest.NewRequest("GET", query, nil))
+	if got := w.Result().Trailer.Get(sqlTruncatedHeader); got != "" {
+		t.Errorf("expected complete results not to be marked truncated, got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	h.QueryHandler("/sql")(w, httptest.NewRequest("GET", "/sql?output_format=xml&query=select%201", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected unknown output format to be a bad request, got status %d", w.Code)
+	}
+}
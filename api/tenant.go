@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	util "github.com/qri-io/apiutil"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/lib"
+)
+
+// errHostPath is the error tenant requests that refer to files on the host get
+var errHostPath = fmt.Errorf("tenants can't read or write files on the host")
+
+// hostRoutes are the routes tenants don't get, which work with files on the
+// host. Tenant routes answer them with 403 Forbidden instead of falling
+// through to "/"
+var hostRoutes = []string{"/ipfs/", "/ipns/", "/status/", "/whatchanged/", "/init/", "/checkout/", "/restore/", "/fsi/write/"}
+
+// hostRouteHandler rejects tenant requests to host routes
+func hostRouteHandler(w http.ResponseWriter, r *http.Request) {
+	util.WriteErrResponse(w, http.StatusForbidden, errHostPath)
+}
+
+// tenantRoutes keeps the routes of each tenant a server hosts
+type tenantRoutes struct {
+	lk    sync.Mutex
+	muxes map[string]tenantMux
+}
+
+// tenantMux is the routes of a tenant instance
+type tenantMux struct {
+	inst *lib.Instance
+	h    http.Handler
+}
+
+// tenantHandler sends requests that carry an api token issued for a tenant
+// to the tenant's routes, and all other requests to host
+func (s Server) tenantHandler(host http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peername := s.requestTenant(r)
+		if peername == "" {
+			host.ServeHTTP(w, r)
+			return
+		}
+		h, err := s.tenantRoutesFor(peername)
+		if err != nil {
+			rw, r := withRequestID(w, r)
+			defer rw.finish()
+			code := http.StatusInternalServerError
+			if err == lib.ErrTenantNotFound {
+				code = http.StatusUnauthorized
+			} else if err == lib.ErrTenantsRequireAuth {
+				code = http.StatusForbidden
+			}
+			reqLog.Errorf(r.Context(), "opening tenant %s: %s", peername, err)
+			util.WriteErrResponse(rw, code, err)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// requestTenant returns the peername of the tenant a request's api token was
+// issued for. Requests without a valid tenant token are for the host
+func (s Server) requestTenant(r *http.Request) string {
+	if s.tenants == nil {
+		return ""
+	}
+	secret := requestToken(r)
+	if secret == "" {
+		return ""
+	}
+	tokens := s.Instance.APITokens()
+	if tokens == nil {
+		return ""
+	}
+	t, err := tokens.Verify(secret)
+	if err != nil {
+		return ""
+	}
+	return t.Profile
+}
+
+// tenantRoutesFor returns the routes of a tenant, creating them when the tenant's
+// instance changes. Tenant routes share the host's rate limiter, and leave out
+// routes that work with files on the host
+func (s Server) tenantRoutesFor(peername string) (http.Handler, error) {
+	inst, err := s.Instance.Tenant(peername)
+	if err != nil {
+		return nil, err
+	}
+
+	s.tenants.lk.Lock()
+	defer s.tenants.lk.Unlock()
+	if m, ok := s.tenants.muxes[peername]; ok && m.inst == inst {
+		return m.h, nil
+	}
+	h := NewServerRoutes(Server{Instance: inst, limiter: s.limiter, signatures: s.signatures, tenant: true})
+	s.tenants.muxes[peername] = tenantMux{inst: inst, h: h}
+	return h, nil
+}
+
+// isHostPath reports whether path is a file on the host, rather than a URL or
+// a path in the dataset store
+func isHostPath(path string) bool {
+	return qfs.PathKind(path) == "local"
+}
+
+// datasetHostPath reports whether saving or rendering a dataset document reads
+// a file on the host. Components uploaded with the request carry their
+// content, and only keep the filename they were uploaded with
+func datasetHostPath(ds *dataset.Dataset) bool {
+	if ds == nil {
+		return false
+	}
+	if len(ds.BodyBytes) == 0 && isHostPath(ds.BodyPath) {
+		return true
+	}
+	if ds.Transform != nil && len(ds.Transform.ScriptBytes) == 0 && isHostPath(ds.Transform.ScriptPath) {
+		return true
+	}
+	if ds.Viz != nil && len(ds.Viz.ScriptBytes) == 0 && isHostPath(ds.Viz.ScriptPath) {
+		return true
+	}
+	if ds.Readme != nil && len(ds.Readme.ScriptBytes) == 0 && isHostPath(ds.Readme.ScriptPath) {
+		return true
+	}
+	return false
+}
+
+// saveHostPath reports whether a save reads a file on the host
+func saveHostPath(p *lib.SaveParams) bool {
+	if isHostPath(p.BodyPath) || datasetHostPath(p.Dataset) {
+		return true
+	}
+	for _, path := range p.FilePaths {
+		if isHostPath(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	cfgtest "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestTenantHandler(t *testing.T) {
+	node, teardown := newTestNode(t)
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "api_tenant_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := config.DefaultConfigForTesting()
+	cfg.API.RequireAuth = true
+	pro, _ := node.Repo.Profile()
+	cfg.Profile, _ = pro.Encode()
+	inst, err := lib.NewInstance(context.Background(), dir, lib.OptConfig(cfg), lib.OptQriNode(node))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := cfgtest.GetTestPeerInfo(2)
+	tenant := config.ProfilePod{}
+	if err := lib.NewTenantMethods(inst).Add(&lib.AddTenantParams{Peername: "alice", PrivKey: info.EncodedPrivKey, ProfileID: info.EncodedPeerID}, &tenant); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := lib.NewAPITokenMethods(inst)
+	hostToken := lib.CreateAPITokenResult{}
+	if err := tokens.Create(&lib.CreateAPITokenParams{Name: "host", Scope: "read"}, &hostToken); err != nil {
+		t.Fatal(err)
+	}
+	aliceToken := lib.CreateAPITokenResult{}
+	if err := tokens.Create(&lib.CreateAPITokenParams{Name: "alice", Scope: "write", Profile: "alice"}, &aliceToken); err != nil {
+		t.Fatal(err)
+	}
+
+	h := New(inst).Handler()
+	me := func(secret string) (int, string) {
+		r := httptest.NewRequest("GET", "/me", nil)
+		r.Header.Set("Authorization", "Bearer "+secret)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		res := struct {
+			Data config.ProfilePod
+		}{}
+		json.Unmarshal(w.Body.Bytes(), &res)
+		return w.Code, res.Data.Peername
+	}
+
+	if code, peername := me(hostToken.Secret); code != http.StatusOK || peername != cfg.Profile.Peername {
+		t.Errorf("expected host token to get the host profile %q, got status %d, peername %q", cfg.Profile.Peername, code, peername)
+	}
+	if code, peername := me(aliceToken.Secret); code != http.StatusOK || peername != "alice" {
+		t.Errorf("expected tenant token to get the tenant profile, got status %d, peername %q", code, peername)
+	}
+
+	// hosts that stop requiring authorization stop serving tenants
+	inst.Config().API.RequireAuth = false
+	if code, peername := me(aliceToken.Secret); code != http.StatusForbidden || peername == "alice" {
+		t.Errorf("expected tenant requests to be refused without api authorization, got status %d", code)
+	}
+	inst.Config().API.RequireAuth = true
+
+	hostFile := filepath.Join(dir, "host.csv")
+	if err := ioutil.WriteFile(hostFile, []byte("a,b\n1,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hostPathReqs := []struct {
+		method, url, body string
+	}{
+		{"POST", "/save/alice/host?new=true&bodypath=" + url.QueryEscape(hostFile), "{}"},
+		{"POST", "/save/alice/host?new=true", `{"bodyPath":"` + hostFile + `"}`},
+		{"POST", "/add/peer/dataset?dir=" + url.QueryEscape(dir), ""},
+		{"GET", "/diff?left_path=" + url.QueryEscape(hostFile) + "&right_path=" + url.QueryEscape(hostFile), ""},
+		{"GET", "/render/alice/host?fsi=true", ""},
+		{"POST", "/checkout/alice/host?dir=" + url.QueryEscape(dir), ""},
+		{"POST", "/init/?dir=" + url.QueryEscape(dir) + "&name=host&format=csv", ""},
+		{"POST", "/restore/alice/host?dir=" + url.QueryEscape(dir), ""},
+		{"POST", "/fsi/write/alice/host", ""},
+		{"GET", "/ipfs/QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt", ""},
+	}
+	for _, c := range hostPathReqs {
+		r := httptest.NewRequest(c.method, c.url, strings.NewReader(c.body))
+		if c.body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		r.Header.Set("Authorization", "Bearer "+aliceToken.Secret)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), errHostPath.Error()) {
+			t.Errorf("%s %s: expected tenant token to be forbidden from host files, got status %d: %s", c.method, c.url, w.Code, w.Body.String())
+		}
+	}
+
+	removed := false
+	name := "alice"
+	if err := lib.NewTenantMethods(inst).Remove(&name, &removed); err != nil {
+		t.Fatal(err)
+	}
+	if code, peername := me(aliceToken.Secret); peername == "alice" {
+		t.Errorf("expected a removed tenant's token to stop working, got status %d", code)
+	}
+}
@@ -2,12 +2,16 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-
 	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	util "github.com/qri-io/apiutil"
 	"github.com/qri-io/qri/base/component"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/p2p"
@@ -16,20 +20,37 @@ import (
 	"nhooyr.io/websocket/wsjson"
 )
 
-const (
-	// TODO(dlong): Move to cfg
-	websocketPort        = 2506
-	qriWebsocketProtocol = "qri-websocket"
-)
+const qriWebsocketProtocol = "qri-websocket"
+
+// StreamTopics are the event topics clients can subscribe to on the events
+// endpoint
+var StreamTopics = []event.Topic{
+	event.ETDatasetSaved,
+	event.ETDatasetRenamed,
+	event.ETDatasetRemoved,
+	event.ETDatasetPublished,
+	event.ETDatasetUnpublished,
+	event.ETDatasetPulled,
+	event.ETFSICreateLinkEvent,
+	event.ETFSICheckoutEvent,
+	event.ETConfigChanged,
+	watchfs.ETFilesysEvent,
+}
 
-// TODO(dlong): This file has a tight coupling between Websocket and Watchfs that makes sense
-// for now, as they're two pieces working together on the same task, but will start to make
-// less sense once more Websocket messages are being delivered, and as the event.Bus is used
-// more places. Reconsider in the future how to better integrate these two pieces.
+// StreamEvent is a bus event as written to event stream clients
+type StreamEvent struct {
+	Topic event.Topic `json:"topic"`
+	// sequence number of the event in the bus journal, omitted if the bus
+	// doesn't keep a journal
+	Seq     uint64      `json:"seq,omitempty"`
+	Payload interface{} `json:"payload"`
+}
 
-// ServeWebsocket creates a websocket that clients can connect to in order to get realtime events
+// ServeWebsocket watches linked working directories, publishing filesystem
+// events to the bus. If the API config sets a websocket port, the events
+// endpoint is also served on that port
 func (s Server) ServeWebsocket(ctx context.Context) {
-	// Watch the filesystem. Events will be sent to websocket connections.
+	// Watch the filesystem. Events are published to the bus for streaming
 	node := s.Node()
 	fsmessages, err := s.startFilesysWatcher(ctx, node)
 	if err != nil {
@@ -37,80 +58,228 @@ func (s Server) ServeWebsocket(ctx context.Context) {
 		return
 	}
 
+	known := component.GetKnownFilenames()
 	go func() {
-		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", LocalHostIP, websocketPort))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case fse := <-fsmessages:
+				if s.filterEvent(fse, known) {
+					log.Debugf("filesys event: %s\n", fse)
+					s.Instance.Bus().Publish(watchfs.ETFilesysEvent, fse)
+				}
+			}
+		}
+	}()
+
+	port := s.Config().API.WebsocketPort
+	if port == 0 {
+		return
+	}
+	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", LocalHostIP, port))
+	if err != nil {
+		log.Infof("Websocket listen on port %d error: %s", port, err)
+		return
+	}
+	defer l.Close()
+
+	srv := &http.Server{
+		Handler:           s.middleware(s.EventsHandler),
+		ReadHeaderTimeout: time.Second * 15,
+	}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	// TODO(dlong): Move to SummaryString
+	fmt.Printf("Listening for websocket connection at %s\n", l.Addr().String())
+
+	if err = srv.Serve(l); err != http.ErrServerClosed {
+		log.Infof("failed to listen and serve: %v", err)
+	}
+}
+
+// EventsHandler streams bus events to a client over a websocket, or as
+// server-sent events if the request accepts "text/event-stream". The
+// optional "topics" param is a comma-separated list of topics to subscribe
+// to, defaulting to all StreamTopics. If the bus keeps a journal, the "since"
+// param (or a Last-Event-ID header) replays journaled events with a greater
+// sequence number before streaming new events
+func (s Server) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.originAllowed(r) {
+		util.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("origin %q is not allowed", r.Header.Get("Origin")))
+		return
+	}
+	topics, err := streamTopicsFromReq(r)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	since, err := streamSinceFromReq(r)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if since > 0 && s.Instance.Bus().Journal() == nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("event journal is not enabled, can't replay events"))
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		s.serveSSE(w, r, topics, since)
+		return
+	}
+
+	c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols: []string{qriWebsocketProtocol},
+		// origins are checked against the API config above
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		reqLog.Debugf(r.Context(), "Websocket accept error: %s", err)
+		return
+	}
+	defer c.Close(websocket.StatusInternalError, "")
+
+	// clients don't send messages, reading only handles closing the connection
+	ctx := c.CloseRead(r.Context())
+	s.streamEvents(ctx, topics, since, func(e StreamEvent) error {
+		return wsjson.Write(ctx, c, e)
+	})
+	c.Close(websocket.StatusNormalClosure, "")
+}
+
+// serveSSE writes events to a response as server-sent events
+func (s Server) serveSSE(w http.ResponseWriter, r *http.Request, topics []event.Topic, since uint64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s.streamEvents(r.Context(), topics, since, func(e StreamEvent) error {
+		data, err := json.Marshal(e.Payload)
 		if err != nil {
-			log.Infof("Websocket listen on port %d error: %s", websocketPort, err)
-			return
+			return err
 		}
-		defer l.Close()
-
-		// Collect all websocket connections. Should only be one at a time, but that may
-		// change in the future.
-		connections := []*websocket.Conn{}
-		srv := &http.Server{
-			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
-					Subprotocols:       []string{qriWebsocketProtocol},
-					InsecureSkipVerify: true,
-				})
-				if err != nil {
-					log.Debugf("Websocket accept error: %s", err)
-					return
-				}
-				connections = append(connections, c)
-			}),
-			ReadTimeout:  time.Second * 15,
-			WriteTimeout: time.Second * 15,
+		if e.Seq > 0 {
+			if _, err = fmt.Fprintf(w, "id: %d\n", e.Seq); err != nil {
+				return err
+			}
 		}
-		defer srv.Close()
-
-		// Subscribe to FSI link creation events, which will affect filesystem watching
-		// TODO(dlong): A good example of tight coupling causing an issue: The Websocket
-		// implementation doesn't need to know about these events, but the FilesystemWatcher
-		// does. Ideally, this Subscribe call would happen along with the latter, not the former.
-		busEvents := s.Instance.Bus().Subscribe(event.ETFSICreateLinkEvent)
-
-		known := component.GetKnownFilenames()
-
-		// Filesystem events are forwarded to the websocket. In the future, this may be
-		// expanded to handle other types of events, such as SaveDatasetProgressEvent,
-		// and DiffProgressEvent, but this is fine for now.
-		go func() {
-			for {
-				select {
-				case e := <-busEvents:
-					log.Debugf("bus event: %s\n", e)
-					if fce, ok := e.Payload.(event.FSICreateLinkEvent); ok {
-						s.Instance.Watcher.Add(watchfs.EventPath{
-							Path:     fce.FSIPath,
-							Username: fce.Username,
-							Dsname:   fce.Dsname,
-						})
-					}
-				case fse := <-fsmessages:
-					if s.filterEvent(fse, known) {
-						log.Debugf("filesys event: %s\n", fse)
-						for k, c := range connections {
-							err = wsjson.Write(ctx, c, fse)
-							if err != nil {
-								log.Errorf("connection %d: wsjson write error: %s", k, err)
-							}
-						}
-					}
-				}
+		if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Topic, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+// streamEvents sends events on the given topics until the context is
+// cancelled or sending fails. Journaled events after since are sent first
+func (s Server) streamEvents(ctx context.Context, topics []event.Topic, since uint64, send func(StreamEvent) error) {
+	bus := s.Instance.Bus()
+	// subscribe before replaying so no events are missed in between
+	events := bus.Subscribe(topics...)
+	defer bus.Unsubscribe(events)
+
+	if j := bus.Journal(); j != nil && since > 0 {
+		subscribed := map[event.Topic]bool{}
+		for _, t := range topics {
+			subscribed[t] = true
+		}
+		for _, e := range j.Since(since) {
+			if !subscribed[e.Topic] {
+				continue
 			}
-		}()
+			if err := send(StreamEvent{Topic: e.Topic, Seq: e.Seq, Payload: e.Payload}); err != nil {
+				reqLog.Debugf(ctx, "event stream send error: %s", err)
+				return
+			}
+			since = e.Seq
+		}
+	}
 
-		// TODO(dlong): Move to SummaryString
-		fmt.Printf("Listening for websocket connection at %s\n", l.Addr().String())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-events:
+			if e.Seq != 0 && e.Seq <= since {
+				// already replayed from the journal
+				continue
+			}
+			if err := send(StreamEvent{Topic: e.Topic, Seq: e.Seq, Payload: e.Payload}); err != nil {
+				reqLog.Debugf(ctx, "event stream send error: %s", err)
+				return
+			}
+		}
+	}
+}
 
-		// Start http server for websocket.
-		err = srv.Serve(l)
-		if err != http.ErrServerClosed {
-			log.Infof("failed to listen and serve: %v", err)
+// originAllowed checks the origin of a streaming request. Requests without an
+// origin, from the same host, or from an allowed origin in the API config are
+// allowed
+func (s Server) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range s.Config().API.AllowedOrigins {
+		if origin == o {
+			return true
 		}
-	}()
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// streamTopicsFromReq reads the topics requested by a streaming client
+func streamTopicsFromReq(r *http.Request) ([]event.Topic, error) {
+	param := r.FormValue("topics")
+	if param == "" {
+		return StreamTopics, nil
+	}
+	var topics []event.Topic
+	for _, name := range strings.Split(param, ",") {
+		t := event.Topic(strings.TrimSpace(name))
+		known := false
+		for _, st := range StreamTopics {
+			if t == st {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event topic %q", t)
+		}
+		topics = append(topics, t)
+	}
+	return topics, nil
+}
+
+// streamSinceFromReq reads the sequence number a streaming client wants to
+// replay events after
+func streamSinceFromReq(r *http.Request) (uint64, error) {
+	param := r.FormValue("since")
+	if param == "" {
+		param = r.Header.Get("Last-Event-ID")
+	}
+	if param == "" {
+		return 0, nil
+	}
+	since, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid event sequence number %q", param)
+	}
+	return since, nil
 }
 
 func (s Server) startFilesysWatcher(ctx context.Context, node *p2p.QriNode) (chan watchfs.FilesysEvent, error) {
@@ -129,13 +298,20 @@ func (s Server) startFilesysWatcher(ctx context.Context, node *p2p.QriNode) (cha
 			})
 		}
 	}
-	// Watch those paths.
-	// TODO(dlong): When datasets are removed or renamed update the watchlist.
+	// Watch those paths. The watcher subscribes to link creation events on
+	// the bus to watch newly linked directories
 	s.Instance.Watcher = watchfs.NewFilesysWatcher(ctx, s.Instance.Bus())
+	if f := s.Instance.FSI(); f != nil {
+		// keep links up to date when working directories are moved
+		s.Instance.Watcher.FollowMoves(f)
+	}
 	fsmessages := s.Instance.Watcher.Begin(paths)
 	return fsmessages, nil
 }
 
 func (s Server) filterEvent(event watchfs.FilesysEvent, knownFilenames map[string][]string) bool {
+	if event.Type == watchfs.RenameFolderEvent || event.Type == watchfs.RemoveFolderEvent {
+		return true
+	}
 	return component.IsKnownFilename(event.Source, knownFilenames)
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/event"
+)
+
+func TestEventsHandler(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	s := New(run.Inst)
+	srv := httptest.NewServer(http.HandlerFunc(s.EventsHandler))
+	defer srv.Close()
+
+	bad := []struct {
+		topics, origin string
+		status         int
+	}{
+		{"dataset:unknown", "", http.StatusBadRequest},
+		{"", "http://evil.example.com", http.StatusForbidden},
+	}
+	for i, c := range bad {
+		req, _ := http.NewRequest("GET", srv.URL+"?topics="+c.topics, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		if c.origin != "" {
+			req.Header.Set("Origin", c.origin)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != c.status {
+			t.Errorf("case %d status mismatch. expected: %d, got: %d", i, c.status, res.StatusCode)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequest("GET", srv.URL+"?topics="+string(event.ETDatasetSaved), nil)
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected event stream content type, got: %q", ct)
+	}
+
+	// wait for the handler to subscribe before publishing
+	for run.Inst.Bus().NumSubscribers() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	run.Inst.Bus().Publish(event.ETDatasetRemoved, event.DatasetRemovedEvent{})
+	run.Inst.Bus().Publish(event.ETDatasetSaved, event.DatasetSavedEvent{FSIPath: "/path/to/dir"})
+
+	sc := bufio.NewScanner(res.Body)
+	var lines []string
+	for sc.Scan() && sc.Text() != "" {
+		lines = append(lines, sc.Text())
+	}
+	got := strings.Join(lines, "\n")
+	if !strings.HasPrefix(got, "event: dataset:saved\ndata: ") || !strings.Contains(got, `"FSIPath":"/path/to/dir"`) {
+		t.Errorf("unexpected event:\n%s", got)
+	}
+}
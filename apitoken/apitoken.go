@@ -0,0 +1,252 @@
+// Package apitoken mints, stores and verifies tokens that authorize requests
+// to the qri JSON API. Only a hash of each token is stored. Tokens carry a
+// single scope, scopes are ordered so each scope grants the ones before it:
+// read, write, publish, admin. A token can be issued for a tenant profile,
+// acting as that profile on a host that serves several
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when a token doesn't match any stored token
+	ErrInvalidToken = fmt.Errorf("invalid api token")
+	// ErrNotFound is returned when a token id doesn't exist
+	ErrNotFound = fmt.Errorf("api token not found")
+)
+
+// tokenPrefix starts every token, making them easy to spot in config files
+// and logs
+const tokenPrefix = "qri_"
+
+// Scope is a level of access granted by a token
+type Scope string
+
+const (
+	// ScopeRead allows requests that read data
+	ScopeRead = Scope("read")
+	// ScopeWrite allows requests that change local data, and ScopeRead
+	ScopeWrite = Scope("write")
+	// ScopePublish allows sending data to other peers & remotes, and
+	// ScopeWrite
+	ScopePublish = Scope("publish")
+	// ScopeAdmin allows everything, including network & profile management
+	ScopeAdmin = Scope("admin")
+)
+
+var scopeRanks = map[Scope]int{
+	ScopeRead:    1,
+	ScopeWrite:   2,
+	ScopePublish: 3,
+	ScopeAdmin:   4,
+}
+
+// ParseScope validates a scope string
+func ParseScope(s string) (Scope, error) {
+	sc := Scope(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := scopeRanks[sc]; !ok {
+		return "", fmt.Errorf("invalid scope %q, must be one of read, write, publish, admin", s)
+	}
+	return sc, nil
+}
+
+// Allows returns true if scope s grants the required scope
+func (s Scope) Allows(required Scope) bool {
+	have, ok := scopeRanks[s]
+	return ok && have >= scopeRanks[required]
+}
+
+// Token describes a minted api token. The token secret is never stored
+type Token struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Scope Scope  `json:"scope"`
+	// Profile is the peername of the tenant profile the token acts as. Empty
+	// tokens act as the host's own profile
+	Profile string    `json:"profile,omitempty"`
+	Hash    string    `json:"hash"`
+	Created time.Time `json:"created"`
+}
+
+// Store keeps api tokens. Store is safe for concurrent use
+type Store struct {
+	// file to persist tokens to. An empty path keeps tokens in memory
+	path   string
+	lk     sync.Mutex
+	tokens []*Token
+}
+
+// StorePath returns the standard path to the token file for a given
+// file-system repo location
+func StorePath(repoPath string) string {
+	return filepath.Join(repoPath, "api_tokens.json")
+}
+
+// NewStore creates a token store that persists to a file on the local
+// filesystem. Passing an empty string creates an in-memory store
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if path == "" {
+		return s, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.tokens); err != nil {
+		return nil, fmt.Errorf("decoding api tokens: %s", err)
+	}
+	return s, nil
+}
+
+// Create mints a new token, returning the token secret. The secret can't be
+// recovered later
+func (s *Store) Create(name string, scope Scope) (secret string, t Token, err error) {
+	return s.CreateForProfile(name, scope, "")
+}
+
+// CreateForProfile mints a new token that acts as the tenant profile with the
+// given peername
+func (s *Store) CreateForProfile(name string, scope Scope, profile string) (secret string, t Token, err error) {
+	if _, ok := scopeRanks[scope]; !ok {
+		return "", t, fmt.Errorf("invalid scope %q", scope)
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return "", t, err
+	}
+	key, err := randomHex(32)
+	if err != nil {
+		return "", t, err
+	}
+	secret = tokenPrefix + id + "_" + key
+
+	tok := &Token{
+		ID:      id,
+		Name:    name,
+		Scope:   scope,
+		Profile: profile,
+		Hash:    hash(secret),
+		Created: time.Now(),
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.tokens = append(s.tokens, tok)
+	if err = s.save(); err != nil {
+		s.tokens = s.tokens[:len(s.tokens)-1]
+		return "", t, err
+	}
+	return secret, *tok, nil
+}
+
+// Verify checks a token secret, returning the matching token
+func (s *Store) Verify(secret string) (Token, error) {
+	id := tokenID(secret)
+	if id == "" {
+		return Token{}, ErrInvalidToken
+	}
+	h := hash(secret)
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for _, t := range s.tokens {
+		if t.ID == id && subtle.ConstantTimeCompare([]byte(t.Hash), []byte(h)) == 1 {
+			return *t, nil
+		}
+	}
+	return Token{}, ErrInvalidToken
+}
+
+// List returns all tokens, oldest first
+func (s *Store) List() []Token {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	list := make([]Token, len(s.tokens))
+	for i, t := range s.tokens {
+		list[i] = *t
+	}
+	return list
+}
+
+// Revoke removes the token with the given id
+func (s *Store) Revoke(id string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for i, t := range s.tokens {
+		if t.ID == id {
+			s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+			return s.save()
+		}
+	}
+	return ErrNotFound
+}
+
+// RevokeProfile removes all tokens issued for a tenant profile
+func (s *Store) RevokeProfile(profile string) error {
+	if profile == "" {
+		return fmt.Errorf("profile is required")
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	kept := s.tokens[:0]
+	for _, t := range s.tokens {
+		if t.Profile != profile {
+			kept = append(kept, t)
+		}
+	}
+	s.tokens = kept
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0600)
+}
+
+// tokenID extracts the id from a token secret
+func tokenID(secret string) string {
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		return ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(secret, tokenPrefix), "_", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+// hash creates the stored form of a token secret. Secrets are long random
+// strings, so a fast hash is enough
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,163 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+func TestScopeAllows(t *testing.T) {
+	cases := []struct {
+		have, required Scope
+		expect         bool
+	}{
+		{ScopeRead, ScopeRead, true},
+		{ScopeRead, ScopeWrite, false},
+		{ScopeWrite, ScopeRead, true},
+		{ScopePublish, ScopeAdmin, false},
+		{ScopeAdmin, ScopePublish, true},
+		{Scope("bad"), ScopeRead, false},
+	}
+	for i, c := range cases {
+		if got := c.have.Allows(c.required); got != c.expect {
+			t.Errorf("case %d: expected %s allows %s to be %t", i, c.have, c.required, c.expect)
+		}
+	}
+
+	if _, err := ParseScope("superuser"); err == nil {
+		t.Error("expected invalid scope to error")
+	}
+	if s, err := ParseScope(" Write "); err != nil || s != ScopeWrite {
+		t.Errorf("expected write scope, got: %q, %v", s, err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_apitoken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := StorePath(dir)
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, tok, err := s.Create("ci", ScopeWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Fatal("token secret must not be stored")
+	}
+
+	// a new store reads persisted tokens
+	s, err = NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Verify(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != tok.ID || got.Scope != ScopeWrite {
+		t.Errorf("unexpected verified token: %#v", got)
+	}
+	if _, err := s.Verify(secret + "0"); err != ErrInvalidToken {
+		t.Errorf("expected tampered token to be invalid, got: %v", err)
+	}
+	if _, err := s.Verify("nope"); err != ErrInvalidToken {
+		t.Errorf("expected malformed token to be invalid, got: %v", err)
+	}
+
+	if err := s.Revoke(tok.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(secret); err != ErrInvalidToken {
+		t.Errorf("expected revoked token to be invalid, got: %v", err)
+	}
+	if err := s.Revoke(tok.ID); err != ErrNotFound {
+		t.Errorf("expected revoking twice to be not found, got: %v", err)
+	}
+
+	hostSecret, _, err := s.Create("host", ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenantSecret, _, err := s.CreateForProfile("tenant", ScopeWrite, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Verify(tenantSecret); err != nil || got.Profile != "alice" {
+		t.Errorf("expected tenant token to verify with profile alice, got: %#v, %v", got, err)
+	}
+	if err := s.RevokeProfile("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(tenantSecret); err != ErrInvalidToken {
+		t.Errorf("expected tenant token to be revoked, got: %v", err)
+	}
+	if _, err := s.Verify(hostSecret); err != nil {
+		t.Errorf("expected host token to survive revoking a tenant's tokens, got: %v", err)
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	pk, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	v := NewVerifier()
+
+	r := httptest.NewRequest("POST", "/save/me/movies", strings.NewReader(`{"meta":{"title":"signed"}}`))
+	if err := v.VerifyRequest(r, pub, now); err != ErrNoSignature {
+		t.Errorf("expected unsigned request to be ErrNoSignature, got: %v", err)
+	}
+	if err := SignRequest(r, pk, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewVerifier().VerifyRequest(r, pub, now.Add(time.Hour)); err == nil {
+		t.Error("expected expired signature to error")
+	}
+	if err := v.VerifyRequest(r, pub, now.Add(time.Minute)); err != nil {
+		t.Errorf("expected signature to verify, got: %s", err)
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"meta":{"title":"signed"}}` {
+		t.Errorf("expected verifying to leave the body readable, got: %q", body)
+	}
+
+	replay := httptest.NewRequest("POST", "/save/me/movies", strings.NewReader(`{"meta":{"title":"signed"}}`))
+	replay.Header = r.Header
+	if err := v.VerifyRequest(replay, pub, now); err == nil {
+		t.Error("expected a replayed signature to error")
+	}
+
+	other := httptest.NewRequest("POST", "/remove/me/movies", strings.NewReader(`{"meta":{"title":"signed"}}`))
+	other.Header = r.Header
+	if err := NewVerifier().VerifyRequest(other, pub, now); err == nil {
+		t.Error("expected signature for a different url to error")
+	}
+
+	tampered := httptest.NewRequest("POST", "/save/me/movies", strings.NewReader(`{"meta":{"title":"tampered"}}`))
+	tampered.Header = r.Header
+	if err := NewVerifier().VerifyRequest(tampered, pub, now); err == nil {
+		t.Error("expected signature for a different body to error")
+	}
+}
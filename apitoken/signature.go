@@ -0,0 +1,151 @@
+package apitoken
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+const (
+	// TimestampHeader holds the unix time a request was signed at
+	TimestampHeader = "X-Qri-Timestamp"
+	// NonceHeader holds a random value that makes each signature unique
+	NonceHeader = "X-Qri-Nonce"
+	// SignatureHeader holds a base64-encoded request signature
+	SignatureHeader = "X-Qri-Signature"
+)
+
+// MaxSignatureAge is how far a signed request's timestamp can be from the
+// current time
+var MaxSignatureAge = 5 * time.Minute
+
+// ErrNoSignature is returned when verifying a request that isn't signed
+var ErrNoSignature = fmt.Errorf("request is not signed")
+
+// SignRequest signs the method, URL, time & body of a request with a private
+// key, adding a nonce so the signature can only be used once
+func SignRequest(r *http.Request, pk crypto.PrivKey, now time.Time) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	r.Header.Set(TimestampHeader, ts)
+	r.Header.Set(NonceHeader, hex.EncodeToString(nonce))
+
+	msg, err := signedBytes(r)
+	if err != nil {
+		return err
+	}
+	sig, err := pk.Sign(msg)
+	if err != nil {
+		return err
+	}
+	r.Header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(sig))
+	return nil
+}
+
+// Verifier checks signed requests, rejecting a signature it's already seen
+type Verifier struct {
+	lk     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+// NewVerifier creates a Verifier
+func NewVerifier() *Verifier {
+	return &Verifier{seen: map[string]time.Time{}}
+}
+
+// VerifyRequest checks a request signature against a public key, returning
+// ErrNoSignature if the request isn't signed. The request body is read to
+// check it, and replaced so handlers can read it again
+func (v *Verifier) VerifyRequest(r *http.Request, pub crypto.PubKey, now time.Time) error {
+	sigStr := r.Header.Get(SignatureHeader)
+	if sigStr == "" {
+		return ErrNoSignature
+	}
+
+	secs, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp")
+	}
+	signed := time.Unix(secs, 0)
+	if age := now.Sub(signed); age > MaxSignatureAge || age < -MaxSignatureAge {
+		return fmt.Errorf("signature has expired")
+	}
+	nonce := r.Header.Get(NonceHeader)
+	if nonce == "" {
+		return fmt.Errorf("signature nonce is required")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(sigStr)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+	msg, err := signedBytes(r)
+	if err != nil {
+		return err
+	}
+	ok, err := pub.Verify(msg, sig)
+	if err != nil || !ok {
+		return fmt.Errorf("invalid signature")
+	}
+
+	// a signature is valid until its timestamp is too old, keep the nonce
+	// until then
+	v.lk.Lock()
+	defer v.lk.Unlock()
+	v.prune(now)
+	if _, ok := v.seen[nonce]; ok {
+		return fmt.Errorf("signature has already been used")
+	}
+	v.seen[nonce] = signed.Add(MaxSignatureAge)
+	return nil
+}
+
+// prune drops nonces of signatures that have expired. Callers must hold the
+// lock
+func (v *Verifier) prune(now time.Time) {
+	if now.Sub(v.pruned) < MaxSignatureAge {
+		return
+	}
+	for nonce, expires := range v.seen {
+		if now.After(expires) {
+			delete(v.seen, nonce)
+		}
+	}
+	v.pruned = now
+}
+
+// signedBytes is the content of a request that's signed, including a hash of
+// the body. Reading the body replaces it with a copy
+func signedBytes(r *http.Request) ([]byte, error) {
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		data, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		body = data
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	sum := sha256.Sum256(body)
+
+	return []byte(r.Method + "\n" + r.URL.RequestURI() + "\n" + r.Header.Get(TimestampHeader) + "\n" + r.Header.Get(NonceHeader) + "\n" + hex.EncodeToString(sum[:])), nil
+}
@@ -31,10 +31,13 @@ import (
 	"time"
 
 	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/logging"
 )
 
 var (
 	log = golog.Logger("base")
+	// reqLog logs lines tagged with the request ID of a context
+	reqLog = logging.NewLogger("base")
 	// OpenFileTimeoutDuration determines the maximium amount of time to wait for
 	// a Filestore to open a file. Some filestores (like IPFS) fallback to a
 	// network request when it can't find a file locally. Setting a short timeout
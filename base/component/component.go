@@ -57,8 +57,12 @@ func ConvertDatasetToComponents(ds *dataset.Dataset, qfilesys qfs.Filesystem) Co
 		dc.Subcomponents["readme"] = &rc
 	}
 	if ds.Transform != nil {
+		format := "star"
+		if ds.Transform.Syntax == "sql" {
+			format = "sql"
+		}
 		dc.Subcomponents["transform"] = &TransformComponent{
-			BaseComponent: BaseComponent{Format: "star"},
+			BaseComponent: BaseComponent{Format: format},
 			Resolver:      qfilesys,
 			Value:         ds.Transform,
 		}
@@ -724,6 +724,10 @@ func (tc *TransformComponent) LoadAndFill(ds *dataset.Dataset) error {
 		if err := fill.Struct(fields, tc.Value); err != nil {
 			return err
 		}
+		// script files don't record their syntax, infer it from the file format
+		if tc.Format == "sql" {
+			tc.Value.Syntax = "sql"
+		}
 	}
 	tc.Base().IsLoaded = true
 
@@ -781,7 +785,7 @@ func (bc *BaseComponent) LoadFile() (map[string]interface{}, error) {
 			return nil, err
 		}
 		return fields, nil
-	case "html", "md", "star":
+	case "html", "md", "star", "sql":
 		fields["ScriptBytes"] = data
 		return fields, nil
 	}
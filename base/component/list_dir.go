@@ -217,7 +217,7 @@ func GetKnownFilenames() map[string][]string {
 		// TODO(dlong): Viz is deprecated
 		"viz":       []string{".html"},
 		"readme":    readmeExtensionTypes,
-		"transform": []string{".star"},
+		"transform": []string{".star", ".sql"},
 		"body":      bodyExtensionTypes,
 	}
 }
@@ -20,19 +20,19 @@ import (
 func OpenDataset(ctx context.Context, fsys qfs.Filesystem, ds *dataset.Dataset) (err error) {
 	if ds.BodyFile() == nil {
 		if err = ds.OpenBodyFile(ctx, fsys); err != nil {
-			log.Debug(err)
+			reqLog.Debugf(ctx, "%s", err)
 			return
 		}
 	}
 	if ds.Transform != nil && ds.Transform.ScriptFile() == nil {
 		if err = ds.Transform.OpenScriptFile(ctx, fsys); err != nil {
-			log.Debug(err)
+			reqLog.Debugf(ctx, "%s", err)
 			return
 		}
 	}
 	if ds.Viz != nil && ds.Viz.ScriptFile() == nil {
 		if err = ds.Viz.OpenScriptFile(ctx, fsys); err != nil {
-			log.Debug(err)
+			reqLog.Debugf(ctx, "%s", err)
 			return
 		}
 	}
@@ -45,10 +45,10 @@ func OpenDataset(ctx context.Context, fsys qfs.Filesystem, ds *dataset.Dataset)
 			if errors.Is(err, context.DeadlineExceeded) {
 				err = nil
 			} else if strings.Contains(err.Error(), "not found") {
-				log.Debug("skipping not-found readme script")
+				reqLog.Debugf(ctx, "skipping not-found readme script")
 				err = nil
 			} else {
-				log.Debug(err)
+				reqLog.Debugf(ctx, "%s", err)
 				return err
 			}
 		}
@@ -62,10 +62,10 @@ func OpenDataset(ctx context.Context, fsys qfs.Filesystem, ds *dataset.Dataset)
 			if errors.Is(err, context.DeadlineExceeded) {
 				err = nil
 			} else if strings.Contains(err.Error(), "not found") {
-				log.Debug("skipping not-found viz script")
+				reqLog.Debugf(ctx, "skipping not-found viz script")
 				err = nil
 			} else {
-				log.Debug(err)
+				reqLog.Debugf(ctx, "%s", err)
 				return
 			}
 		}
@@ -118,7 +118,7 @@ func ListDatasets(ctx context.Context, r repo.Repo, term string, limit, offset i
 	}
 	res, err = r.References(0, num)
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return nil, fmt.Errorf("error getting dataset list: %s", err.Error())
 	}
 
@@ -199,7 +199,7 @@ func RawDatasetRefs(ctx context.Context, r repo.Repo) (string, error) {
 	}
 	res, err := r.References(0, num)
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return "", fmt.Errorf("error getting dataset list: %s", err.Error())
 	}
 
@@ -245,7 +245,7 @@ func FetchDataset(ctx context.Context, r repo.Repo, ref *reporef.DatasetRef, pin
 
 	if pin {
 		if err = PinDataset(ctx, r, *ref); err != nil {
-			log.Debug(err.Error())
+			reqLog.Debugf(ctx, "%s", err)
 			return fmt.Errorf("error pinning root key: %s", err.Error())
 		}
 	}
@@ -253,7 +253,7 @@ func FetchDataset(ctx context.Context, r repo.Repo, ref *reporef.DatasetRef, pin
 	if load {
 		ds, err := dsfs.LoadDataset(ctx, r.Store(), path)
 		if err != nil {
-			log.Debug(err.Error())
+			reqLog.Debugf(ctx, "%s", err)
 			return fmt.Errorf("error loading newly saved dataset path: %s", path)
 		}
 		ref.Dataset = ds
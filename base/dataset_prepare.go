@@ -45,8 +45,14 @@ func PrepareDatasetSave(ctx context.Context, r repo.Repo, peername, name string)
 	}
 
 	prevPath = lookup.Path
-	log.Debugf("loading prevPath: %s. lookup result: %v", prevPath, lookup)
+	reqLog.Debugf(ctx, "loading prevPath: %s. lookup result: %v", prevPath, lookup)
+	prev, mutable, err = PrepareDatasetSaveFrom(ctx, r, prevPath)
+	return
+}
 
+// PrepareDatasetSaveFrom loads a dataset version to save a new version on
+// top of. prev has its body file set, mutable has no transform or commit
+func PrepareDatasetSaveFrom(ctx context.Context, r repo.Repo, prevPath string) (prev, mutable *dataset.Dataset, err error) {
 	if prev, err = dsfs.LoadDataset(ctx, r.Store(), prevPath); err != nil {
 		return
 	}
@@ -0,0 +1,110 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/startf"
+)
+
+// DepGraph connects datasets in a repo through the datasets their transforms
+// load. Datasets are keyed by alias ("peername/name")
+type DepGraph struct {
+	// Upstream maps a dataset to the datasets its latest transform loaded
+	Upstream map[string][]string
+	// Downstream maps a dataset to the datasets whose latest transform loaded it
+	Downstream map[string][]string
+}
+
+// LoadDepGraph builds a dependency graph from the head version of every
+// dataset in the repo
+func LoadDepGraph(ctx context.Context, r repo.Repo) (*DepGraph, error) {
+	refs, err := r.References(0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &DepGraph{
+		Upstream:   map[string][]string{},
+		Downstream: map[string][]string{},
+	}
+	for _, ref := range refs {
+		if ref.Path == "" {
+			continue
+		}
+		ds, err := dsfs.LoadDataset(ctx, r.Store(), ref.Path)
+		if err != nil {
+			reqLog.Debugf(ctx, "loading dataset %s: %s", ref, err)
+			continue
+		}
+
+		alias := ref.AliasString()
+		for _, dep := range startf.DatasetResources(ds.Transform) {
+			g.add(dep.AliasString(), alias)
+		}
+	}
+
+	for _, deps := range g.Upstream {
+		sort.Strings(deps)
+	}
+	for _, deps := range g.Downstream {
+		sort.Strings(deps)
+	}
+	return g, nil
+}
+
+func (g *DepGraph) add(upstream, downstream string) {
+	for _, a := range g.Upstream[downstream] {
+		if a == upstream {
+			return
+		}
+	}
+	g.Upstream[downstream] = append(g.Upstream[downstream], upstream)
+	g.Downstream[upstream] = append(g.Downstream[upstream], downstream)
+}
+
+// Cascade returns every dataset that depends on alias, directly or
+// transitively, in the order transforms need to re-run so each dataset runs
+// after all of its upstream datasets. alias itself is not included. It's an
+// error for the downstream graph to contain a cycle
+func (g *DepGraph) Cascade(alias string) ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	order := []string{}
+
+	var visit func(a string) error
+	visit = func(a string) error {
+		switch state[a] {
+		case visiting:
+			return fmt.Errorf("dependency cycle detected at %s", a)
+		case visited:
+			return nil
+		}
+		state[a] = visiting
+		for _, down := range g.Downstream[a] {
+			if err := visit(down); err != nil {
+				return err
+			}
+		}
+		state[a] = visited
+		order = append(order, a)
+		return nil
+	}
+
+	if err := visit(alias); err != nil {
+		return nil, err
+	}
+
+	// order is reverse-topological & ends with alias itself
+	cascade := make([]string, 0, len(order)-1)
+	for i := len(order) - 2; i >= 0; i-- {
+		cascade = append(cascade, order[i])
+	}
+	return cascade, nil
+}
@@ -0,0 +1,46 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDepGraphCascade(t *testing.T) {
+	g := &DepGraph{Upstream: map[string][]string{}, Downstream: map[string][]string{}}
+	// a -> b -> d
+	// a -> c -> d
+	g.add("a", "b")
+	g.add("a", "c")
+	g.add("b", "d")
+	g.add("c", "d")
+	g.add("c", "d")
+
+	if diff := cmp.Diff([]string{"a"}, g.Upstream["c"]); diff != "" {
+		t.Errorf("upstream mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := g.Cascade("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := map[string]int{}
+	for i, a := range got {
+		pos[a] = i
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 downstream datasets, got: %v", got)
+	}
+	if pos["d"] < pos["b"] || pos["d"] < pos["c"] {
+		t.Errorf("expected d to run after b & c, got: %v", got)
+	}
+
+	if got, err = g.Cascade("d"); err != nil || len(got) != 0 {
+		t.Errorf("expected no downstream datasets for d, got: %v, %v", got, err)
+	}
+
+	g.add("d", "a")
+	if _, err = g.Cascade("a"); err == nil {
+		t.Error("expected a cycle to error")
+	}
+}
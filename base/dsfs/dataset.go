@@ -38,6 +38,9 @@ var (
 	// files unresolved.
 	// TODO (b5) - allow -1 duration as a sentinel value for no timeout
 	OpenFileTimeoutDuration = time.Millisecond * 700
+	// ErrNoChanges is returned when a save has nothing to commit. Saves wrap
+	// it, use errors.Is to check for it
+	ErrNoChanges = fmt.Errorf("no changes")
 )
 
 // If a user has a dataset larger than the above limit, then instead of diffing we compare the
@@ -241,6 +244,15 @@ type SaveSwitches struct {
 	FileHint string
 	// Drop is a string of components to remove before saving
 	Drop string
+	// RecordHTTP is whether http requests made by a transform should be recorded
+	// into a fixture stored alongside the transform
+	RecordHTTP bool
+	// ReplayHTTP is whether a transform should be run against previously
+	// recorded http responses instead of the network
+	ReplayHTTP bool
+	// ReplayDatasets is whether a transform should load the versions of
+	// datasets it recorded as dependencies instead of their current heads
+	ReplayDatasets bool
 }
 
 // CreateDataset places a dataset into the store.
@@ -419,7 +431,7 @@ func generateCommit(store cafs.Filestore, prev, ds *dataset.Dataset, privKey cry
 	shortTitle, longMessage, err := generateCommitDescriptions(store, prev, ds, bodyAct, forceIfNoChanges)
 	if err != nil {
 		log.Debug(fmt.Errorf("error saving: %s", err))
-		return fmt.Errorf("error saving: %s", err)
+		return fmt.Errorf("error saving: %w", err)
 	}
 
 	if shortTitle == defaultCreatedDescription && fileHint != "" {
@@ -756,7 +768,7 @@ func generateCommitDescriptions(store cafs.Filestore, prev, ds *dataset.Dataset,
 		if forceIfNoChanges {
 			return "forced update", "forced update", nil
 		}
-		return "", "", fmt.Errorf("no changes")
+		return "", "", ErrNoChanges
 	}
 
 	return shortTitle, longMessage, nil
@@ -0,0 +1,59 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/startf"
+)
+
+// HTTPFixtureResourceKey is the transform resource name recorded http
+// fixtures are stored under
+const HTTPFixtureResourceKey = "http_fixture"
+
+// ErrNoHTTPFixture indicates a transform has no recorded http fixture
+var ErrNoHTTPFixture = fmt.Errorf("transform has no recorded http fixture")
+
+// LoadHTTPFixture reads the recorded http fixture of a transform from a store
+func LoadHTTPFixture(ctx context.Context, store cafs.Filestore, tf *dataset.Transform) (*startf.HTTPFixture, error) {
+	if tf == nil || tf.Resources == nil || tf.Resources[HTTPFixtureResourceKey] == nil {
+		return nil, ErrNoHTTPFixture
+	}
+
+	f, err := store.Get(ctx, tf.Resources[HTTPFixtureResourceKey].Path)
+	if err != nil {
+		return nil, fmt.Errorf("loading http fixture: %w", err)
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading http fixture: %w", err)
+	}
+	return startf.ParseHTTPFixture(data)
+}
+
+// WriteHTTPFixture places a recorded http fixture in the store, adding the
+// resulting content-addressed path to the transform's resources
+func WriteHTTPFixture(ctx context.Context, store cafs.Filestore, tf *dataset.Transform, fixture *startf.HTTPFixture) error {
+	data, err := json.Marshal(fixture)
+	if err != nil {
+		return err
+	}
+
+	path, err := store.Put(ctx, qfs.NewMemfileBytes("http_fixture.json", data))
+	if err != nil {
+		return fmt.Errorf("storing http fixture: %w", err)
+	}
+
+	if tf.Resources == nil {
+		tf.Resources = map[string]*dataset.TransformResource{}
+	}
+	tf.Resources[HTTPFixtureResourceKey] = &dataset.TransformResource{Path: path}
+	return nil
+}
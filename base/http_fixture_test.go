@@ -0,0 +1,43 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/startf"
+)
+
+func TestHTTPFixtureRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := cafs.NewMapstore()
+	tf := &dataset.Transform{}
+
+	if _, err := LoadHTTPFixture(ctx, store, tf); err != ErrNoHTTPFixture {
+		t.Errorf("expected loading a missing fixture to return ErrNoHTTPFixture, got: %v", err)
+	}
+
+	fixture := &startf.HTTPFixture{
+		Interactions: []*startf.HTTPInteraction{
+			{Method: "GET", URL: "http://example.com", StatusCode: 200, Body: []byte("hello")},
+		},
+	}
+	if err := WriteHTTPFixture(ctx, store, tf, fixture); err != nil {
+		t.Fatal(err)
+	}
+	if tf.Resources[HTTPFixtureResourceKey] == nil {
+		t.Fatal("expected fixture to be added to transform resources")
+	}
+
+	got, err := LoadHTTPFixture(ctx, store, tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Len() != 1 {
+		t.Fatalf("expected 1 interaction, got: %d", got.Len())
+	}
+	if string(got.Interactions[0].Body) != "hello" {
+		t.Errorf("body mismatch. expected: %q, got: %q", "hello", string(got.Interactions[0].Body))
+	}
+}
@@ -67,7 +67,7 @@ func DatasetLog(ctx context.Context, r repo.Repo, ref reporef.DatasetRef, limit,
 	if pro, err := r.Profile(); err == nil && ref.Peername == pro.Peername {
 		go func() {
 			if err := constructDatasetLogFromHistory(context.Background(), r, reporef.ConvertToDsref(ref)); err != nil {
-				log.Errorf("constructDatasetLogFromHistory: %s", err)
+				reqLog.Errorf(ctx, "constructDatasetLogFromHistory: %s", err)
 			}
 		}()
 	}
@@ -23,12 +23,12 @@ func CreatePreview(ctx context.Context, r repo.Repo, ref dsref.Ref) (ds *dataset
 
 	ds, err = dsfs.LoadDataset(ctx, r.Store(), ref.Path)
 	if err != nil {
-		log.Errorf("CreatePreview loading dataset: %s", err.Error())
+		reqLog.Errorf(ctx, "CreatePreview loading dataset: %s", err.Error())
 		return nil, err
 	}
 
 	if err = ds.OpenBodyFile(ctx, r.Store()); err != nil {
-		log.Errorf("CreatePreview opening body file: %s", err.Error())
+		reqLog.Errorf(ctx, "CreatePreview opening body file: %s", err.Error())
 		return nil, err
 	}
 
@@ -39,7 +39,7 @@ func CreatePreview(ctx context.Context, r repo.Repo, ref dsref.Ref) (ds *dataset
 
 	data, err := ConvertBodyFile(ds.BodyFile(), ds.Structure, st, MaxNumDatasetRowsInPreview, 0, false)
 	if err != nil {
-		log.Errorf("CreatePreview converting body file: %s", err.Error())
+		reqLog.Errorf(ctx, "CreatePreview converting body file: %s", err.Error())
 		return nil, err
 	}
 
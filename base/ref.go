@@ -124,7 +124,7 @@ func RenameDatasetRef(ctx context.Context, r repo.Repo, curr, next dsref.Ref) (*
 	}
 	// Canonicalize the existing reference so that we have ProfileID and Path
 	if err := repo.CanonicalizeDatasetRef(r, &currRef); err != nil && err != repo.ErrNoHistory {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return nil, fmt.Errorf("error with existing reference: %s", err.Error())
 	}
 	// Canonicalize the next reference to make sure it doesn't exist
@@ -133,7 +133,7 @@ func RenameDatasetRef(ctx context.Context, r repo.Repo, curr, next dsref.Ref) (*
 		// successful canonicalization on rename is an error
 		return nil, fmt.Errorf("dataset '%s/%s' already exists", nextRef.Peername, nextRef.Name)
 	} else if err != repo.ErrNotFound {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return nil, fmt.Errorf("error with new reference: %s", err.Error())
 	}
 	// Assign state that stays the same during a rename
@@ -171,7 +171,7 @@ func RenameDatasetRef(ctx context.Context, r repo.Repo, curr, next dsref.Ref) (*
 // changed
 // TODO (b5) - make this transactional
 func ModifyRepoUsername(ctx context.Context, r repo.Repo, book *logbook.Book, from, to string) error {
-	log.Debugf("change peername: %s -> %s", from, to)
+	reqLog.Debugf(ctx, "change peername: %s -> %s", from, to)
 	// TODO (b5) - we need to immediately update all dataset references in the refstore on rename
 	// because we currently rely on dsref as our source of canonicalization.
 	// Many places in our codebase call repo.CanonicalizeDatasetRef with an alias reference
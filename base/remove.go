@@ -29,7 +29,7 @@ func RemoveEntireDataset(ctx context.Context, r repo.Repo, ref dsref.Ref, histor
 		if _, err := RemoveNVersionsFromStore(ctx, r, ref, -1); err == nil {
 			didRemove = appendString(didRemove, "history")
 		} else {
-			log.Debugf("Remove, base.RemoveNVersionsFromStore failed, error: %s", err)
+			reqLog.Debugf(ctx, "Remove, base.RemoveNVersionsFromStore failed, error: %s", err)
 			removeErr = err
 		}
 	}
@@ -41,7 +41,7 @@ func RemoveEntireDataset(ctx context.Context, r repo.Repo, ref dsref.Ref, histor
 		// If the logbook is missing, it's not an error worth stopping for, since we're
 		// deleting the dataset anyway. This can happen from adding a foreign dataset.
 		if err != oplog.ErrNotFound {
-			log.Debugf("Remove, logbook.WriteDatasetDelete failed, error: %s", err)
+			reqLog.Debugf(ctx, "Remove, logbook.WriteDatasetDelete failed, error: %s", err)
 			removeErr = err
 		}
 	}
@@ -56,7 +56,7 @@ func RemoveEntireDataset(ctx context.Context, r repo.Repo, ref dsref.Ref, histor
 		didRemove = appendString(didRemove, "refstore")
 	}
 	if err := r.DeleteRef(datasetRef); err != nil {
-		log.Debugf("Remove, DeleteRef failed, error: %s", err)
+		reqLog.Debugf(ctx, "Remove, DeleteRef failed, error: %s", err)
 		removeErr = err
 	}
 	return didRemove, removeErr
@@ -129,12 +129,12 @@ func RemoveNVersionsFromStore(ctx context.Context, r repo.Repo, curr dsref.Ref,
 			// Note: We want delete to succeed even if datasets are remote, so we don't fail on
 			// this error, and break early instead.
 			if strings.Contains(err.Error(), "context deadline exceeded") {
-				log.Debugf("could not load dataset ref, not found locally")
+				reqLog.Debugf(ctx, "could not load dataset ref, not found locally")
 				break
 			}
 			// TODO (b5) - removing dataset versions should rely on logbook, which is able
 			// to traverse across missing datasets in qfs
-			log.Debugf("error fetching previous: %s", err)
+			reqLog.Debugf(ctx, "error fetching previous: %s", err)
 			break
 		}
 		dest.Path = loadedPrev.Path
@@ -208,11 +208,11 @@ func RewindDatasetRef(ctx context.Context, r repo.Repo, curr, next dsref.Ref) (*
 
 	// Both references need to canonicalize
 	if err := repo.CanonicalizeDatasetRef(r, &currRef); err != nil && err != repo.ErrNoHistory {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return nil, fmt.Errorf("error with existing reference: %s", err.Error())
 	}
 	if err := repo.CanonicalizeDatasetRef(r, &nextRef); err != nil && err != repo.ErrNoHistory {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return nil, fmt.Errorf("error with target reference: %s", err.Error())
 	}
 
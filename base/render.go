@@ -129,7 +129,7 @@ func Render(ctx context.Context, r repo.Repo, ref reporef.DatasetRef, tmplData [
 
 	ds, err := dsfs.LoadDataset(ctx, store, ref.Path)
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return nil, err
 	}
 	if err := OpenDataset(ctx, r.Filesystem(), ds); err != nil {
@@ -22,11 +22,6 @@ type SaveSwitches = dsfs.SaveSwitches
 
 // SaveDataset initializes a dataset from a dataset pointer and data file
 func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *dataset.Dataset, secrets map[string]string, scriptOut io.Writer, sw SaveSwitches) (ref reporef.DatasetRef, err error) {
-	var (
-		prevPath string
-		pro      *profile.Profile
-	)
-
 	// TODO(dlong): Set this in the caller, return err if no peername, add test for it
 	// Actually, is it possible to save a dataset using any peername other than "me" or
 	// the user's own username? Should we just get the current user's name from the
@@ -39,12 +34,12 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 
 	prev, mutable, prevPath, err := PrepareDatasetSave(ctx, r, changes.Peername, changes.Name)
 	if err != nil {
-		log.Errorf("preparing dataset: %s", err)
+		reqLog.Errorf(ctx, "preparing dataset: %s", err)
 		return
 	}
 
 	if prevPath != "" {
-		log.Debugf("loading previous path: %s", prevPath)
+		reqLog.Debugf(ctx, "loading previous path: %s", prevPath)
 		if sw.NewName && isInferredName {
 			// Using --new flag, name was inferred, but it's already in use. Because the --new
 			// flag was given, user is requesting we invent a unique name. Increment a counter
@@ -67,17 +62,50 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 		}
 	}
 
-	if pro, err = r.Profile(); err != nil {
+	return saveDataset(ctx, r, str, changes, prev, mutable, prevPath, secrets, scriptOut, sw)
+}
+
+// SaveDatasetFrom is SaveDataset, applying changes to the version at prevPath
+// instead of the dataset's head. An empty prevPath saves changes as the
+// first version of a dataset
+func SaveDatasetFrom(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *dataset.Dataset, prevPath string, secrets map[string]string, scriptOut io.Writer, sw SaveSwitches) (ref reporef.DatasetRef, err error) {
+	prev, mutable := &dataset.Dataset{}, &dataset.Dataset{}
+	if prevPath != "" {
+		if prev, mutable, err = PrepareDatasetSaveFrom(ctx, r, prevPath); err != nil {
+			return
+		}
+	}
+	return saveDataset(ctx, r, str, changes, prev, mutable, prevPath, secrets, scriptOut, sw)
+}
+
+func saveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes, prev, mutable *dataset.Dataset, prevPath string, secrets map[string]string, scriptOut io.Writer, sw SaveSwitches) (ref reporef.DatasetRef, err error) {
+	pro, err := r.Profile()
+	if err != nil {
 		return
 	}
 
+	// replayed fixtures must be loaded before dry-runs swap out the store
+	var fixture *startf.HTTPFixture
+	if changes.Transform != nil && sw.ReplayHTTP {
+		if fixture, err = LoadHTTPFixture(ctx, r.Store(), changes.Transform); err == ErrNoHTTPFixture {
+			fixture, err = LoadHTTPFixture(ctx, r.Store(), prev.Transform)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	// transforms read the datasets they depend on from the real repo, even on
+	// dry-runs
+	tfRepo := r
+
 	if sw.DryRun {
 		str.PrintErr("🏃🏽‍♀️ dry run\n")
 
 		// dry-runs store to an in-memory repo
 		r, err = repo.NewMemRepo(pro, cafs.NewMapstore(), r.Filesystem(), profile.NewMemStore())
 		if err != nil {
-			log.Debugf("creating new memRepo: %s", err)
+			reqLog.Debugf(ctx, "creating new memRepo: %s", err)
 			return
 		}
 	}
@@ -88,16 +116,39 @@ func SaveDataset(ctx context.Context, r repo.Repo, str ioes.IOStreams, changes *
 		mutateCheck := startf.MutatedComponentsFunc(changes)
 
 		opts := []func(*startf.ExecOpts){
-			startf.AddQriRepo(r),
+			startf.AddQriRepo(tfRepo),
 			startf.AddMutateFieldCheck(mutateCheck),
 			startf.SetErrWriter(scriptOut),
 			startf.SetSecrets(secrets),
 		}
 
-		if err = startf.ExecScript(ctx, changes, prev, opts...); err != nil {
+		if sw.ReplayDatasets {
+			// running the transform clears its recorded dependencies
+			opts = append(opts, startf.PinDatasets(startf.DatasetResources(changes.Transform)))
+		}
+
+		if sw.ReplayHTTP {
+			opts = append(opts, startf.ReplayHTTP(fixture))
+		} else if sw.RecordHTTP {
+			fixture = &startf.HTTPFixture{}
+			opts = append(opts, startf.RecordHTTP(fixture))
+		}
+
+		if err = startf.Run(ctx, changes, prev, opts...); err != nil {
 			return
 		}
 
+		if sw.RecordHTTP && !sw.ReplayHTTP {
+			if err = WriteHTTPFixture(ctx, r.Store(), changes.Transform, fixture); err != nil {
+				return
+			}
+			str.PrintErr(fmt.Sprintf("📼 recorded %d http requests\n", fixture.Len()))
+		} else if !sw.ReplayHTTP && changes.Transform.Resources != nil {
+			// a fixture carried over from a previous version no longer describes
+			// the requests this run made
+			delete(changes.Transform.Resources, HTTPFixtureResourceKey)
+		}
+
 		str.PrintErr("✅ transform complete\n")
 	}
 
@@ -157,22 +208,22 @@ func CreateDataset(ctx context.Context, r repo.Repo, streams ioes.IOStreams, ds,
 
 	pro, err = r.Profile()
 	if err != nil {
-		log.Debugf("getting repo profile: %s", err)
+		reqLog.Debugf(ctx, "getting repo profile: %s", err)
 		return
 	}
 
 	if err = Drop(ds, sw.Drop); err != nil {
-		log.Debugf("dropping components: %s", err)
+		reqLog.Debugf(ctx, "dropping components: %s", err)
 		return ref, err
 	}
 
 	if err = ValidateDataset(ds); err != nil {
-		log.Debugf("ValidateDataset: %s", err)
+		reqLog.Debugf(ctx, "ValidateDataset: %s", err)
 		return
 	}
 
 	if path, err = dsfs.CreateDataset(ctx, r.Store(), ds, dsPrev, r.PrivateKey(), sw); err != nil {
-		log.Debugf("dsfs.CreateDataset: %s", err)
+		reqLog.Debugf(ctx, "dsfs.CreateDataset: %s", err)
 		return
 	}
 	if ds.PreviousPath != "" && ds.PreviousPath != "/" {
@@ -197,7 +248,7 @@ func CreateDataset(ctx context.Context, r repo.Repo, streams ioes.IOStreams, ds,
 
 	if !sw.DryRun {
 		if err = r.PutRef(ref); err != nil {
-			log.Debugf("r.PutRef: %s", err)
+			reqLog.Debugf(ctx, "r.PutRef: %s", err)
 			return
 		}
 
@@ -226,7 +277,7 @@ func CreateDataset(ctx context.Context, r repo.Repo, streams ioes.IOStreams, ds,
 	// TODO (b5): this should be replaced with a call to OpenDataset with a qfs that
 	// knows about the store
 	if resBody, err = r.Store().Get(ctx, ref.Dataset.BodyPath); err != nil {
-		log.Error("error getting from store:", err.Error())
+		reqLog.Errorf(ctx, "error getting from store: %s", err)
 	}
 	ref.Dataset.SetBodyFile(resBody)
 	return
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewAPITokenCommand creates a new `qri apitoken` command for managing tokens
+// that authorize JSON API requests
+func NewAPITokenCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &APITokenOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "apitoken",
+		Short: "manage tokens for the JSON API",
+		Long: `API tokens authorize requests to the JSON API that ` + "`qri connect`" + ` serves.
+Send a token in an "Authorization: Bearer TOKEN" header. Tokens are only
+checked when the api.requireauth config value is true.
+
+Each token has a scope. Scopes grant access to everything the scopes before
+them do:
+  read     get datasets, history, status & other read-only requests
+  write    save, remove, rename & other changes to local datasets
+  publish  publish & unpublish datasets
+  admin    everything, including connecting to peers & registry profiles
+
+Tokens created with --profile act as a tenant profile this host serves, see
+` + "`qri tenant`" + `. Tenant tokens can't have admin scope.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "create a new api token",
+		Example: `  # Create a token that can read & save datasets:
+  $ qri apitoken create --name ci --scope write`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Create()
+		},
+	}
+	create.Flags().StringVar(&o.Name, "name", "", "description of what the token is for")
+	create.Flags().StringVar(&o.Scope, "scope", string(apitoken.ScopeRead), "token scope, one of read, write, publish, admin")
+	create.Flags().StringVar(&o.Profile, "profile", "", "peername of a tenant the token acts as")
+	create.MarkFlagRequired("name")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list api tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	revoke := &cobra.Command{
+		Use:   "revoke ID",
+		Short: "revoke an api token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Revoke()
+		},
+	}
+
+	cmd.AddCommand(create, list, revoke)
+	return cmd
+}
+
+// APITokenOptions encapsulates state for the apitoken command
+type APITokenOptions struct {
+	ioes.IOStreams
+
+	Name    string
+	Scope   string
+	Profile string
+	ID      string
+
+	APITokenMethods *lib.APITokenMethods
+}
+
+// Complete adds any missing configuration that can only be added just before
+// calling Run
+func (o *APITokenOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.ID = args[0]
+	}
+	o.APITokenMethods, err = f.APITokenMethods()
+	return err
+}
+
+// Create executes the apitoken create command
+func (o *APITokenOptions) Create() error {
+	if o.Name == "" {
+		return errors.New(lib.ErrBadArgs, "--name is required")
+	}
+	p := &lib.CreateAPITokenParams{
+		Name:    o.Name,
+		Scope:   o.Scope,
+		Profile: o.Profile,
+	}
+	res := lib.CreateAPITokenResult{}
+	if err := o.APITokenMethods.Create(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "created %s token %q with id %s", res.Token.Scope, res.Token.Name, res.Token.ID)
+	printWarning(o.ErrOut, "copy this token now, it won't be shown again:")
+	fmt.Fprintln(o.Out, res.Secret)
+	return nil
+}
+
+// List executes the apitoken list command
+func (o *APITokenOptions) List() error {
+	p := false
+	res := []apitoken.Token{}
+	if err := o.APITokenMethods.List(&p, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no api tokens")
+		return nil
+	}
+	for _, t := range res {
+		if t.Profile != "" {
+			fmt.Fprintf(o.Out, "%s  %-7s  %s  %s (%s)\n", t.ID, t.Scope, t.Created.Format("2006-01-02"), t.Name, t.Profile)
+			continue
+		}
+		fmt.Fprintf(o.Out, "%s  %-7s  %s  %s\n", t.ID, t.Scope, t.Created.Format("2006-01-02"), t.Name)
+	}
+	return nil
+}
+
+// Revoke executes the apitoken revoke command
+func (o *APITokenOptions) Revoke() error {
+	res := false
+	if err := o.APITokenMethods.Revoke(&o.ID, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "revoked api token %s", o.ID)
+	return nil
+}
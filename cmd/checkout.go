@@ -18,9 +18,27 @@ func NewCheckoutCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "checkout DATASET",
 		Short: "create a linked directory and write dataset files to that directory",
-		Long:  ``,
+		Long: `Checkout creates a directory and writes the components of a dataset to it as
+files, linking the directory to the dataset. Large datasets can be partially
+checked out: components left out with --components, and the unwritten part of
+a body cut with --body-limit stay in the repo as placeholders. Status reports
+placeholders as unmodified, and save keeps their stored versions. A body cut
+with --body-limit is read-only, run "qri restore body" to check out all of it.
+
+Use --body-format to edit the body in a different format than it's stored in.
+The body & structure are written in the working format, and save converts
+them back to the stored format, keeping the schema.`,
 		Example: `  # Place a copy of me/annual_pop in the ./annual_pop directory:
-  $ qri checkout me/annual_pop`,
+  $ qri checkout me/annual_pop
+
+  # Check out only metadata, leaving the structure and body in the repo:
+  $ qri checkout me/annual_pop --components meta,readme
+
+  # Check out the first 100 entries of the body:
+  $ qri checkout me/annual_pop --body-limit 100
+
+  # Edit a csv body as a spreadsheet:
+  $ qri checkout me/annual_pop --body-format xlsx`,
 		Annotations: map[string]string{
 			"group": "workdir",
 		},
@@ -33,6 +51,10 @@ func NewCheckoutCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringSliceVar(&o.Components, "components", nil, "comma-separated list of components to check out, defaults to all")
+	cmd.Flags().IntVar(&o.BodyLimit, "body-limit", 0, "maximum number of body entries to check out, defaults to all")
+	cmd.Flags().StringVar(&o.BodyFormat, "body-format", "", "format to check out the body in, one of: csv, json, xlsx. defaults to the stored format")
+
 	return cmd
 }
 
@@ -44,7 +66,10 @@ type CheckoutOptions struct {
 
 	FSIMethods *lib.FSIMethods
 
-	Dir string
+	Dir        string
+	Components []string
+	BodyLimit  int
+	BodyFormat string
 }
 
 // Complete configures the checkout command
@@ -92,7 +117,14 @@ func (o *CheckoutOptions) Run() (err error) {
 	}
 
 	var res string
-	err = o.FSIMethods.Checkout(&lib.CheckoutParams{Dir: o.Dir, Ref: ref}, &res)
+	p := &lib.CheckoutParams{
+		Dir:        o.Dir,
+		Ref:        ref,
+		Components: o.Components,
+		BodyLimit:  o.BodyLimit,
+		BodyFormat: o.BodyFormat,
+	}
+	err = o.FSIMethods.Checkout(p, &res)
 	if err != nil {
 		return err
 	}
@@ -21,8 +21,7 @@ func TestConnect(t *testing.T) {
 	_, registryServer := regmock.NewMockServer()
 
 	// Configure ports such that other tests do not conflict with the connection ports
-	// TODO(dustmop): Add websocket.port to config, set that here
-	run.MustExec(t, "qri config set api.port 0 rpc.port 0")
+	run.MustExec(t, "qri config set api.port 0 api.websocketport 0")
 
 	cmd := "qri connect --registry=" + registryServer.URL
 
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewDepsCommand creates a new `qri deps` command that shows datasets
+// connected to a dataset through transforms
+func NewDepsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &DepsOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "deps DATASET",
+		Short: "show datasets a dataset depends on, and datasets that depend on it",
+		Long: `Deps lists the upstream datasets a dataset's transform loads, and the
+downstream datasets with transforms that load it. Dependencies are recorded
+each time a transform runs, and are read from the latest version of every
+dataset in your repo.
+
+Downstream datasets are listed in the order ` + "`qri save --cascade`" + ` re-runs
+their transforms.`,
+		Example: `  # Show dependencies of me/annual_pop:
+  $ qri deps me/annual_pop
+
+  # Get dependencies as JSON:
+  $ qri deps --format json me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "output format. one of [json]")
+
+	return cmd
+}
+
+// DepsOptions encapsulates state for the deps command
+type DepsOptions struct {
+	ioes.IOStreams
+
+	Refs   *RefSelect
+	Format string
+
+	DatasetMethods *lib.DatasetMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *DepsOptions) Complete(f Factory, args []string) (err error) {
+	if o.DatasetMethods, err = f.DatasetMethods(); err != nil {
+		return
+	}
+
+	o.Refs, err = GetCurrentRefSelect(f, args, 1, nil)
+	return err
+}
+
+// Validate checks that any user input is valid
+func (o *DepsOptions) Validate() error {
+	if o.Format != "" && o.Format != "json" {
+		return fmt.Errorf("format must be json")
+	}
+	return nil
+}
+
+// Run executes the deps command
+func (o *DepsOptions) Run() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	p := &lib.DepsParams{Ref: o.Refs.Ref()}
+	res := &lib.DepsResult{}
+	if err := o.DatasetMethods.Deps(p, res); err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, string(data))
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	writeDeps(buf, "upstream", res.Upstream)
+	fmt.Fprintln(buf)
+	writeDeps(buf, "downstream", res.Cascade)
+	printInfo(o.Out, buf.String())
+	return nil
+}
+
+func writeDeps(buf *bytes.Buffer, label string, aliases []string) {
+	fmt.Fprintf(buf, "%s:\n", label)
+	if len(aliases) == 0 {
+		fmt.Fprintf(buf, "  none\n")
+		return
+	}
+	for _, a := range aliases {
+		fmt.Fprintf(buf, "  %s\n", a)
+	}
+}
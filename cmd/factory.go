@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"net/rpc"
 	"os"
 	"path/filepath"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo/gen"
+	"github.com/qri-io/qri/rpc"
 )
 
 // Factory is an interface for providing required structures to cobra commands
@@ -36,6 +36,9 @@ type Factory interface {
 	SearchMethods() (*lib.SearchMethods, error)
 	SQLMethods() (*lib.SQLMethods, error)
 	FSIMethods() (*lib.FSIMethods, error)
+	TransformMethods() (*lib.TransformMethods, error)
+	APITokenMethods() (*lib.APITokenMethods, error)
+	TenantMethods() (*lib.TenantMethods, error)
 
 	// TODO (b5) - these should be deprecated:
 	ExportRequests() (*lib.ExportRequests, error)
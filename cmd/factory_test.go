@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"net/rpc"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +16,7 @@ import (
 	"github.com/qri-io/qri/repo/gen"
 	"github.com/qri-io/qri/repo/test"
 	repotest "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/rpc"
 )
 
 // TestFactory is an implementation of the Factory interface for testing purposes
@@ -201,6 +201,21 @@ func (t TestFactory) SQLMethods() (*lib.SQLMethods, error) {
 	return lib.NewSQLMethods(t.inst), nil
 }
 
+// TransformMethods generates a lib.TransformMethods from internal state
+func (t TestFactory) TransformMethods() (*lib.TransformMethods, error) {
+	return lib.NewTransformMethods(t.inst), nil
+}
+
+// APITokenMethods generates a lib.APITokenMethods from internal state
+func (t TestFactory) APITokenMethods() (*lib.APITokenMethods, error) {
+	return lib.NewAPITokenMethods(t.inst), nil
+}
+
+// TenantMethods generates a lib.TenantMethods from internal state
+func (t TestFactory) TenantMethods() (*lib.TenantMethods, error) {
+	return lib.NewTenantMethods(t.inst), nil
+}
+
 // RenderRequests generates a lib.RenderRequests from internal state
 func (t TestFactory) RenderRequests() (*lib.RenderRequests, error) {
 	return lib.NewRenderRequests(t.repo, t.rpc), nil
@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
 )
@@ -48,7 +55,85 @@ func NewFSICommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(link, unlink)
+	watch := &cobra.Command{
+		Use:   "watch DATASET",
+		Short: "automatically save changes to a linked directory",
+		Long: `Watch waits for files in the working directory of a dataset to change, and
+saves a new version once the directory has been quiet for a while. Changes
+aren't saved while any component has a parse error or conflict. Watch runs
+until interrupted with ctrl+c.`,
+		Example: `  # Save changes to a linked dataset after 10 quiet seconds:
+  $ qri workdir watch me/dataset --quiet-period 10s
+
+  # Only save when the body is valid against the structure schema:
+  $ qri workdir watch me/dataset --validate`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Watch()
+		},
+	}
+	watch.Flags().DurationVar(&o.QuietPeriod, "quiet-period", lib.DefaultAutoSaveQuietPeriod, "how long the directory must go without changes before saving")
+	watch.Flags().BoolVar(&o.Validate, "validate", false, "only save when the body validates against the structure schema")
+
+	stash := &cobra.Command{
+		Use:   "stash [DATASET]",
+		Short: "set aside changes to a linked directory",
+		Long: `Stash copies uncommitted changes in a dataset's working directory into the
+repo, and returns the directory to the latest version of the dataset. Stashing
+doesn't create a version. Use "qri workdir stash pop" to re-apply the changes.`,
+		Example: `  # Set aside changes to the dataset linked to the current directory:
+  $ qri workdir stash -m "trying a new schema"
+
+  # Re-apply the most recently stashed changes:
+  $ qri workdir stash pop
+
+  # List stashed changes:
+  $ qri workdir stash list`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Stash()
+		},
+	}
+	stash.Flags().StringVarP(&o.Message, "message", "m", "", "description of the stashed changes")
+
+	pop := &cobra.Command{
+		Use:   "pop [DATASET]",
+		Short: "re-apply stashed changes to a linked directory",
+		Long: `Pop writes stashed changes back to a dataset's working directory and removes
+them from the stash. The working directory must be clean. If versions of the
+dataset saved since stashing change the same components as the stash, pop
+reports the conflicts and leaves the stash in place unless --force is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.StashPop()
+		},
+	}
+	pop.Flags().IntVar(&o.Index, "index", 0, "stash entry to pop, 0 is the most recent")
+	pop.Flags().BoolVar(&o.Force, "force", false, "apply changes despite conflicts or local changes")
+
+	stashList := &cobra.Command{
+		Use:   "list [DATASET]",
+		Short: "list stashed changes for a dataset",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.StashList()
+		},
+	}
+	stash.AddCommand(pop, stashList)
+
+	cmd.AddCommand(link, unlink, watch, stash)
 	return cmd
 }
 
@@ -56,9 +141,14 @@ func NewFSICommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 type FSIOptions struct {
 	ioes.IOStreams
 
-	Refs       *RefSelect
-	Path       string
-	FSIMethods *lib.FSIMethods
+	Refs        *RefSelect
+	Path        string
+	QuietPeriod time.Duration
+	Validate    bool
+	Message     string
+	Index       int
+	Force       bool
+	FSIMethods  *lib.FSIMethods
 }
 
 // Complete adds any missing configuration that can only be added just before
@@ -118,3 +208,105 @@ func (o *FSIOptions) Unlink() error {
 	}
 	return nil
 }
+
+// Watch executes the fsi watch command
+func (o *FSIOptions) Watch() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	p := &lib.AutoSaveParams{
+		Ref:          o.Refs.Ref(),
+		QuietPeriod:  o.QuietPeriod,
+		RequireValid: o.Validate,
+		OnResult: func(res lib.AutoSaveResult) {
+			switch {
+			case res.Err != nil:
+				printErr(o.ErrOut, res.Err)
+			case res.Skipped != "":
+				printInfo(o.ErrOut, "not saving: %s", res.Skipped)
+			default:
+				printSuccess(o.Out, "saved %s", res.Ref)
+			}
+		},
+	}
+	printInfo(o.ErrOut, "watching for changes, press ctrl+c to stop")
+	return o.FSIMethods.AutoSave(ctx, p)
+}
+
+// Stash executes the fsi stash command
+func (o *FSIOptions) Stash() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	p := &lib.StashParams{
+		Ref:     o.Refs.Ref(),
+		Message: o.Message,
+	}
+	res := fsi.StashEntry{}
+	if err := o.FSIMethods.Stash(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "stashed changes to %s", strings.Join(stashedComponents(res), ", "))
+	return nil
+}
+
+// StashPop executes the fsi stash pop command
+func (o *FSIOptions) StashPop() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	p := &lib.StashPopParams{
+		Ref:   o.Refs.Ref(),
+		Index: o.Index,
+		Force: o.Force,
+	}
+	res := lib.StashPopResult{}
+	if err := o.FSIMethods.StashPop(p, &res); err != nil {
+		return err
+	}
+	if len(res.Conflicts) > 0 {
+		printWarning(o.ErrOut, "applied changes that conflict with new versions: %s", strings.Join(res.Conflicts, ", "))
+	}
+	printSuccess(o.Out, "applied stashed changes to %s", strings.Join(stashedComponents(res.Entry), ", "))
+	return nil
+}
+
+// StashList executes the fsi stash list command
+func (o *FSIOptions) StashList() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	p := &lib.StashListParams{Ref: o.Refs.Ref()}
+	res := []fsi.StashEntry{}
+	if err := o.FSIMethods.StashList(p, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no stashed changes")
+		return nil
+	}
+	for i, e := range res {
+		fmt.Fprintf(o.Out, "%d  %s  %s  %s\n", i, e.Created.Format(time.RFC822), strings.Join(stashedComponents(e), ", "), e.Message)
+	}
+	return nil
+}
+
+// stashedComponents lists the components changed in a stash entry
+func stashedComponents(e fsi.StashEntry) []string {
+	var names []string
+	for _, si := range e.Changes {
+		if si.Type != fsi.STUnmodified {
+			names = append(names, si.Component)
+		}
+	}
+	return names
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,8 +13,10 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qri/dsref"
 	qrierr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/rpc"
 )
 
 var noPrompt = false
@@ -42,6 +45,19 @@ func printWarning(w io.Writer, msg string, params ...interface{}) {
 	fmt.Fprintln(w, color.New(color.FgYellow).Sprintf(msg, params...))
 }
 
+// printRPCEvent writes events a daemon publishes while running a command,
+// with the dataset they're about if they have one
+func printRPCEvent(w io.Writer) func(e rpc.Event) {
+	return func(e rpc.Event) {
+		payload := struct{ Ref dsref.Ref }{}
+		if err := json.Unmarshal(e.Payload, &payload); err == nil && !payload.Ref.IsEmpty() {
+			printInfo(w, "%s %s", e.Topic, payload.Ref.Alias())
+			return
+		}
+		printInfo(w, "%s", e.Topic)
+	}
+}
+
 func printErr(w io.Writer, err error, params ...interface{}) {
 	var qerr qrierr.Error
 	if errors.As(err, &qerr) {
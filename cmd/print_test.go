@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"bytes"
 	"runtime"
 	"testing"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/rpc"
 )
 
 func TestDoesCommandExist(t *testing.T) {
@@ -15,3 +19,15 @@ func TestDoesCommandExist(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintRPCEvent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	print := printRPCEvent(buf)
+	print(rpc.Event{Topic: event.ETDatasetSaved, Payload: []byte(`{"Ref":{"username":"peer","name":"movies"}}`)})
+	print(rpc.Event{Topic: event.ETConfigChanged, Payload: []byte(`{"Config":{}}`)})
+
+	expect := "dataset:saved peer/movies\nconfig:changed\n"
+	if buf.String() != expect {
+		t.Errorf("expected output %q, got %q", expect, buf.String())
+	}
+}
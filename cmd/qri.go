@@ -3,7 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"net/rpc"
 	"os"
 	"runtime"
 
@@ -12,6 +11,7 @@ import (
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo/gen"
+	"github.com/qri-io/qri/rpc"
 	"github.com/spf13/cobra"
 )
 
@@ -41,11 +41,13 @@ https://github.com/qri-io/qri/issues`,
 
 	cmd.AddCommand(
 		NewAddCommand(opt, ioStreams),
+		NewAPITokenCommand(opt, ioStreams),
 		NewAutocompleteCommand(opt, ioStreams),
 		NewCheckoutCommand(opt, ioStreams),
 		NewConfigCommand(opt, ioStreams),
 		NewConnectCommand(opt, ioStreams),
 		NewDAGCommand(opt, ioStreams),
+		NewDepsCommand(opt, ioStreams),
 		NewDiffCommand(opt, ioStreams),
 		NewExportCommand(opt, ioStreams),
 		NewFSICommand(opt, ioStreams),
@@ -67,6 +69,8 @@ https://github.com/qri-io/qri/issues`,
 		NewStatsCommand(opt, ioStreams),
 		NewStatusCommand(opt, ioStreams),
 		NewSQLCommand(opt, ioStreams),
+		NewTenantCommand(opt, ioStreams),
+		NewTransformCommand(opt, ioStreams),
 		NewUseCommand(opt, ioStreams),
 		NewValidateCommand(opt, ioStreams),
 		NewVersionCommand(opt, ioStreams),
@@ -130,6 +134,10 @@ func (o *QriOptions) Init() (err error) {
 		if err != nil {
 			return err
 		}
+		// show what a connected daemon does while it runs a command
+		if cli := o.inst.RPC(); cli != nil {
+			cli.OnEvent = printRPCEvent(o.IOStreams.ErrOut)
+		}
 		// Handle color and prompt flags which apply to every command
 		shouldColorOutput := !o.NoColor
 		cfg := o.inst.Config()
@@ -292,3 +300,30 @@ func (o *QriOptions) FSIMethods() (m *lib.FSIMethods, err error) {
 
 	return lib.NewFSIMethods(o.inst), nil
 }
+
+// APITokenMethods generates a lib.APITokenMethods from internal state
+func (o *QriOptions) APITokenMethods() (m *lib.APITokenMethods, err error) {
+	if err = o.Init(); err != nil {
+		return
+	}
+
+	return lib.NewAPITokenMethods(o.inst), nil
+}
+
+// TenantMethods generates a lib.TenantMethods from internal state
+func (o *QriOptions) TenantMethods() (m *lib.TenantMethods, err error) {
+	if err = o.Init(); err != nil {
+		return
+	}
+
+	return lib.NewTenantMethods(o.inst), nil
+}
+
+// TransformMethods generates a lib.TransformMethods from internal state
+func (o *QriOptions) TransformMethods() (m *lib.TransformMethods, err error) {
+	if err = o.Init(); err != nil {
+		return
+	}
+
+	return lib.NewTransformMethods(o.inst), nil
+}
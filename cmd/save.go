@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
+	"github.com/fatih/color"
+	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
@@ -26,6 +30,8 @@ your dataset’s log (which you can see by running ` + "`qri log <dataset_refere
 
 If the dataset you're changing has defined a transform, running ` + "`qri save`" + `
 will re execute the transform. To only re-run the transform, run save with no args.
+Transforms are written in starlark (.star files) or as a single SQL SELECT
+statement over other datasets (.sql files).
 
 Every time you save, you can provide a message about what you changed and why. 
 If you don’t provide a message Qri will automatically generate one for you.
@@ -42,7 +48,22 @@ commit message and title to the save.`,
   $ qri save --file /path/to/dataset.yaml me/annual_pop
   
   # Re-execute a dataset that has a transform:
-  $ qri save me/tf_dataset`,
+  $ qri save me/tf_dataset
+
+  # Derive a dataset from others with an SQL SELECT transform:
+  $ qri save --file transform.sql me/derived_dataset
+
+  # Preview the result of a transform without saving:
+  $ qri save --dry-run me/tf_dataset
+
+  # Re-execute a transform, recording http requests for later replay:
+  $ qri save --record me/tf_dataset
+
+  # Re-execute a transform offline using recorded http requests:
+  $ qri save --replay me/tf_dataset
+
+  # Save a new version & re-run every transform that loads this dataset:
+  $ qri save --body /path/to/data.csv --cascade me/annual_pop`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -68,7 +89,9 @@ commit message and title to the save.`,
 	// cmd.Flags().BoolVarP(&o.ShowValidation, "show-validation", "s", false, "display a list of validation errors upon adding")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
 	cmd.Flags().BoolVarP(&o.Publish, "publish", "p", false, "publish this dataset to the registry")
-	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "simulate saving a dataset")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "simulate saving a dataset, previewing changes against the head version")
+	cmd.Flags().IntVar(&o.PreviewLimit, "preview-limit", lib.DefaultPreviewBodyLimit, "number of body entries to preview in a dry run")
+	cmd.Flags().StringVar(&o.Format, "format", "", "output format of a dry run preview. one of [json]")
 	cmd.Flags().BoolVar(&o.Force, "force", false, "force a new commit, even if no changes are detected")
 	cmd.Flags().BoolVarP(&o.KeepFormat, "keep-format", "k", false, "convert incoming data to stored data format")
 	// TODO(dlong): --no-render is deprecated, viz are being phased out, in favor of readme.
@@ -76,6 +99,9 @@ commit message and title to the save.`,
 	cmd.Flags().BoolVarP(&o.NewName, "new", "n", false, "save a new dataset only, using an available name")
 	cmd.Flags().BoolVarP(&o.UseDscache, "use-dscache", "", false, "experimental: build and use dscache if none exists")
 	cmd.Flags().StringVar(&o.Drop, "drop", "", "comma-separated list of components to remove")
+	cmd.Flags().BoolVar(&o.RecordHTTP, "record", false, "record transform http requests for offline replay")
+	cmd.Flags().BoolVar(&o.ReplayHTTP, "replay", false, "re-run the transform against http requests recorded with the previous version")
+	cmd.Flags().BoolVar(&o.Cascade, "cascade", false, "re-run transforms of datasets that depend on this one")
 
 	return cmd
 }
@@ -103,6 +129,11 @@ type SaveOptions struct {
 	Secrets        []string
 	NewName        bool
 	UseDscache     bool
+	RecordHTTP     bool
+	ReplayHTTP     bool
+	Cascade        bool
+	PreviewLimit   int
+	Format         string
 
 	DatasetMethods *lib.DatasetMethods
 	FSIMethods     *lib.FSIMethods
@@ -139,6 +170,15 @@ func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that all user input is valid
 func (o *SaveOptions) Validate() error {
+	if o.RecordHTTP && o.ReplayHTTP {
+		return errors.New(lib.ErrBadArgs, "cannot use both --record and --replay flags")
+	}
+	if o.Cascade && o.DryRun {
+		return errors.New(lib.ErrBadArgs, "cannot use both --cascade and --dry-run flags")
+	}
+	if o.Format != "" && o.Format != "json" {
+		return errors.New(lib.ErrBadArgs, "dry run preview format must be json")
+	}
 	return nil
 }
 
@@ -164,10 +204,12 @@ func (o *SaveOptions) Run() (err error) {
 		Drop:                o.Drop,
 		ConvertFormatToPrev: o.KeepFormat,
 		Force:               o.Force,
-		ReturnBody:          o.DryRun,
 		ShouldRender:        !o.NoRender,
 		NewName:             o.NewName,
 		UseDscache:          o.UseDscache,
+		RecordHTTP:          o.RecordHTTP,
+		ReplayHTTP:          o.ReplayHTTP,
+		Cascade:             o.Cascade,
 	}
 
 	if o.Secrets != nil {
@@ -187,6 +229,10 @@ continue?`, true) {
 		}
 	}
 
+	if o.DryRun {
+		return o.dryRun(p)
+	}
+
 	res := &reporef.DatasetRef{}
 	if err = o.DatasetMethods.Save(p, res); err != nil {
 		return err
@@ -198,13 +244,85 @@ continue?`, true) {
 		printWarning(o.ErrOut, fmt.Sprintf("this dataset has %d validation errors", res.Dataset.Structure.ErrCount))
 	}
 
-	if o.DryRun {
-		data, err := json.MarshalIndent(res.Dataset, "", "  ")
+	return nil
+}
+
+// dryRun previews a save, printing the would-be commit, structure & schema
+// changes and a page of the resulting body
+func (o *SaveOptions) dryRun(p *lib.SaveParams) error {
+	pp := &lib.PreviewSaveParams{
+		SaveParams: *p,
+		BodyLimit:  o.PreviewLimit,
+	}
+	res := &lib.PreviewSaveResult{}
+	if err := o.DatasetMethods.PreviewSave(pp, res); err != nil {
+		return err
+	}
+
+	o.StopSpinner()
+	printInfo(o.ErrOut, "dry run: would save %s", res.Ref)
+	if res.Dataset.Structure != nil && res.Dataset.Structure.ErrCount > 0 {
+		printWarning(o.ErrOut, fmt.Sprintf("this dataset has %d validation errors", res.Dataset.Structure.ErrCount))
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(res, "", "  ")
 		if err != nil {
 			return err
 		}
 		fmt.Fprint(o.Out, string(data))
+		return nil
 	}
 
-	return nil
+	return printToPager(o.Out, dryRunPreview(res))
+}
+
+func dryRunPreview(res *lib.PreviewSaveResult) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	ds := res.Dataset
+
+	if res.HeadPath == "" {
+		fmt.Fprintf(buf, "dry run of new dataset %s\n\n", res.Ref)
+	} else {
+		fmt.Fprintf(buf, "dry run against head %s\n\n", res.HeadPath)
+	}
+
+	if ds.Commit != nil {
+		fmt.Fprintf(buf, "commit: %s\n", ds.Commit.Title)
+		if ds.Commit.Message != "" {
+			fmt.Fprintf(buf, "%s\n", ds.Commit.Message)
+		}
+		buf.WriteByte('\n')
+	}
+
+	if ds.Structure != nil {
+		fmt.Fprintf(buf, "entries: %d (head: %d)\n\n", ds.Structure.Entries, res.HeadEntries)
+	}
+
+	writeDeltas := func(name string, deltas []*lib.Delta, stat *lib.DiffStat) {
+		if len(deltas) == 0 {
+			fmt.Fprintf(buf, "%s: no changes\n\n", name)
+			return
+		}
+		fmt.Fprintf(buf, "%s changes:\n", name)
+		deepdiff.FormatPrettyStats(buf, stat, !color.NoColor)
+		buf.WriteByte('\n')
+		deepdiff.FormatPretty(buf, deltas, !color.NoColor)
+		buf.WriteByte('\n')
+	}
+	writeDeltas("structure", res.Structure, res.StructureStat)
+	writeDeltas("schema", res.Schema, res.SchemaStat)
+
+	if len(res.Body) > 0 {
+		buf.WriteString("body preview:\n")
+		var body interface{}
+		if err := json.Unmarshal(res.Body, &body); err == nil {
+			data, _ := json.MarshalIndent(body, "", "  ")
+			buf.Write(data)
+		} else {
+			buf.Write(res.Body)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf
 }
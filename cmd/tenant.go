@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo/gen"
+	"github.com/spf13/cobra"
+)
+
+// NewTenantCommand creates a new `qri tenant` command for managing the
+// profiles a host serves
+func NewTenantCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &TenantOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "tenant",
+		Short: "manage profiles this host serves",
+		Long: `Tenants let one ` + "`qri connect`" + ` host serve several profiles. Each tenant
+has its own keypair, logbook & dataset references, and shares the host's
+content store.
+
+API requests act as a tenant when they carry an api token issued for it:
+  $ qri apitoken create --name laptop --scope write --profile PEERNAME
+
+Hosts only serve tenants when the API requires authorization. Otherwise
+requests without a token act as the host. Turn it on before adding tenants:
+  $ qri config set api.requireauth true
+
+Tenants are offline: they can publish to remotes, but don't connect to
+peers themselves.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	add := &cobra.Command{
+		Use:   "add PEERNAME",
+		Short: "add a tenant profile with a new keypair",
+		Example: `  # Add a tenant, then issue it a token:
+  $ qri tenant add alice
+  $ qri apitoken create --name alice --scope publish --profile alice`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Add()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list tenants",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:   "remove PEERNAME",
+		Short: "remove a tenant, its dataset references & api tokens",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Remove()
+		},
+	}
+
+	cmd.AddCommand(add, list, remove)
+	return cmd
+}
+
+// TenantOptions encapsulates state for the tenant command
+type TenantOptions struct {
+	ioes.IOStreams
+
+	Peername string
+
+	Generator     gen.CryptoGenerator
+	TenantMethods *lib.TenantMethods
+}
+
+// Complete adds any missing configuration that can only be added just before
+// calling Run
+func (o *TenantOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Peername = args[0]
+	}
+	o.Generator = f.CryptoGenerator()
+	o.TenantMethods, err = f.TenantMethods()
+	return err
+}
+
+// Add executes the tenant add command
+func (o *TenantOptions) Add() error {
+	if o.Peername == "" {
+		return errors.New(lib.ErrBadArgs, "peername is required")
+	}
+	privKey, profileID := o.Generator.GeneratePrivateKeyAndPeerID()
+	p := &lib.AddTenantParams{
+		Peername:  o.Peername,
+		PrivKey:   privKey,
+		ProfileID: profileID,
+	}
+	res := config.ProfilePod{}
+	if err := o.TenantMethods.Add(p, &res); err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "added tenant %s with profile ID %s", res.Peername, res.ID)
+	return nil
+}
+
+// List executes the tenant list command
+func (o *TenantOptions) List() error {
+	p := false
+	res := []config.ProfilePod{}
+	if err := o.TenantMethods.List(&p, &res); err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		printInfo(o.Out, "no tenants")
+		return nil
+	}
+	for _, pro := range res {
+		fmt.Fprintf(o.Out, "%s  %s  %s\n", pro.ID, pro.Created.Format("2006-01-02"), pro.Peername)
+	}
+	return nil
+}
+
+// Remove executes the tenant remove command
+func (o *TenantOptions) Remove() error {
+	res := false
+	if err := o.TenantMethods.Remove(&o.Peername, &res); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "removed tenant %s", o.Peername)
+	return nil
+}
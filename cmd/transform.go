@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/startf"
+	"github.com/spf13/cobra"
+)
+
+// NewTransformCommand creates a new `qri transform` command for working with
+// dataset transforms
+func NewTransformCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &TransformOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "transform",
+		Short: "tools for writing dataset transforms",
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	test := &cobra.Command{
+		Use:   "test [DIR]",
+		Short: "run transform tests in a working directory",
+		Long: `Test runs every function that begins with "test_" in the ` + "`transform_test.star`" + `
+file of a working directory against the ` + "`transform.star`" + ` file next to it.
+
+Tests never touch the network or the repo. Instead tests describe the world a
+transform runs in using these builtins:
+
+  mock_dataset(ref, body=None, meta=None)   respond to load_dataset(ref)
+  mock_http(url, body="", method="GET", status=200, request_body=None)
+      respond to http requests, only those sending request_body when it's set
+  set_prev(body=None, meta=None)   set the previous version of the dataset
+  run_transform(config=None)   run the transform, returning the result
+
+and check results with assert.eq, assert.ne, assert.true and assert.fails.`,
+		Example: `  # Run transform tests in the current working directory:
+  $ qri transform test
+
+  # Run transform tests in another directory:
+  $ qri transform test ~/datasets/world_bank_population`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Test()
+		},
+	}
+
+	cmd.AddCommand(test)
+	return cmd
+}
+
+// TransformOptions encapsulates state for the transform command
+type TransformOptions struct {
+	ioes.IOStreams
+
+	Dir              string
+	TransformMethods *lib.TransformMethods
+}
+
+// Complete adds any missing configuration that can only be added just before
+// calling Run
+func (o *TransformOptions) Complete(f Factory, args []string) (err error) {
+	if o.TransformMethods, err = f.TransformMethods(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.Dir = args[0]
+	} else if o.Dir, err = os.Getwd(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Test executes the transform test command
+func (o *TransformOptions) Test() error {
+	p := &lib.TransformTestParams{
+		Dir:          o.Dir,
+		ScriptOutput: o.ErrOut,
+	}
+	res := []*startf.TestResult{}
+	if err := o.TransformMethods.Test(p, &res); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range res {
+		if r.Passed {
+			printSuccess(o.Out, "PASS %s (%s)", r.Name, r.Duration)
+			continue
+		}
+		failed++
+		printErr(o.Out, fmt.Errorf("FAIL %s (%s)", r.Name, r.Duration))
+		fmt.Fprintf(o.Out, "    %s\n", strings.Replace(strings.TrimSpace(r.Error), "\n", "\n    ", -1))
+	}
+
+	if len(res) == 0 {
+		printWarning(o.Out, "no test functions found in %s", lib.TransformTestFilename)
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transform tests failed", failed, len(res))
+	}
+	printSuccess(o.Out, "all %d transform tests passed", len(res))
+	return nil
+}
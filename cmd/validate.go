@@ -5,6 +5,7 @@ import (
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/spf13/cobra"
@@ -58,7 +59,10 @@ if these flags are provided.`,
   $ qri validate --body new_data.csv me/annual_pop
 
   # Validate data against a new schema:
-  $ qri validate --body data.csv --schema schema.json`,
+  $ qri validate --body data.csv --schema schema.json
+
+  # Check a transform reproduces the same body using recorded http responses:
+  $ qri validate --reproduce me/tf_dataset`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
@@ -76,6 +80,7 @@ if these flags are provided.`,
 	cmd.MarkFlagFilename("schema", "json")
 	cmd.Flags().StringVarP(&o.StructureFilepath, "structure", "", "", "json structure file to use for validation")
 	cmd.MarkFlagFilename("structure", "json")
+	cmd.Flags().BoolVar(&o.Reproduce, "reproduce", false, "re-run the transform offline against recorded http responses, checking it yields the same body")
 
 	return cmd
 }
@@ -89,6 +94,7 @@ type ValidateOptions struct {
 	SchemaFilepath    string
 	StructureFilepath string
 	URL               string
+	Reproduce         bool
 
 	DatasetMethods *lib.DatasetMethods
 }
@@ -117,6 +123,10 @@ func (o *ValidateOptions) Run() (err error) {
 	defer o.StopSpinner()
 
 	ref := o.Refs.Ref()
+	if o.Reproduce {
+		return o.reproduce(ref)
+	}
+
 	p := &lib.ValidateDatasetParams{
 		Ref: ref,
 		// TODO: restore
@@ -143,3 +153,25 @@ func (o *ValidateOptions) Run() (err error) {
 	}
 	return nil
 }
+
+func (o *ValidateOptions) reproduce(ref string) error {
+	if ref == "" {
+		return errors.New(lib.ErrBadArgs, "please provide a dataset name to reproduce")
+	}
+
+	p := &lib.ReproduceParams{
+		Ref:          ref,
+		ScriptOutput: o.ErrOut,
+	}
+	res := &lib.ReproduceResult{}
+	if err := o.DatasetMethods.Reproduce(p, res); err != nil {
+		return err
+	}
+
+	o.StopSpinner()
+	if !res.Reproduced {
+		return fmt.Errorf("transform did not reproduce %s\nstored body hash:     %s\nreproduced body hash: %s", res.Ref, res.BodyHash, res.ReproducedBodyHash)
+	}
+	printSuccess(o.Out, "✔ reproduced %s, body hash %s", res.Ref, res.BodyHash)
+	return nil
+}
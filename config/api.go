@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"reflect"
 	"time"
 
@@ -11,6 +12,20 @@ import (
 // DefaultAPIPort is local the port webapp serves on by default
 var DefaultAPIPort = 2503
 
+// DefaultWebsocketPort is the port the standalone event stream listener
+// serves on by default
+var DefaultWebsocketPort = 2506
+
+// DefaultSQLTimeoutMs bounds how long SQL queries over HTTP run by default
+var DefaultSQLTimeoutMs = 30000
+
+// DefaultSQLMaxRows caps the rows SQL queries over HTTP return by default
+var DefaultSQLMaxRows = 10000
+
+// DefaultMaxBodySize caps the size of request bodies the api accepts by
+// default, in bytes
+var DefaultMaxBodySize int64 = 256 << 20
+
 // API holds configuration for the qri JSON api
 type API struct {
 	Enabled bool `json:"enabled"`
@@ -44,6 +59,34 @@ type API struct {
 	AllowedOrigins []string `json:"allowedorigins"`
 	// whether to allow requests from addresses other than localhost
 	ServeRemoteTraffic bool `json:"serveremotetraffic"`
+	// WebsocketPort specifies a port for a standalone event stream listener,
+	// in addition to the /events endpoint on the main API port. 0 disables
+	// the standalone listener
+	WebsocketPort int `json:"websocketport"`
+	// RequireAuth requires requests to carry an api token or a signature
+	// from the profile keypair
+	RequireAuth bool `json:"requireauth,omitempty"`
+	// SQLTimeoutMs is the time limit for SQL queries over HTTP, in
+	// milliseconds. 0 means no limit
+	SQLTimeoutMs int `json:"sqltimeoutms,omitempty"`
+	// SQLMaxRows truncates SQL query results over HTTP after this many rows.
+	// 0 means no limit
+	SQLMaxRows int `json:"sqlmaxrows,omitempty"`
+	// SQLReadOnly allows SQL queries while the API is in read-only mode
+	SQLReadOnly bool `json:"sqlreadonly,omitempty"`
+	// RateLimit is the number of requests per second each client can make,
+	// clients are identified by api token, or by IP address. 0 means no limit
+	RateLimit float64 `json:"ratelimit,omitempty"`
+	// RateLimitBurst is the number of requests a client can make at once
+	// before RateLimit applies. Defaults to RateLimit, rounded up
+	RateLimitBurst int `json:"ratelimitburst,omitempty"`
+	// MaxBodySize is the largest request body accepted, in bytes. 0 means
+	// no limit
+	MaxBodySize int64 `json:"maxbodysize,omitempty"`
+	// TrustedProxies lists IP addresses or CIDR ranges of proxies in front of
+	// the API. Requests from these addresses are identified by the client
+	// address in X-Forwarded-For instead of the address they come from
+	TrustedProxies []string `json:"trustedproxies,omitempty"`
 }
 
 // Validate validates all fields of api returning all errors found.
@@ -83,6 +126,50 @@ func (a API) Validate() error {
         "description": "When true, requests that have X-Forwarded-Proto: http will be redirected to their https variant",
         "type": "boolean"
       },
+      "requireauth": {
+        "description": "When true, requests must be authorized with an api token or a profile keypair signature",
+        "type": "boolean"
+      },
+      "sqltimeoutms": {
+        "description": "Time limit for SQL queries over HTTP in milliseconds, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "sqlmaxrows": {
+        "description": "Maximum number of rows SQL queries over HTTP return, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "sqlreadonly": {
+        "description": "When true, SQL queries are allowed in read-only mode",
+        "type": "boolean"
+      },
+      "ratelimit": {
+        "description": "Requests per second each client can make, 0 means no limit",
+        "type": "number",
+        "minimum": 0
+      },
+      "ratelimitburst": {
+        "description": "Requests a client can make at once before the rate limit applies",
+        "type": "integer",
+        "minimum": 0
+      },
+      "maxbodysize": {
+        "description": "Largest request body accepted in bytes, 0 means no limit",
+        "type": "integer",
+        "minimum": 0
+      },
+      "trustedproxies": {
+        "description": "IP addresses or CIDR ranges of proxies whose X-Forwarded-For header is trusted",
+        "type": "array",
+        "items": {
+          "type": "string"
+        }
+      },
+      "websocketport": {
+        "description": "The port for a standalone event stream listener, 0 disables it",
+        "type": "integer"
+      },
       "allowedorigins": {
         "description": "Support CORS signing from a list of origins",
         "type": "array",
@@ -92,15 +179,41 @@ func (a API) Validate() error {
       }
     }
   }`)
+	for _, p := range a.TrustedProxies {
+		if _, err := ParseTrustedProxy(p); err != nil {
+			return err
+		}
+	}
 	return validate(schema, &a)
 }
 
+// ParseTrustedProxy parses a TrustedProxies entry, either an IP address or
+// a CIDR range
+func ParseTrustedProxy(s string) (*net.IPNet, error) {
+	if _, ipnet, err := net.ParseCIDR(s); err == nil {
+		return ipnet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid trusted proxy %q: expected an IP address or CIDR range", s)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
 // DefaultAPI returns the default configuration details
 func DefaultAPI() *API {
 	return &API{
-		Enabled: true,
-		Port:    DefaultAPIPort,
-		TLS:     false,
+		Enabled:       true,
+		Port:          DefaultAPIPort,
+		WebsocketPort: DefaultWebsocketPort,
+		SQLTimeoutMs:  DefaultSQLTimeoutMs,
+		SQLMaxRows:    DefaultSQLMaxRows,
+		MaxBodySize:   DefaultMaxBodySize,
+		TLS:           false,
 		AllowedOrigins: []string{
 			"electron://local.qri.io",
 			fmt.Sprintf("http://localhost:%d", DefaultWebappPort),
@@ -121,10 +234,22 @@ func (a *API) Copy() *API {
 		DisconnectAfter:    a.DisconnectAfter,
 		ProxyForceHTTPS:    a.ProxyForceHTTPS,
 		ServeRemoteTraffic: a.ServeRemoteTraffic,
+		WebsocketPort:      a.WebsocketPort,
+		RequireAuth:        a.RequireAuth,
+		SQLTimeoutMs:       a.SQLTimeoutMs,
+		SQLMaxRows:         a.SQLMaxRows,
+		SQLReadOnly:        a.SQLReadOnly,
+		RateLimit:          a.RateLimit,
+		RateLimitBurst:     a.RateLimitBurst,
+		MaxBodySize:        a.MaxBodySize,
 	}
 	if a.AllowedOrigins != nil {
 		res.AllowedOrigins = make([]string, len(a.AllowedOrigins))
 		reflect.Copy(reflect.ValueOf(res.AllowedOrigins), reflect.ValueOf(a.AllowedOrigins))
 	}
+	if a.TrustedProxies != nil {
+		res.TrustedProxies = make([]string, len(a.TrustedProxies))
+		reflect.Copy(reflect.ValueOf(res.TrustedProxies), reflect.ValueOf(a.TrustedProxies))
+	}
 	return res
 }
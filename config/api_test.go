@@ -24,6 +24,16 @@ func TestAPICopy(t *testing.T) {
 			TLS:                true,
 			ProxyForceHTTPS:    true,
 			ServeRemoteTraffic: true,
+			RequireAuth:        true,
+			SQLReadOnly:        true,
+		}},
+		{"rate & size limits", &API{
+			RateLimit:      2.5,
+			RateLimitBurst: 10,
+			MaxBodySize:    1024,
+		}},
+		{"trusted proxies", &API{
+			TrustedProxies: []string{"10.0.0.1", "192.168.0.0/16"},
 		}},
 	}
 	for i, c := range cases {
@@ -39,5 +49,23 @@ func TestAPICopy(t *testing.T) {
 				continue
 			}
 		}
+		if cpy.TrustedProxies != nil {
+			cpy.TrustedProxies[0] = ""
+			if reflect.DeepEqual(cpy, c.api) {
+				t.Errorf("API Copy test case %d '%s', editing one api struct should not affect the other: \ncopy: %v, \noriginal: %v", i, c.description, cpy, c.api)
+			}
+		}
+	}
+}
+
+func TestAPIValidateTrustedProxies(t *testing.T) {
+	a := DefaultAPI()
+	a.TrustedProxies = []string{"10.0.0.1", "::1", "172.16.0.0/12"}
+	if err := a.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	a.TrustedProxies = []string{"proxy.example.com"}
+	if err := a.Validate(); err == nil {
+		t.Error("expected a hostname to be an invalid trusted proxy")
 	}
 }
@@ -15,7 +15,7 @@ import (
 
 // CurrentConfigRevision is the latest configuration revision configurations
 // that don't match this revision number should be migrated up
-const CurrentConfigRevision = 1
+const CurrentConfigRevision = 3
 
 // Config encapsulates all configuration details for qri
 type Config struct {
@@ -89,7 +89,11 @@ func (cfg Config) SummaryString() (summary string) {
 	}
 
 	if cfg.RPC != nil && cfg.RPC.Enabled {
-		summary += fmt.Sprintf("RPC port:\t%d\n", cfg.RPC.Port)
+		socket := cfg.RPC.Socket
+		if socket == "" {
+			socket = DefaultRPCSocket
+		}
+		summary += fmt.Sprintf("RPC socket:\t%s\n", socket)
 	}
 
 	return summary
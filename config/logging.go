@@ -6,6 +6,9 @@ import "github.com/qri-io/jsonschema"
 type Logging struct {
 	// Levels is a map of package_name : log_level (one of [info, error, debug, warn])
 	Levels map[string]string `json:"levels"`
+	// Format is the output format of log lines, one of [text, json]. Defaults
+	// to text
+	Format string `json:"format,omitempty"`
 }
 
 // DefaultLogging produces a new default logging configuration
@@ -41,6 +44,15 @@ func (l Logging) Validate() error {
             ]
           }
         }
+      },
+      "format": {
+        "description": "Output format of log lines",
+        "type": "string",
+        "enum": [
+          "",
+          "text",
+          "json"
+        ]
       }
     }
   }`)
@@ -49,7 +61,7 @@ func (l Logging) Validate() error {
 
 // Copy returns a deep copy of a Logging struct
 func (l *Logging) Copy() *Logging {
-	res := &Logging{}
+	res := &Logging{Format: l.Format}
 	if l.Levels != nil {
 		res.Levels = map[string]string{}
 		for key, value := range l.Levels {
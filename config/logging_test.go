@@ -10,6 +10,16 @@ func TestLoggingValidate(t *testing.T) {
 	if err != nil {
 		t.Errorf("error validating default logging: %s", err)
 	}
+
+	l := DefaultLogging()
+	l.Format = "json"
+	if err := l.Validate(); err != nil {
+		t.Errorf("error validating json logging: %s", err)
+	}
+	l.Format = "xml"
+	if err := l.Validate(); err == nil {
+		t.Error("expected an unknown log format to be invalid")
+	}
 }
 
 func TestLoggingCopy(t *testing.T) {
@@ -17,6 +27,7 @@ func TestLoggingCopy(t *testing.T) {
 		logging *Logging
 	}{
 		{DefaultLogging()},
+		{&Logging{Levels: map[string]string{"qriapi": "debug"}, Format: "json"}},
 	}
 	for i, c := range cases {
 		cpy := c.logging.Copy()
@@ -10,8 +10,20 @@ import (
 func RunMigrations(streams ioes.IOStreams, cfg *config.Config) (migrated bool, err error) {
 	if cfg.Revision != config.CurrentConfigRevision {
 		streams.PrintErr("migrating configuration...")
-		if err := ZeroToOne(cfg); err != nil {
-			return false, err
+		if cfg.Revision < 1 {
+			if err := ZeroToOne(cfg); err != nil {
+				return false, err
+			}
+		}
+		if cfg.Revision < 2 {
+			if err := OneToTwo(cfg); err != nil {
+				return false, err
+			}
+		}
+		if cfg.Revision < 3 {
+			if err := TwoToThree(cfg); err != nil {
+				return false, err
+			}
 		}
 		streams.PrintErr("done!\n")
 		return true, nil
@@ -52,6 +64,29 @@ func ZeroToOne(cfg *config.Config) error {
 	return nil
 }
 
+// OneToTwo migrates a configuration from Revision 1 to Revision 2, adding
+// the standalone event stream port that was previously hardcoded
+func OneToTwo(cfg *config.Config) error {
+	if cfg.API != nil && cfg.API.WebsocketPort == 0 {
+		cfg.API.WebsocketPort = config.DefaultWebsocketPort
+	}
+
+	cfg.Revision = 2
+	return nil
+}
+
+// TwoToThree migrates a configuration from Revision 2 to Revision 3. RPC
+// moved from a TCP port to a unix socket in the repo directory, dropping
+// rpc.port
+func TwoToThree(cfg *config.Config) error {
+	if cfg.RPC != nil {
+		cfg.RPC.Port = 0
+	}
+
+	cfg.Revision = 3
+	return nil
+}
+
 func delIdx(i int, sl []string) []string {
 	if i < len(sl)-1 {
 		return append(sl[:i], sl[i+1:]...)
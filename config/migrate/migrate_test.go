@@ -0,0 +1,52 @@
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/config"
+)
+
+func TestTwoToThree(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config_migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("revision: 2\nprofile:\n  peername: migrating\nrpc:\n  enabled: true\n  port: 2504\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.ReadFromFile(path)
+	if err != nil {
+		t.Fatalf("reading a revision 2 config: %s", err)
+	}
+
+	streams, _, _, _ := ioes.NewTestIOStreams()
+	migrated, err := RunMigrations(streams, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !migrated || cfg.Revision != config.CurrentConfigRevision {
+		t.Fatalf("expected config to migrate to revision %d, got %d", config.CurrentConfigRevision, cfg.Revision)
+	}
+	if !cfg.RPC.Enabled {
+		t.Errorf("expected rpc to stay enabled")
+	}
+
+	if err := cfg.WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "port: 2504") {
+		t.Errorf("expected migrated config to drop rpc.port, got:\n%s", data)
+	}
+}
@@ -11,6 +11,9 @@ type Repo struct {
 	Middleware []string `json:"middleware"`
 	Type       string   `json:"type"`
 	Path       string   `json:"path,omitempty"`
+	// EventJournalSize is the number of recent events to keep in a journal in
+	// the repo directory. 0 disables the journal
+	EventJournalSize int `json:"eventjournalsize,omitempty"`
 }
 
 // DefaultRepo creates & returns a new default repo configuration
@@ -37,6 +40,11 @@ func (cfg Repo) Validate() error {
           "type": "string"
         }
       },
+      "eventjournalsize": {
+        "description": "Number of recent events to keep in the event journal, 0 disables the journal",
+        "type": "integer",
+        "minimum": 0
+      },
       "type": {
         "description": "Type of repository",
         "type": "string",
@@ -53,7 +61,8 @@ func (cfg Repo) Validate() error {
 // Copy returns a deep copy of the Repo struct
 func (cfg *Repo) Copy() *Repo {
 	res := &Repo{
-		Type: cfg.Type,
+		Type:             cfg.Type,
+		EventJournalSize: cfg.EventJournalSize,
 	}
 	if cfg.Middleware != nil {
 		res.Middleware = make([]string, len(cfg.Middleware))
@@ -2,20 +2,26 @@ package config
 
 import "github.com/qri-io/jsonschema"
 
-// RPC configures a Remote Procedure Call (RPC) listener
+// RPC configures the Remote Procedure Call (RPC) listener commands use to
+// talk to a running qri daemon
 type RPC struct {
 	Enabled bool `json:"enabled"`
-	Port    int  `json:"port"`
+	// Socket is the path of the unix domain socket the daemon listens on.
+	// Relative paths are relative to the repo directory. Defaults to
+	// DefaultRPCSocket in the repo directory
+	Socket string `json:"socket,omitempty"`
+	// Port is the TCP port RPC used to listen on. Deprecated: it's only read so
+	// configurations from before revision 3 load, migration clears it
+	Port int `json:"port,omitempty"`
 }
 
-// DefaultRPCPort is local the port RPC serves on by default
-var DefaultRPCPort = 2504
+// DefaultRPCSocket is the filename of the RPC socket within a repo directory
+const DefaultRPCSocket = "qri.sock"
 
 // DefaultRPC creates a new default RPC configuration
 func DefaultRPC() *RPC {
 	return &RPC{
 		Enabled: true,
-		Port:    DefaultRPCPort,
 	}
 }
 
@@ -27,15 +33,15 @@ func (cfg RPC) Validate() error {
     "title": "RPC",
     "description": "The RPC configuration",
     "type": "object",
-    "required": ["enabled", "port"],
+    "required": ["enabled"],
     "properties": {
       "enabled": {
         "description": "When true, communcation over rpc is allowed",
         "type": "boolean"
       },
-      "port": {
-        "description": "The port on which to listen for rpc calls",
-        "type": "integer"
+      "socket": {
+        "description": "Path of the unix domain socket to listen for rpc calls on",
+        "type": "string"
       }
     }
   }`)
@@ -46,6 +52,7 @@ func (cfg RPC) Validate() error {
 func (cfg *RPC) Copy() *RPC {
 	res := &RPC{
 		Enabled: cfg.Enabled,
+		Socket:  cfg.Socket,
 		Port:    cfg.Port,
 	}
 
@@ -1,5 +1,7 @@
 package errors
 
+import "errors"
+
 // Error wraps an error and satisfies the error interface
 // It couples more developer focused errors with more
 // user-friendly errors. If a msg exists, you can send an
@@ -31,3 +33,9 @@ func (e Error) Unwrap() error {
 func (e Error) Message() string {
 	return e.msg
 }
+
+// Is reports whether any error in err's chain matches target. It calls the
+// standard library errors.Is, for packages that import this one as errors
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
@@ -16,3 +16,14 @@ func TestError(t *testing.T) {
 		t.Errorf("error in Error struct function `Error()`: expected: %s, got: %s", "testing error", e.Error())
 	}
 }
+
+func TestIs(t *testing.T) {
+	target := fmt.Errorf("target")
+	e := New(fmt.Errorf("wrapping: %w", target), "testing message")
+	if !Is(e, target) {
+		t.Error("expected Is to find a wrapped error")
+	}
+	if Is(e, fmt.Errorf("target")) {
+		t.Error("expected Is not to match an error by its message")
+	}
+}
@@ -0,0 +1,14 @@
+package event
+
+import "github.com/qri-io/qri/config"
+
+var (
+	// ETConfigChanged type for when the configuration is updated
+	ETConfigChanged = Topic("config:changed")
+)
+
+// ConfigChangedEvent describes an updated configuration
+type ConfigChangedEvent struct {
+	// the new configuration, without private values
+	Config *config.Config
+}
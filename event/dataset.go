@@ -0,0 +1,63 @@
+package event
+
+import "github.com/qri-io/qri/dsref"
+
+var (
+	// ETDatasetSaved type for when a new version of a dataset is saved.
+	// payload is a DatasetSavedEvent
+	ETDatasetSaved = Topic("dataset:saved")
+	// ETDatasetRenamed type for when a dataset is given a new name.
+	// payload is a DatasetRenamedEvent
+	ETDatasetRenamed = Topic("dataset:renamed")
+	// ETDatasetRemoved type for when versions of a dataset are removed.
+	// payload is a DatasetRemovedEvent
+	ETDatasetRemoved = Topic("dataset:removed")
+	// ETDatasetPublished type for when a dataset is published, either to a
+	// remote or by marking it public locally. payload is a DatasetPublicationEvent
+	ETDatasetPublished = Topic("dataset:published")
+	// ETDatasetUnpublished type for when a dataset is unpublished, either from
+	// a remote or by marking it private locally. payload is a
+	// DatasetPublicationEvent
+	ETDatasetUnpublished = Topic("dataset:unpublished")
+	// ETDatasetPulled type for when a dataset is fetched from another peer or
+	// remote. payload is a DatasetPulledEvent
+	ETDatasetPulled = Topic("dataset:pulled")
+)
+
+// DatasetSavedEvent describes a newly saved dataset version
+type DatasetSavedEvent struct {
+	Ref dsref.Ref
+	// working directory the dataset is linked to, if any
+	FSIPath string
+}
+
+// DatasetRenamedEvent describes a dataset name change
+type DatasetRenamedEvent struct {
+	Previous dsref.Ref
+	Ref      dsref.Ref
+}
+
+// DatasetRemovedEvent describes removing versions of a dataset
+type DatasetRemovedEvent struct {
+	Ref dsref.Ref
+	// number of versions removed, dsref.AllGenerations if the dataset was
+	// removed entirely
+	NumDeleted int
+	// true if removing the dataset unlinked a working directory
+	Unlinked bool
+}
+
+// DatasetPublicationEvent describes a change to a dataset's publicity
+type DatasetPublicationEvent struct {
+	Ref dsref.Ref
+	// name of the remote, empty when publicity is only changed locally
+	Remote string
+}
+
+// DatasetPulledEvent describes a dataset fetched from the network
+type DatasetPulledEvent struct {
+	Ref dsref.Ref
+	// remote or address the dataset was pulled from, empty if it came from
+	// the p2p network
+	Remote string
+}
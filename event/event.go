@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/logging"
 )
 
 var log = golog.Logger("event")
@@ -21,6 +22,12 @@ type Topic string
 type Event struct {
 	Topic
 	Payload interface{}
+	// sequence number of the event in the bus journal, 0 if the bus doesn't
+	// keep a journal
+	Seq uint64
+	// RequestID is the ID of the request that caused the event, if it was
+	// published with one
+	RequestID string
 }
 
 // Publisher is an interface that can only publish an event
@@ -43,6 +50,9 @@ func (n *NilPublisher) Publish(t Topic, data interface{}) {
 type Bus interface {
 	// Publish an event to the bus
 	Publish(t Topic, data interface{})
+	// PublishContext publishes an event tagged with the request ID ctx
+	// carries
+	PublishContext(ctx context.Context, t Topic, data interface{})
 	// Subscribe to one or more topics
 	Subscribe(topics ...Topic) <-chan Event
 	// Unsubscribe cleans up a channel that no longer need to receive events
@@ -54,9 +64,19 @@ type Bus interface {
 	SubscribeOnce(types ...Topic) <-chan Event
 	// NumSubscriptions returns the number of subscribers to the bus's events
 	NumSubscribers() int
+	// Journal returns the journal published events are recorded in, nil if
+	// the bus doesn't keep a journal
+	Journal() *Journal
 }
 
-type dataChannels []chan Event
+// subscription is a channel events are delivered to. done is closed when the
+// channel unsubscribes, abandoning any deliveries still waiting on a reader
+type subscription struct {
+	ch   chan Event
+	done chan struct{}
+}
+
+type dataChannels []*subscription
 
 type bus struct {
 	ctx context.Context
@@ -66,6 +86,8 @@ type bus struct {
 
 	onceLk sync.RWMutex
 	onces  []onceSub
+
+	journal *Journal
 }
 
 type onceSub struct {
@@ -95,21 +117,55 @@ func NewBus(ctx context.Context) Bus {
 	return b
 }
 
+// NewBusWithJournal creates a new event bus that records every published
+// event in a journal before delivering it, setting the sequence number of
+// delivered events
+func NewBusWithJournal(ctx context.Context, j *Journal) Bus {
+	b := NewBus(ctx).(*bus)
+	b.journal = j
+	return b
+}
+
+// Journal returns the bus journal, if any
+func (b *bus) Journal() *Journal {
+	return b.journal
+}
+
 // Publish sends an event to the bus
 func (b *bus) Publish(topic Topic, data interface{}) {
+	b.publish(Event{Payload: data, Topic: topic})
+}
+
+// PublishContext sends an event to the bus, tagged with the request ID ctx
+// carries
+func (b *bus) PublishContext(ctx context.Context, topic Topic, data interface{}) {
+	b.publish(Event{Payload: data, Topic: topic, RequestID: logging.RequestID(ctx)})
+}
+
+func (b *bus) publish(event Event) {
 	b.lk.RLock()
 	defer b.lk.RUnlock()
+	topic := event.Topic
 	log.Debugf("Publish: %s", topic)
 
-	event := Event{Payload: data, Topic: topic}
+	if b.journal != nil {
+		entry, err := b.journal.Append(topic, event.Payload)
+		if err != nil {
+			log.Errorf("journaling event %s: %s", topic, err)
+		}
+		event.Seq = entry.Seq
+	}
 
 	if chans, ok := b.subs[topic]; ok {
 		// slices in this map refer to same array even though they are passed by value
 		// creating a new slice preserves locking correctly
 		channels := append(dataChannels{}, chans...)
 		go func(e Event, dataChannelSlices dataChannels) {
-			for _, ch := range dataChannelSlices {
-				ch <- e
+			for _, sub := range dataChannelSlices {
+				select {
+				case sub.ch <- e:
+				case <-sub.done:
+				}
 			}
 		}(event, channels)
 	}
@@ -138,33 +194,39 @@ func (b *bus) Subscribe(topics ...Topic) <-chan Event {
 	defer b.lk.Unlock()
 	log.Debugf("Subscribe: %v", topics)
 
-	ch := make(chan Event)
+	sub := &subscription{ch: make(chan Event), done: make(chan struct{})}
 
 	for _, topic := range topics {
 		if prev, ok := b.subs[topic]; ok {
-			b.subs[topic] = append(prev, ch)
+			b.subs[topic] = append(prev, sub)
 		} else {
-			b.subs[topic] = dataChannels{ch}
+			b.subs[topic] = dataChannels{sub}
 		}
 	}
 
-	return ch
+	return sub.ch
 }
 
-// Unsubscribe cleans up a channel that no longer need to receive events
+// Unsubscribe cleans up a channel that no longer need to receive events. Once
+// Unsubscribe returns the bus stops delivering to the channel, including
+// events published before the call that haven't been read yet
 func (b *bus) Unsubscribe(unsub <-chan Event) {
 	b.lk.Lock()
 	defer b.lk.Unlock()
+	var found *subscription
 	for topic, channels := range b.subs {
-		var replace dataChannels
-		for i, ch := range channels {
-			if ch == unsub {
-				replace = append(channels[:i], channels[i+1:]...)
+		replace := make(dataChannels, 0, len(channels))
+		for _, sub := range channels {
+			if sub.ch == unsub {
+				found = sub
+				continue
 			}
+			replace = append(replace, sub)
 		}
-		if replace != nil {
-			b.subs[topic] = replace
-		}
+		b.subs[topic] = replace
+	}
+	if found != nil {
+		close(found.done)
 	}
 }
 
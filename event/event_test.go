@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func Example() {
@@ -77,3 +78,25 @@ func TestSubscribeUnsubscribe(t *testing.T) {
 		t.Errorf("expected 1 subscribers, got %d", b.NumSubscribers())
 	}
 }
+
+func TestUnsubscribeAbandonsDelivery(t *testing.T) {
+	ctx := context.Background()
+	const testTopic = Topic("test_event")
+
+	b := NewBus(ctx)
+	unread := b.Subscribe(testTopic)
+	ch := b.Subscribe(testTopic)
+
+	// delivery blocks on the unread channel until it unsubscribes
+	b.Publish(testTopic, "hello")
+	b.Unsubscribe(unread)
+
+	select {
+	case e := <-ch:
+		if e.Payload != "hello" {
+			t.Errorf("expected payload %q, got: %v", "hello", e.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected unsubscribing to unblock delivery to other subscribers")
+	}
+}
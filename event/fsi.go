@@ -1,5 +1,7 @@
 package event
 
+import "github.com/qri-io/qri/dsref"
+
 var (
 	// ETFSICreateLinkEvent type for when FSI creates a link between a dataset and working directory
 	ETFSICreateLinkEvent = Topic("fsi:createLinkEvent")
@@ -11,3 +13,17 @@ type FSICreateLinkEvent struct {
 	Username string
 	Dsname   string
 }
+
+var (
+	// ETFSICheckoutEvent type for when a dataset is checked out to a working
+	// directory. payload is a FSICheckoutEvent
+	ETFSICheckoutEvent = Topic("fsi:checkoutEvent")
+)
+
+// FSICheckoutEvent describes a dataset written to a working directory
+type FSICheckoutEvent struct {
+	Ref     dsref.Ref
+	FSIPath string
+	// components written to the directory, empty if all were written
+	Components []string
+}
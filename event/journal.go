@@ -0,0 +1,193 @@
+package event
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JournalFilename is the name of the event journal file within a repo
+// directory
+const JournalFilename = "events.jsonl"
+
+// JournalEntry is an event recorded in a Journal
+type JournalEntry struct {
+	Seq       uint64          `json:"seq"`
+	Topic     Topic           `json:"topic"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Journal is an append-only log of published events, persisted as lines of
+// JSON. Every entry gets a sequence number one greater than the last,
+// including across restarts. Only the most recent entries are kept, older
+// entries are dropped as the journal grows. Journal is safe for concurrent use
+type Journal struct {
+	path       string
+	maxEntries int
+
+	lk        sync.Mutex
+	f         *os.File
+	entries   []JournalEntry
+	seq       uint64
+	fileLines int
+}
+
+// NewJournal opens or creates a journal file at path that keeps at most
+// maxEntries events
+func NewJournal(path string, maxEntries int) (*Journal, error) {
+	if maxEntries <= 0 {
+		return nil, fmt.Errorf("journal size must be greater than 0")
+	}
+	j := &Journal{path: path, maxEntries: maxEntries}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j.f = f
+	return j, nil
+}
+
+// load reads existing entries from the journal file
+func (j *Journal) load() error {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		j.fileLines++
+		e := JournalEntry{}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			// a partially-written last line is skipped
+			log.Debugf("skipping invalid journal entry: %s", err)
+			continue
+		}
+		j.push(e)
+	}
+	return sc.Err()
+}
+
+// push adds an entry to the in-memory tail of the journal
+func (j *Journal) push(e JournalEntry) {
+	j.entries = append(j.entries, e)
+	if len(j.entries) > j.maxEntries {
+		j.entries = append([]JournalEntry{}, j.entries[len(j.entries)-j.maxEntries:]...)
+	}
+	if e.Seq > j.seq {
+		j.seq = e.Seq
+	}
+}
+
+// Append records an event, returning the created entry
+func (j *Journal) Append(t Topic, payload interface{}) (JournalEntry, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	e := JournalEntry{
+		Seq:       j.seq + 1,
+		Topic:     t,
+		Timestamp: time.Now(),
+		Payload:   data,
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return JournalEntry{}, err
+	}
+	j.fileLines++
+	j.push(e)
+
+	// rewrite the file once it holds twice as many entries as are kept
+	if j.fileLines >= 2*j.maxEntries {
+		if err := j.compact(); err != nil {
+			log.Errorf("compacting event journal: %s", err)
+		}
+	}
+	return e, nil
+}
+
+// compact replaces the journal file with the entries that are kept
+func (j *Journal) compact() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(j.path), ".events-")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	for _, e := range j.entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	j.f.Close()
+	j.f = f
+	j.fileLines = len(j.entries)
+	return nil
+}
+
+// Since returns journaled events with a sequence number greater than seq,
+// oldest first. Events that have been dropped from the journal can't be
+// returned. Compare the first returned sequence number with seq+1 to detect
+// missed events
+func (j *Journal) Since(seq uint64) []JournalEntry {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	for i, e := range j.entries {
+		if e.Seq > seq {
+			return append([]JournalEntry{}, j.entries[i:]...)
+		}
+	}
+	return nil
+}
+
+// LastSeq returns the sequence number of the most recent event
+func (j *Journal) LastSeq() uint64 {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	return j.seq
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.lk.Lock()
+	defer j.lk.Unlock()
+	return j.f.Close()
+}
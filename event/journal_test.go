@@ -0,0 +1,113 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_event_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, JournalFilename)
+
+	j, err := NewJournal(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := j.Append(Topic("test:event"), map[string]int{"i": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := j.LastSeq(); got != 4 {
+		t.Errorf("expected last seq 4, got: %d", got)
+	}
+	got := j.Since(0)
+	if len(got) != 3 || got[0].Seq != 2 {
+		t.Errorf("expected the journal to keep the 3 most recent entries, got: %v", got)
+	}
+	if got := j.Since(3); len(got) != 1 || string(got[0].Payload) != `{"i":3}` {
+		t.Errorf("unexpected entries since 3: %v", got)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// reopening continues the sequence
+	j, err = NewJournal(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if got := j.LastSeq(); got != 4 {
+		t.Errorf("expected reopened last seq 4, got: %d", got)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := j.Append(Topic("test:event"), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := j.Since(0); len(got) != 3 || got[0].Seq != 5 {
+		t.Errorf("unexpected entries after reopening: %v", got)
+	}
+
+	// the file is compacted as it grows
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		lines++
+	}
+	if lines >= 6 {
+		t.Errorf("expected journal file to be compacted, got %d lines", lines)
+	}
+}
+
+func TestBusWithJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_event_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	j, err := NewJournal(filepath.Join(dir, JournalFilename), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := NewBusWithJournal(ctx, j)
+	if bus.Journal() != j {
+		t.Fatal("expected bus to return its journal")
+	}
+
+	// events published without subscribers are still journaled
+	bus.Publish(Topic("test:first"), "one")
+	ch := bus.Subscribe(Topic("test:second"))
+	bus.Publish(Topic("test:second"), "two")
+
+	select {
+	case e := <-ch:
+		if e.Seq != 2 {
+			t.Errorf("expected delivered event to have seq 2, got: %d", e.Seq)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	if got := j.Since(0); len(got) != 2 || got[0].Topic != Topic("test:first") {
+		t.Errorf("unexpected journal entries: %v", got)
+	}
+}
@@ -13,23 +13,47 @@ import (
 
 // GetBody is an FSI version of base.ReadBody
 func GetBody(dirPath string, format dataset.DataFormat, fcfg dataset.FormatConfig, offset, limit int, all bool) ([]byte, error) {
+	file, structure, err := OpenBody(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	st := &dataset.Structure{}
+	assign := &dataset.Structure{
+		Format: format.String(),
+		Schema: structure.Schema,
+	}
+	if fcfg != nil {
+		assign.FormatConfig = fcfg.Map()
+	}
+	st.Assign(structure, assign)
 
+	return base.ConvertBodyFile(file, structure, st, limit, offset, all)
+}
+
+// OpenBody opens the body file of a working directory, returning the file
+// and the structure needed to read it. If the directory has no schema, one is
+// detected from the body. Callers must close the returned file
+func OpenBody(dirPath string) (qfs.File, *dataset.Structure, error) {
 	components, err := component.ListDirectoryComponents(dirPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	err = component.ExpandListedComponents(components, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	bodyComponent := components.Base().GetSubcomponent("body")
+	if bodyComponent == nil {
+		return nil, nil, fmt.Errorf("no body file to read")
+	}
 	f, err := os.Open(bodyComponent.Base().SourceFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer f.Close()
 
 	var structure *dataset.Structure
 
@@ -39,7 +63,8 @@ func GetBody(dirPath string, format dataset.DataFormat, fcfg dataset.FormatConfi
 		stComponent.LoadAndFill(nil)
 		comp, ok := stComponent.(*component.StructureComponent)
 		if !ok {
-			return nil, fmt.Errorf("could not get structure")
+			f.Close()
+			return nil, nil, fmt.Errorf("could not get structure")
 		}
 		structure = comp.Value
 		schema = structure.Schema
@@ -56,28 +81,33 @@ func GetBody(dirPath string, format dataset.DataFormat, fcfg dataset.FormatConfi
 		// TODO(dlong): This should move into `dsio` package.
 		entries, err := component.OpenEntryReader(f, bodyFormat)
 		if err != nil {
-			return nil, err
+			f.Close()
+			return nil, nil, err
 		}
-		schema = entries.Structure().Schema
+		// Read the body using the detected schema
+		structure.Schema = entries.Structure().Schema
 		// Reset the reader
 		f.Seek(0, 0)
 	}
 
-	file := qfs.NewMemfileReader(filepath.Base(bodyComponent.Base().SourceFile), f)
+	return qfs.NewMemfileReader(filepath.Base(bodyComponent.Base().SourceFile), f), structure, nil
+}
 
-	st := &dataset.Structure{}
-	assign := &dataset.Structure{
-		Format: format.String(),
-		Schema: schema,
+// BodyFileVersion identifies the body file of a working directory by its
+// name, size & modification time, which change whenever the file is edited.
+// Directories without a body file return ""
+func BodyFileVersion(dirPath string) (string, error) {
+	components, err := component.ListDirectoryComponents(dirPath)
+	if err != nil {
+		return "", err
 	}
-	if fcfg != nil {
-		assign.FormatConfig = fcfg.Map()
+	bodyComponent := components.Base().GetSubcomponent("body")
+	if bodyComponent == nil {
+		return "", nil
 	}
-	st.Assign(structure, assign)
-	// If there is no schema on the dataset, but one was detected, assign it as well.
-	if structure.Schema == nil && assign.Schema != nil {
-		structure.Schema = assign.Schema
+	fi, err := os.Stat(bodyComponent.Base().SourceFile)
+	if err != nil {
+		return "", err
 	}
-
-	return base.ConvertBodyFile(file, structure, st, limit, offset, all)
+	return fmt.Sprintf("%s@%d.%d", fi.Name(), fi.Size(), fi.ModTime().UnixNano()), nil
 }
@@ -0,0 +1,39 @@
+package fsi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBodyFileVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fsi_body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if v, err := BodyFileVersion(dir); err != nil || v != "" {
+		t.Fatalf("expected no version without a body file, got: %q, %v", v, err)
+	}
+
+	bodyPath := filepath.Join(dir, "body.csv")
+	if err := ioutil.WriteFile(bodyPath, []byte("a,b\n1,2\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	before, err := BodyFileVersion(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(bodyPath, []byte("a,b\n1,2\n3,4\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	after, err := BodyFileVersion(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == "" || before == after {
+		t.Errorf("expected editing the body file to change its version, got %q then %q", before, after)
+	}
+}
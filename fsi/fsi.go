@@ -59,11 +59,21 @@ func RepoPath(repoPath string) string {
 	return filepath.Join(repoPath, "fsi.qfb")
 }
 
+// StatusCachePath returns the standard path to the status cache directory for
+// a given file-system repo location
+func StatusCachePath(repoPath string) string {
+	return filepath.Join(repoPath, "fsi_status")
+}
+
 // FSI is a repo-side struct for coordinating file system integration
 type FSI struct {
 	// repository for resolving dataset names
 	repo repo.Repo
 	pub  event.Publisher
+	// cache of working directory statuses
+	statusCache *StatusCache
+	// uncommitted changes set aside from working directories
+	stash *StashStore
 }
 
 // NewFSI creates an FSI instance from a path to a links flatbuffer file
@@ -71,7 +81,12 @@ func NewFSI(r repo.Repo, pub event.Publisher) *FSI {
 	if pub == nil {
 		pub = &event.NilPublisher{}
 	}
-	return &FSI{repo: r, pub: pub}
+	return &FSI{repo: r, pub: pub, statusCache: NewStatusCache(""), stash: NewStashStore("")}
+}
+
+// SetStatusCache replaces the cache Status uses to skip unchanged components
+func (fsi *FSI) SetStatusCache(c *StatusCache) {
+	fsi.statusCache = c
 }
 
 // LinkedRefs returns a list of linked datasets and their connected directories
@@ -173,7 +188,6 @@ func (fsi *FSI) CreateLink(dirPath, refStr string) (alias string, rollback func(
 // ModifyLinkDirectory changes the FSIPath in the repo so that it is linked to the directory. Does
 // not affect the .qri-ref linkfile in the working directory. Called when the command-line
 // interface or filesystem watcher detects that a working folder has been moved.
-// TODO(dlong): Add a filesystem watcher that behaves as described
 // TODO(dlong): Perhaps add a `qri mv` command that explicitly changes a working directory location
 func (fsi *FSI) ModifyLinkDirectory(dirPath, refStr string) error {
 	ref, err := repo.ParseDatasetRef(refStr)
@@ -213,9 +227,13 @@ func (fsi *FSI) ModifyLinkReference(dirPath, refStr string) error {
 // Unlink removes the link file (.qri-ref) in the directory, and removes the fsi path
 // from the reference in the refstore
 func (fsi *FSI) Unlink(dirPath string, ref dsref.Ref) error {
+	fsi.statusCache.Drop(dirPath)
 	if removeLinkErr := removeLinkFile(dirPath); removeLinkErr != nil {
 		log.Debugf("removing link file: %s", removeLinkErr.Error())
 	}
+	if err := WriteLinkMeta(dirPath, nil); err != nil {
+		log.Debugf("removing link metadata: %s", err.Error())
+	}
 
 	// Ref may be empty, which will mean only the link file should be removed
 	if ref.IsEmpty() {
@@ -0,0 +1,426 @@
+package fsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/component"
+	"github.com/qri-io/qri/base/dsfs"
+)
+
+// LinkMetaFilename is the name of the file that records how a dataset was
+// checked out into a linked directory. Directories that hold a complete copy
+// of a dataset don't have one
+const LinkMetaFilename = ".qri-link.json"
+
+// LinkMeta describes a linked directory that doesn't hold an exact copy of
+// its dataset. Components that weren't checked out are placeholders: they're
+// intentionally absent, and the stored version stands in for them on save.
+// The body can also be written in a working format that's converted back to
+// the stored format on save
+type LinkMeta struct {
+	// names of components written to the directory, empty means all
+	Components []string `json:"components,omitempty"`
+	// maximum number of body entries written to the directory, 0 means the
+	// whole body was written
+	BodyLimit int `json:"bodyLimit,omitempty"`
+	// format the body & structure are written in, when it differs from the
+	// stored format
+	BodyFormat string `json:"bodyFormat,omitempty"`
+	// stored body format & format configuration, recorded with BodyFormat
+	StoredFormat       string                 `json:"storedFormat,omitempty"`
+	StoredFormatConfig map[string]interface{} `json:"storedFormatConfig,omitempty"`
+	// hex-encoded sha256 sums of component files that don't match the stored
+	// version byte-for-byte, keyed by component name: a body slice, or a body
+	// & structure written in BodyFormat
+	Hashes map[string]string `json:"hashes,omitempty"`
+}
+
+// ReadLinkMeta reads the link metadata of a directory. Directories without
+// link metadata return an empty LinkMeta
+func ReadLinkMeta(dir string) (*LinkMeta, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, LinkMetaFilename))
+	if os.IsNotExist(err) {
+		return &LinkMeta{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	lm := &LinkMeta{}
+	if err := json.Unmarshal(data, lm); err != nil {
+		return nil, fmt.Errorf("reading %s: %s", LinkMetaFilename, err)
+	}
+	return lm, nil
+}
+
+// WriteLinkMeta records link metadata in a directory, removing the metadata
+// file if the directory holds a complete copy of the dataset
+func WriteLinkMeta(dir string, lm *LinkMeta) error {
+	path := filepath.Join(dir, LinkMetaFilename)
+	if lm == nil || lm.IsEmpty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(lm, "", "  ")
+	if err != nil {
+		return err
+	}
+	return base.WriteHiddenFile(path, string(data))
+}
+
+// IsEmpty returns true if the directory holds an exact copy of the dataset
+func (lm *LinkMeta) IsEmpty() bool {
+	return !lm.IsPartial() && lm.BodyFormat == ""
+}
+
+// IsPartial returns true if the directory is missing any part of the dataset
+func (lm *LinkMeta) IsPartial() bool {
+	return len(lm.Components) > 0 || lm.BodyLimit > 0
+}
+
+// CheckedOut reports whether a component was written to the directory
+func (lm *LinkMeta) CheckedOut(compName string) bool {
+	if len(lm.Components) == 0 {
+		return true
+	}
+	for _, name := range lm.Components {
+		if name == compName {
+			return true
+		}
+	}
+	return false
+}
+
+// Placeholder reports whether the stored version of a component stands in for
+// the file in the directory
+func (lm *LinkMeta) Placeholder(compName string) bool {
+	return !lm.CheckedOut(compName) || (compName == "body" && lm.BodyLimit > 0)
+}
+
+// CheckOut records components as written to the directory exactly as they're
+// stored. Passing no component names records an exact copy of the dataset.
+// Checking out the body or structure returns both to the stored format
+func (lm *LinkMeta) CheckOut(compNames ...string) {
+	if len(compNames) == 0 {
+		*lm = LinkMeta{}
+		return
+	}
+	for _, name := range compNames {
+		if name == "body" {
+			lm.BodyLimit = 0
+			delete(lm.Hashes, "body")
+		}
+		if (name == "body" || name == "structure") && lm.BodyFormat != "" {
+			lm.BodyFormat = ""
+			lm.StoredFormat = ""
+			lm.StoredFormatConfig = nil
+			delete(lm.Hashes, "body")
+			delete(lm.Hashes, "structure")
+		}
+		if len(lm.Components) > 0 && !lm.CheckedOut(name) {
+			lm.Components = append(lm.Components, name)
+		}
+	}
+}
+
+// convertsFile reports whether the file of a component is written
+// differently from the stored version
+func (lm *LinkMeta) convertsFile(compName string) bool {
+	switch compName {
+	case "body":
+		return lm.BodyLimit > 0 || lm.BodyFormat != ""
+	case "structure":
+		return lm.BodyFormat != ""
+	}
+	return false
+}
+
+// setHash records the hash of a component file
+func (lm *LinkMeta) setHash(compName, path string) error {
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	if lm.Hashes == nil {
+		lm.Hashes = map[string]string{}
+	}
+	lm.Hashes[compName] = hash
+	return nil
+}
+
+// unchanged reports whether a component file still matches the hash recorded
+// when it was written
+func (lm *LinkMeta) unchanged(compName, path string) (bool, error) {
+	expect, ok := lm.Hashes[compName]
+	if !ok {
+		return false, nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return false, err
+	}
+	return hash == expect, nil
+}
+
+// workingStructure returns a copy of a stored structure in the working format
+func (lm *LinkMeta) workingStructure(st *dataset.Structure) *dataset.Structure {
+	working := &dataset.Structure{}
+	working.Assign(st)
+	working.Format = lm.BodyFormat
+	working.FormatConfig = nil
+	return working
+}
+
+// storedStructure returns a copy of a working structure in the stored format
+func (lm *LinkMeta) storedStructure(st *dataset.Structure) *dataset.Structure {
+	stored := &dataset.Structure{}
+	stored.Assign(st)
+	stored.Format = lm.StoredFormat
+	stored.FormatConfig = lm.StoredFormatConfig
+	return stored
+}
+
+// ValidateCheckoutComponents returns an error if any name isn't a component
+// that can be checked out
+func ValidateCheckoutComponents(compNames []string) error {
+	for _, name := range compNames {
+		known := false
+		for _, compName := range component.AllSubcomponentNames() {
+			if name == compName {
+				known = true
+				break
+			}
+		}
+		if !known || name == "commit" {
+			return fmt.Errorf("unknown component %q", name)
+		}
+	}
+	return nil
+}
+
+// WriteCheckout writes the components of a dataset selected by link metadata
+// to a directory, and records the metadata alongside the link. Bodies are cut
+// to the first lm.BodyLimit entries, and converted to lm.BodyFormat
+func WriteCheckout(ds *dataset.Dataset, dirPath string, resolver qfs.Filesystem, lm *LinkMeta) error {
+	if ds.Structure != nil && lm.BodyFormat == ds.Structure.Format {
+		lm.BodyFormat = ""
+	}
+	if lm.BodyFormat != "" {
+		if ds.Structure == nil {
+			return fmt.Errorf("can't convert the body of a dataset without a structure")
+		}
+		if !lm.CheckedOut("structure") || !lm.CheckedOut("body") {
+			return fmt.Errorf("converting the body format requires checking out both the body and structure")
+		}
+		lm.StoredFormat = ds.Structure.Format
+		lm.StoredFormatConfig = ds.Structure.FormatConfig
+	}
+	if lm.BodyLimit > 0 && ds.Structure != nil && ds.Structure.Entries > 0 && ds.Structure.Entries <= lm.BodyLimit {
+		// the whole body fits
+		lm.BodyLimit = 0
+	}
+	return writeLinked(ds, dirPath, resolver, lm, true)
+}
+
+// WriteLinkedComponents writes components of a dataset to a linked directory,
+// leaving placeholder components absent and writing the body in the working
+// format
+func WriteLinkedComponents(ds *dataset.Dataset, dirPath string, resolver qfs.Filesystem) error {
+	lm, err := ReadLinkMeta(dirPath)
+	if err != nil {
+		return err
+	}
+	if lm.IsEmpty() {
+		return WriteComponents(ds, dirPath, resolver)
+	}
+	return writeLinked(ds, dirPath, resolver, lm, lm.BodyLimit == 0)
+}
+
+// writeLinked writes the checked out components of a dataset, converting
+// the body & structure as link metadata describes. Hashes of converted files
+// are recorded in the metadata, which is then written to the directory
+func writeLinked(ds *dataset.Dataset, dirPath string, resolver qfs.Filesystem, lm *LinkMeta, writeBody bool) error {
+	writeBody = writeBody && lm.CheckedOut("body")
+	if ds.Structure != nil && (lm.BodyFormat != "" || lm.BodyLimit > 0) {
+		st := ds.Structure
+		if lm.BodyFormat != "" {
+			ds.Structure = lm.workingStructure(st)
+		}
+		if writeBody {
+			body, err := openBody(ds, resolver)
+			if err != nil {
+				return err
+			}
+			data, err := base.ConvertBodyFile(body, st, ds.Structure, lm.BodyLimit, 0, lm.BodyLimit == 0)
+			if err != nil {
+				return err
+			}
+			ds.SetBodyFile(qfs.NewMemfileBytes(fmt.Sprintf("body.%s", ds.Structure.Format), data))
+		}
+	}
+
+	comp := component.ConvertDatasetToComponents(ds, resolver)
+	comp.Base().RemoveSubcomponent("commit")
+	comp.DropDerivedValues()
+
+	for _, compName := range component.AllSubcomponentNames() {
+		if !lm.CheckedOut(compName) || (compName == "body" && !writeBody) {
+			continue
+		}
+		path, err := WriteComponent(comp, compName, dirPath)
+		if err != nil {
+			return err
+		}
+		if path != "" && lm.convertsFile(compName) {
+			if err := lm.setHash(compName, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return WriteLinkMeta(dirPath, lm)
+}
+
+// openBody returns the body file of a dataset, fetching it from the resolver
+// if the dataset hasn't been opened
+func openBody(ds *dataset.Dataset, resolver qfs.Filesystem) (qfs.File, error) {
+	if f := ds.BodyFile(); f != nil {
+		return f, nil
+	}
+	if ds.BodyPath == "" || resolver == nil {
+		return nil, fmt.Errorf("no body file to write")
+	}
+	return resolver.Get(context.Background(), ds.BodyPath)
+}
+
+// ReadLinkedDir reads the component files of a linked directory like ReadDir,
+// leaving placeholder components unset so the stored version stands in for
+// them. Bodies in a working format are converted back to the stored format.
+// A body slice that's been modified can't stand in for the whole body, and is
+// an error
+func ReadLinkedDir(dir string) (*dataset.Dataset, error) {
+	ds, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	lm, err := ReadLinkMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if ds.BodyPath != "" && lm.convertsFile("body") {
+		unchanged, err := lm.unchanged("body", ds.BodyPath)
+		if err != nil {
+			return nil, err
+		}
+		if unchanged {
+			// the stored body stands in for an unedited body
+			ds.BodyPath = ""
+		} else if lm.BodyLimit > 0 {
+			return nil, fmt.Errorf("%s holds only the first %d body entries and can't replace the whole body. run `qri restore body` to check out the complete body before editing it", filepath.Base(ds.BodyPath), lm.BodyLimit)
+		}
+	}
+
+	if lm.BodyFormat != "" && ds.Structure != nil {
+		working := ds.Structure
+		ds.Structure = lm.storedStructure(working)
+		if ds.BodyPath != "" {
+			f, err := os.Open(ds.BodyPath)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			body, err := base.ConvertBodyFormat(qfs.NewMemfileReader(filepath.Base(ds.BodyPath), f), working, ds.Structure)
+			if err != nil {
+				return nil, fmt.Errorf("converting %s to %s: %s", filepath.Base(ds.BodyPath), lm.StoredFormat, err)
+			}
+			ds.BodyPath = ""
+			ds.SetBodyFile(body)
+		}
+	}
+	return ds, nil
+}
+
+// ReadWorkingDataset reads the working version of a dataset from a linked
+// directory for display. Placeholder components are filled in from the
+// stored version at path, so a body slice is never presented as the whole
+// body
+func ReadWorkingDataset(ctx context.Context, dir string, store cafs.Filestore, path string) (*dataset.Dataset, error) {
+	lm, err := ReadLinkMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	if lm.IsEmpty() {
+		return ReadDir(dir)
+	}
+	ds, err := ReadLinkedDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return ds, nil
+	}
+
+	stored, err := dsfs.LoadDataset(ctx, store, path)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Meta == nil && lm.Placeholder("meta") {
+		ds.Meta = stored.Meta
+	}
+	if ds.Structure == nil && lm.Placeholder("structure") {
+		ds.Structure = stored.Structure
+	}
+	if ds.Readme == nil && lm.Placeholder("readme") {
+		ds.Readme = stored.Readme
+	}
+	if ds.Viz == nil && lm.Placeholder("viz") {
+		ds.Viz = stored.Viz
+	}
+	if ds.Transform == nil && lm.Placeholder("transform") {
+		ds.Transform = stored.Transform
+	}
+	if ds.BodyPath == "" && ds.BodyFile() == nil && lm.Placeholder("body") {
+		ds.BodyPath = stored.BodyPath
+	}
+	return ds, nil
+}
+
+// HasWorkingBody reports whether the body file of a linked directory is the
+// whole body in the stored format, and can be read as-is
+func HasWorkingBody(dir string) (bool, error) {
+	lm, err := ReadLinkMeta(dir)
+	if err != nil {
+		return false, err
+	}
+	return lm.CheckedOut("body") && !lm.convertsFile("body"), nil
+}
+
+// knownLinkStatuses marks components of a directory that are unmodified
+// according to link metadata: placeholders that weren't checked out, and
+// converted files that haven't been edited since they were written
+func (lm *LinkMeta) knownLinkStatuses(working component.Component, known map[string]string) {
+	for _, compName := range component.AllSubcomponentNames() {
+		comp := working.Base().GetSubcomponent(compName)
+		if comp == nil {
+			if lm.Placeholder(compName) {
+				known[compName] = STUnmodified
+			}
+			continue
+		}
+		if lm.convertsFile(compName) {
+			if ok, err := lm.unchanged(compName, comp.Base().SourceFile); err == nil && ok {
+				known[compName] = STUnmodified
+			}
+		}
+	}
+}
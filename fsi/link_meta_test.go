@@ -0,0 +1,225 @@
+package fsi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+)
+
+func TestLinkMetaCheckOut(t *testing.T) {
+	lm := &LinkMeta{Components: []string{"meta"}, BodyLimit: 10, Hashes: map[string]string{"body": "abc"}}
+	if !lm.Placeholder("body") || !lm.Placeholder("structure") || lm.Placeholder("meta") {
+		t.Errorf("unexpected placeholders for %#v", lm)
+	}
+
+	lm.CheckOut("structure")
+	expect := &LinkMeta{Components: []string{"meta", "structure"}, BodyLimit: 10, Hashes: map[string]string{"body": "abc"}}
+	if diff := cmp.Diff(expect, lm); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	lm = &LinkMeta{BodyLimit: 10, BodyFormat: "json", Hashes: map[string]string{"body": "abc"}}
+	lm.CheckOut("body")
+	if !lm.IsEmpty() {
+		t.Errorf("expected checking out body to complete a body slice, got: %#v", lm)
+	}
+
+	lm = &LinkMeta{Components: []string{"meta"}}
+	lm.CheckOut()
+	if !lm.IsEmpty() {
+		t.Errorf("expected checking out everything to be complete, got: %#v", lm)
+	}
+
+	if err := ValidateCheckoutComponents([]string{"meta", "body"}); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateCheckoutComponents([]string{"metadata"}); err == nil {
+		t.Error("expected unknown component to error")
+	}
+}
+
+func TestPartialCheckout(t *testing.T) {
+	ctx := context.Background()
+	paths := NewTmpPaths()
+	defer paths.Close()
+
+	fsi := NewFSI(paths.testRepo, nil)
+	if _, _, err := fsi.CreateLink(paths.firstDir, "me/cities"); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.ParseDatasetRef("me/cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.CanonicalizeDatasetRef(paths.testRepo, &ref); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := dsfs.LoadDataset(ctx, paths.testRepo.Store(), ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = base.OpenDataset(ctx, paths.testRepo.Filesystem(), ds); err != nil {
+		t.Fatal(err)
+	}
+
+	lm := &LinkMeta{Components: []string{"meta", "structure", "body"}, BodyLimit: 2}
+	if err = WriteCheckout(ds, paths.firstDir, paths.testRepo.Filesystem(), lm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(paths.firstDir, "readme.md")); !os.IsNotExist(err) {
+		t.Errorf("expected readme to be left out of the checkout")
+	}
+	got, err := ReadLinkMeta(paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BodyLimit != 2 || got.Hashes["body"] == "" {
+		t.Errorf("expected body slice to be recorded, got: %#v", got)
+	}
+
+	changes, err := fsi.Status(ctx, paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range changes {
+		if ch.Type != STUnmodified {
+			t.Errorf("expected %s to be unmodified, got: %q", ch.Component, ch.Type)
+		}
+		if ch.Component == "readme" && ch.Message != PlaceholderMessage {
+			t.Errorf("expected readme to be a placeholder, got message: %q", ch.Message)
+		}
+	}
+
+	read, err := ReadLinkedDir(paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.BodyPath != "" {
+		t.Errorf("expected unedited body slice to stand in for the stored body, got body path: %q", read.BodyPath)
+	}
+	if read.Meta == nil || read.Meta.Title != ds.Meta.Title {
+		t.Errorf("expected meta to be read from the directory")
+	}
+
+	working, err := ReadWorkingDataset(ctx, paths.firstDir, paths.testRepo.Store(), ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if working.BodyPath != ds.BodyPath {
+		t.Errorf("expected body slice to read as the stored body %q, got: %q", ds.BodyPath, working.BodyPath)
+	}
+	if (working.Readme == nil) != (ds.Readme == nil) {
+		t.Errorf("expected placeholder readme to come from the stored version")
+	}
+	if whole, err := HasWorkingBody(paths.firstDir); err != nil {
+		t.Fatal(err)
+	} else if whole {
+		t.Errorf("expected a body slice not to be read as the working body")
+	}
+
+	bodyPath := filepath.Join(paths.firstDir, "body."+ds.Structure.Format)
+	data, err := ioutil.ReadFile(bodyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(bodyPath, append(data, '\n'), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadLinkedDir(paths.firstDir); err == nil {
+		t.Error("expected reading an edited body slice to error")
+	}
+
+	// removing a body slice leaves the stored body in place
+	if err := os.Remove(bodyPath); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = fsi.Status(ctx, paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statusTypes(changes); got["body"] != STUnmodified {
+		t.Errorf("expected missing body slice to be a placeholder, got: %q", got["body"])
+	}
+}
+
+func TestCheckoutBodyFormat(t *testing.T) {
+	ctx := context.Background()
+	paths := NewTmpPaths()
+	defer paths.Close()
+
+	fsi := NewFSI(paths.testRepo, nil)
+	if _, _, err := fsi.CreateLink(paths.firstDir, "me/cities"); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.ParseDatasetRef("me/cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.CanonicalizeDatasetRef(paths.testRepo, &ref); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := dsfs.LoadDataset(ctx, paths.testRepo.Store(), ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = base.OpenDataset(ctx, paths.testRepo.Filesystem(), ds); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Structure.Format != "csv" {
+		t.Fatalf("expected test dataset to be stored as csv, got: %q", ds.Structure.Format)
+	}
+
+	if err = WriteCheckout(ds, paths.firstDir, paths.testRepo.Filesystem(), &LinkMeta{BodyFormat: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	bodyPath := filepath.Join(paths.firstDir, "body.json")
+	if _, err := os.Stat(bodyPath); err != nil {
+		t.Fatalf("expected body to be written as json: %s", err)
+	}
+
+	changes, err := fsi.Status(ctx, paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range changes {
+		if ch.Type != STUnmodified {
+			t.Errorf("expected %s to be unmodified, got: %q", ch.Component, ch.Type)
+		}
+	}
+
+	read, err := ReadLinkedDir(paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.BodyPath != "" || read.BodyFile() != nil {
+		t.Errorf("expected unedited body to stand in for the stored body")
+	}
+	if read.Structure == nil || read.Structure.Format != "csv" {
+		t.Errorf("expected structure to be converted back to csv, got: %#v", read.Structure)
+	}
+
+	if err := ioutil.WriteFile(bodyPath, []byte(`[["toronto",40000000,55.5,false]]`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	read, err = ReadLinkedDir(paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.BodyFile() == nil {
+		t.Fatal("expected edited body to be read")
+	}
+	data, err := ioutil.ReadAll(read.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\n" {
+		t.Errorf("expected body converted to csv, got: %q", got)
+	}
+}
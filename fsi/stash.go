@@ -0,0 +1,373 @@
+package fsi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/component"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+)
+
+// ErrStashConflict is returned when popping a stash that changes components
+// that have also changed in the dataset's history since the stash was made
+var ErrStashConflict = fmt.Errorf("stash conflicts with changes to the dataset")
+
+// StashEntry is a set of uncommitted working directory changes, set aside in
+// the repo's content store without creating a version
+type StashEntry struct {
+	// alias of the stashed dataset
+	Ref string `json:"ref"`
+	// path of the head version when the changes were stashed
+	Head string `json:"head"`
+	// optional description of the stashed changes
+	Message string    `json:"message,omitempty"`
+	Created time.Time `json:"created"`
+	// status of the working directory when it was stashed
+	Changes []StatusItem `json:"changes"`
+	// content store paths of stashed component files, keyed by filename
+	Files map[string]string `json:"files"`
+}
+
+// changedComponents lists the names of components that had changes
+func (e *StashEntry) changedComponents() map[string]string {
+	changed := map[string]string{}
+	for _, si := range e.Changes {
+		if si.Type != STUnmodified {
+			changed[si.Component] = si.Type
+		}
+	}
+	return changed
+}
+
+// StashStore keeps stash entries for all linked datasets. StashStore is safe
+// for concurrent use
+type StashStore struct {
+	// file to persist entries to. An empty path keeps entries in memory
+	path    string
+	lk      sync.Mutex
+	entries []*StashEntry
+}
+
+// StashPath returns the standard path to the stash file for a given
+// file-system repo location
+func StashPath(repoPath string) string {
+	return filepath.Join(repoPath, "fsi_stash.json")
+}
+
+// NewStashStore creates a stash store that persists to a file on the local
+// filesystem. Passing an empty string creates an in-memory store
+func NewStashStore(path string) *StashStore {
+	s := &StashStore{path: path}
+	if path == "" {
+		return s
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("reading stash file: %s", err)
+		}
+		return s
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		log.Errorf("decoding stash file: %s", err)
+	}
+	return s
+}
+
+// List returns the stash entries for a dataset alias, most recent first
+func (s *StashStore) List(alias string) []*StashEntry {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	var list []*StashEntry
+	for _, e := range s.entries {
+		if e.Ref == alias {
+			list = append(list, e)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Created.After(list[j].Created) })
+	return list
+}
+
+func (s *StashStore) add(e *StashEntry) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.entries = append(s.entries, e)
+	return s.save()
+}
+
+func (s *StashStore) remove(e *StashEntry) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for i, entry := range s.entries {
+		if entry == e {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			break
+		}
+	}
+	return s.save()
+}
+
+func (s *StashStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0644)
+}
+
+// SetStashStore replaces the store stash entries are kept in
+func (fsi *FSI) SetStashStore(s *StashStore) {
+	fsi.stash = s
+}
+
+// StashList returns the stashed changes of the dataset linked to a directory,
+// most recent first
+func (fsi *FSI) StashList(dir string) ([]*StashEntry, error) {
+	refStr, ok := GetLinkedFilesysRef(dir)
+	if !ok {
+		return nil, ErrNoLink
+	}
+	ref, err := fsi.getRepoRef(refStr)
+	if err != nil && err != repo.ErrNoHistory {
+		return nil, err
+	}
+	return fsi.stash.List(ref.AliasString()), nil
+}
+
+// Stash sets aside uncommitted changes in a linked directory, copying the
+// component files into the repo's content store and returning the directory
+// to the head version of the dataset
+func (fsi *FSI) Stash(ctx context.Context, dir, message string) (*StashEntry, error) {
+	refStr, ok := GetLinkedFilesysRef(dir)
+	if !ok {
+		return nil, ErrNoLink
+	}
+	ref, err := fsi.getRepoRef(refStr)
+	if err != nil && err != repo.ErrNoHistory {
+		return nil, err
+	}
+
+	changes, err := fsi.Status(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	e := &StashEntry{
+		Ref:     ref.AliasString(),
+		Head:    ref.Path,
+		Message: message,
+		Created: time.Now(),
+		Changes: changes,
+		Files:   map[string]string{},
+	}
+	if len(e.changedComponents()) == 0 {
+		return nil, fmt.Errorf("no local changes to stash")
+	}
+
+	working, err := component.ListDirectoryComponents(dir)
+	if err != nil {
+		return nil, err
+	}
+	store := fsi.repo.Store()
+	for _, comp := range working.Base().Subcomponents {
+		path := comp.Base().SourceFile
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := store.Put(ctx, qfs.NewMemfileBytes(filepath.Base(path), data))
+		if err != nil {
+			return nil, fmt.Errorf("stashing %s: %s", filepath.Base(path), err)
+		}
+		if pinner, ok := store.(cafs.Pinner); ok {
+			if err := pinner.Pin(ctx, key, true); err != nil {
+				log.Debugf("pinning stashed file: %s", err)
+			}
+		}
+		e.Files[filepath.Base(path)] = key
+	}
+
+	if err := fsi.stash.add(e); err != nil {
+		return nil, err
+	}
+	if err := fsi.writeHead(ctx, dir, ref.Path); err != nil {
+		return e, fmt.Errorf("changes were stashed, but restoring the working directory failed: %s", err)
+	}
+	return e, nil
+}
+
+// StashPop re-applies stashed changes to a linked directory, removing them
+// from the stash. index selects the stash entry, with 0 being the most recent.
+// Popping requires a clean working directory. If the dataset's head moved
+// since the changes were stashed, any component that changed both in history
+// & in the stash is a conflict: StashPop returns ErrStashConflict & leaves the
+// stash in place unless force is true. Conflicting component names are
+// returned either way
+func (fsi *FSI) StashPop(ctx context.Context, dir string, index int, force bool) (e *StashEntry, conflicts []string, err error) {
+	list, err := fsi.StashList(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil, fmt.Errorf("no stashed changes")
+	}
+	if index < 0 || index >= len(list) {
+		return nil, nil, fmt.Errorf("stash index %d out of range, there are %d stashed changes", index, len(list))
+	}
+	e = list[index]
+
+	refStr, _ := GetLinkedFilesysRef(dir)
+	ref, err := fsi.getRepoRef(refStr)
+	if err != nil && err != repo.ErrNoHistory {
+		return nil, nil, err
+	}
+
+	if !force {
+		if err := fsi.IsWorkingDirectoryClean(ctx, dir); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if ref.Path != e.Head {
+		if conflicts, err = fsi.stashConflicts(ctx, e, ref.Path); err != nil {
+			return nil, nil, err
+		}
+		if len(conflicts) > 0 && !force {
+			return e, conflicts, ErrStashConflict
+		}
+	}
+
+	working, err := component.ListDirectoryComponents(dir)
+	if err != nil && err != component.ErrNoDatasetFiles {
+		return nil, nil, err
+	}
+	removeComponentFile := func(compName string) error {
+		if working == nil {
+			return nil
+		}
+		if comp := working.Base().GetSubcomponent(compName); comp != nil {
+			if err := os.Remove(comp.Base().SourceFile); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for compName, typ := range e.changedComponents() {
+		if typ == STRemoved {
+			if err := removeComponentFile(compName); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	store := fsi.repo.Store()
+	for filename, key := range e.Files {
+		// a stashed component may have a different file extension than the
+		// current one, remove the current one first
+		if err := removeComponentFile(strings.TrimSuffix(filename, filepath.Ext(filename))); err != nil {
+			return nil, nil, err
+		}
+		f, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading stashed %s: %s", filename, err)
+		}
+		data, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, filename), data, component.WritePerm); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if pinner, ok := store.(cafs.Pinner); ok {
+		for _, key := range e.Files {
+			if err := pinner.Unpin(ctx, key, true); err != nil {
+				log.Debugf("unpinning stashed file: %s", err)
+			}
+		}
+	}
+	fsi.statusCache.Drop(dir)
+	return e, conflicts, fsi.stash.remove(e)
+}
+
+// stashConflicts lists components that changed both in a stash entry and
+// between the entry's head and the current head
+func (fsi *FSI) stashConflicts(ctx context.Context, e *StashEntry, head string) ([]string, error) {
+	load := func(path string) (component.Component, error) {
+		ds := &dataset.Dataset{}
+		if path != "" {
+			var err error
+			if ds, err = dsfs.LoadDataset(ctx, fsi.repo.Store(), path); err != nil {
+				return nil, err
+			}
+		}
+		comps := component.ConvertDatasetToComponents(ds, fsi.repo.Filesystem())
+		comps.Base().RemoveSubcomponent("commit")
+		comps.DropDerivedValues()
+		return comps, nil
+	}
+	prev, err := load(e.Head)
+	if err != nil {
+		return nil, err
+	}
+	next, err := load(head)
+	if err != nil {
+		return nil, err
+	}
+	moved, err := fsi.CalculateStateTransition(ctx, prev, next)
+	if err != nil {
+		return nil, err
+	}
+
+	stashed := e.changedComponents()
+	var conflicts []string
+	for _, si := range moved {
+		if _, ok := stashed[si.Component]; ok && si.Type != STUnmodified {
+			conflicts = append(conflicts, si.Component)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// writeHead replaces the component files in a linked directory with the
+// version of the dataset at path, following the directory's link metadata
+func (fsi *FSI) writeHead(ctx context.Context, dir, path string) error {
+	defer fsi.statusCache.Drop(dir)
+	if err := DeleteComponentFiles(dir); err != nil && err != component.ErrNoDatasetFiles {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	ds, err := dsfs.LoadDataset(ctx, fsi.repo.Store(), path)
+	if err != nil {
+		return err
+	}
+	if err = base.OpenDataset(ctx, fsi.repo.Filesystem(), ds); err != nil {
+		return err
+	}
+	lm, err := ReadLinkMeta(dir)
+	if err != nil {
+		return err
+	}
+	return writeLinked(ds, dir, fsi.repo.Filesystem(), lm, true)
+}
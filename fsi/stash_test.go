@@ -0,0 +1,113 @@
+package fsi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+)
+
+func TestStash(t *testing.T) {
+	ctx := context.Background()
+	paths := NewTmpPaths()
+	defer paths.Close()
+
+	fsi := NewFSI(paths.testRepo, nil)
+	if _, _, err := fsi.CreateLink(paths.firstDir, "me/cities"); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.ParseDatasetRef("me/cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.CanonicalizeDatasetRef(paths.testRepo, &ref); err != nil {
+		t.Fatal(err)
+	}
+	if err = fsi.writeHead(ctx, paths.firstDir, ref.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fsi.Stash(ctx, paths.firstDir, ""); err == nil {
+		t.Error("expected stashing a clean directory to error")
+	}
+
+	metaPath := filepath.Join(paths.firstDir, "meta.json")
+	edited := []byte(`{"qri":"md:0","title":"stashed title"}`)
+	if err := ioutil.WriteFile(metaPath, edited, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	e, err := fsi.Stash(ctx, paths.firstDir, "new title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Head != ref.Path || e.Message != "new title" {
+		t.Errorf("unexpected stash entry: %#v", e)
+	}
+	if err := fsi.IsWorkingDirectoryClean(ctx, paths.firstDir); err != nil {
+		t.Errorf("expected stashing to clean the working directory: %s", err)
+	}
+	list, err := fsi.StashList(paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("expected 1 stash entry, got: %d", len(list))
+	}
+
+	if _, conflicts, err := fsi.StashPop(ctx, paths.firstDir, 0, false); err != nil || len(conflicts) != 0 {
+		t.Fatalf("unexpected pop result. conflicts: %v, err: %v", conflicts, err)
+	}
+	data, err := ioutil.ReadFile(metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(edited) {
+		t.Errorf("expected stashed meta to be re-applied, got: %s", data)
+	}
+	if list, _ = fsi.StashList(paths.firstDir); len(list) != 0 {
+		t.Errorf("expected pop to remove the stash entry, got %d entries", len(list))
+	}
+
+	// saving a version that changes the same component conflicts
+	if _, err = fsi.Stash(ctx, paths.firstDir, ""); err != nil {
+		t.Fatal(err)
+	}
+	prev, err := dsfs.LoadDataset(ctx, paths.testRepo.Store(), ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := &dataset.Dataset{
+		Peername: ref.Peername,
+		Name:     ref.Name,
+		Meta:     &dataset.Meta{Qri: "md:0", Title: "saved title"},
+	}
+	changes.Structure = prev.Structure
+	saved, err := base.SaveDataset(ctx, paths.testRepo, ioes.NewDiscardIOStreams(), changes, nil, nil, base.SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = fsi.writeHead(ctx, paths.firstDir, saved.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	_, conflicts, err := fsi.StashPop(ctx, paths.firstDir, 0, false)
+	if err != ErrStashConflict {
+		t.Fatalf("expected conflict error, got: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0] != "meta" {
+		t.Errorf("expected meta to conflict, got: %v", conflicts)
+	}
+	if _, _, err = fsi.StashPop(ctx, paths.firstDir, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = ioutil.ReadFile(metaPath); string(data) != string(edited) {
+		t.Errorf("expected forced pop to apply stashed meta, got: %s", data)
+	}
+}
@@ -30,6 +30,10 @@ var (
 	ErrWorkingDirectoryDirty = fmt.Errorf("working directory is dirty")
 )
 
+// PlaceholderMessage is the status message of a component that wasn't checked
+// out to a linked directory
+const PlaceholderMessage = "placeholder: not checked out"
+
 // StatusItem is a component that has status representation on the filesystem
 type StatusItem struct {
 	SourceFile string    `json:"sourceFile"`
@@ -69,7 +73,9 @@ func (fsi *FSI) AliasToLinkedDir(alias string) (string, error) {
 	return ref.FSIPath, nil
 }
 
-// Status compares status of the current working directory against the dataset's last version
+// Status compares status of the current working directory against the dataset's last version.
+// Results are cached per directory: components with unchanged files reuse their cached status,
+// and bodies are compared by content hash before falling back to parsing
 func (fsi *FSI) Status(ctx context.Context, dir string) (changes []StatusItem, err error) {
 	refStr, ok := GetLinkedFilesysRef(dir)
 	if !ok {
@@ -77,8 +83,26 @@ func (fsi *FSI) Status(ctx context.Context, dir string) (changes []StatusItem, e
 		return nil, err
 	}
 
-	var stored *dataset.Dataset
 	ref, err := fsi.getRepoRef(refStr)
+
+	// record the time before reading any files, so writes that happen while
+	// status is calculated aren't mistaken for unchanged files next time
+	calculated := time.Now()
+	working, err := component.ListDirectoryComponents(dir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := statComponentFiles(working)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := fsi.statusCache.get(dir)
+	if changes, ok := cached.cachedStatus(ref.Path, files); ok {
+		return changes, nil
+	}
+
+	var stored *dataset.Dataset
 	if ref.Path == "" {
 		// no dataset, compare to an empty ds
 		stored = &dataset.Dataset{}
@@ -92,26 +116,43 @@ func (fsi *FSI) Status(ctx context.Context, dir string) (changes []StatusItem, e
 	stored.Commit = nil
 	stored.Peername = ""
 
-	working, err := component.ListDirectoryComponents(dir)
+	err = component.ExpandListedComponents(working, fsi.repo.Filesystem())
 	if err != nil {
 		return nil, err
 	}
 
-	err = component.ExpandListedComponents(working, fsi.repo.Filesystem())
+	known, storedBodyHash := fsi.knownStatuses(ctx, cached, ref.Path, stored, working, files)
+	lm, err := ReadLinkMeta(dir)
 	if err != nil {
 		return nil, err
 	}
-
-	// TODO: If in the future we cache mtimes and previous status, we can more lazily read only
-	// some components.
+	lm.knownLinkStatuses(working, known)
 
 	prevComps := component.ConvertDatasetToComponents(stored, fsi.repo.Filesystem())
 	nextComps := working
-	return fsi.CalculateStateTransition(ctx, prevComps, nextComps)
+	if changes, err = fsi.calculateStateTransition(ctx, prevComps, nextComps, known); err != nil {
+		return nil, err
+	}
+
+	fsi.statusCache.put(dir, &statusCacheEntry{
+		Path:           ref.Path,
+		StoredBodyHash: storedBodyHash,
+		Calculated:     calculated,
+		Files:          files,
+		Status:         changes,
+	})
+	return changes, nil
 }
 
 // CalculateStateTransition calculates the differences between two versions of a dataset.
 func (fsi *FSI) CalculateStateTransition(ctx context.Context, prev, next component.Component) (changes []StatusItem, err error) {
+	return fsi.calculateStateTransition(ctx, prev, next, nil)
+}
+
+// calculateStateTransition calculates differences between two versions, using
+// the given status types for components present in both versions instead of
+// comparing them
+func (fsi *FSI) calculateStateTransition(ctx context.Context, prev, next component.Component, known map[string]string) (changes []StatusItem, err error) {
 
 	changes = make([]StatusItem, 0, component.NumberPossibleComponents)
 
@@ -154,6 +195,15 @@ func (fsi *FSI) CalculateStateTransition(ctx context.Context, prev, next compone
 			})
 			continue
 		} else if prevComp != nil && nextComp == nil {
+			if typ, ok := known[compName]; ok {
+				// Intentionally absent - the stored version stands in for the component.
+				changes = append(changes, StatusItem{
+					Component: compName,
+					Type:      typ,
+					Message:   PlaceholderMessage,
+				})
+				continue
+			}
 			// Did exist before, but doesn't now - component was removed.
 			changes = append(changes, StatusItem{
 				Component: compName,
@@ -162,6 +212,16 @@ func (fsi *FSI) CalculateStateTransition(ctx context.Context, prev, next compone
 			continue
 		}
 
+		if typ, ok := known[compName]; ok {
+			changes = append(changes, StatusItem{
+				SourceFile: nextComp.Base().SourceFile,
+				Component:  compName,
+				Type:       typ,
+				Mtime:      nextComp.Base().ModTime,
+			})
+			continue
+		}
+
 		isEqual, err := prevComp.Compare(nextComp)
 		if err != nil {
 			changes = append(changes, StatusItem{
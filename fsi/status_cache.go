@@ -0,0 +1,303 @@
+package fsi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base/component"
+)
+
+// statusCacheRacyWindow is how close a file's modification time can be to
+// the moment a status was calculated before the file's stat can't be trusted.
+// Filesystems with coarse timestamps can write a file twice within the same
+// tick, leaving mtime & size unchanged
+const statusCacheRacyWindow = time.Second
+
+// StatusCache remembers the result of calculating the status of linked working
+// directories, alongside the modification time, size & content hash of each
+// component file. Status uses the cache to skip comparing components whose
+// files haven't changed, and to compare bodies by hash instead of parsing
+// them. StatusCache is safe for concurrent use
+type StatusCache struct {
+	// directory to persist entries to. An empty dir keeps entries in memory
+	dir     string
+	lk      sync.Mutex
+	entries map[string]*statusCacheEntry
+}
+
+// NewStatusCache creates a status cache that persists to a directory on the
+// local filesystem. Passing an empty string creates an in-memory cache
+func NewStatusCache(dir string) *StatusCache {
+	if dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			log.Debugf("creating status cache directory: %s", err)
+			dir = ""
+		}
+	}
+	return &StatusCache{
+		dir:     dir,
+		entries: map[string]*statusCacheEntry{},
+	}
+}
+
+// statusCacheEntry is the cached status of a single working directory
+type statusCacheEntry struct {
+	// path of the stored version the status was calculated against
+	Path string `json:"path"`
+	// hex-encoded sha256 sum of the stored version's body file
+	StoredBodyHash string `json:"storedBodyHash,omitempty"`
+	// time the status was calculated
+	Calculated time.Time `json:"calculated"`
+	// component files, keyed by source file path
+	Files  map[string]fileStat `json:"files"`
+	Status []StatusItem        `json:"status"`
+}
+
+// fileStat describes the state of a component file
+type fileStat struct {
+	Mtime time.Time `json:"mtime"`
+	Size  int64     `json:"size"`
+	// hex-encoded sha256 sum of file contents, only calculated for body files
+	Hash string `json:"hash,omitempty"`
+}
+
+// unchanged reports whether a file still matches the cached state without
+// reading it
+func (e *statusCacheEntry) unchanged(path string, st fileStat) bool {
+	if e == nil || path == "" {
+		return false
+	}
+	prev, ok := e.Files[path]
+	if !ok || !prev.Mtime.Equal(st.Mtime) || prev.Size != st.Size {
+		return false
+	}
+	return st.Mtime.Before(e.Calculated.Add(-statusCacheRacyWindow))
+}
+
+// item returns the cached status of a component
+func (e *statusCacheEntry) item(compName string) (StatusItem, bool) {
+	if e == nil {
+		return StatusItem{}, false
+	}
+	for _, si := range e.Status {
+		if si.Component == compName {
+			return si, true
+		}
+	}
+	return StatusItem{}, false
+}
+
+// get returns the cached entry for a working directory, or nil
+func (c *StatusCache) get(dir string) *statusCacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if e, ok := c.entries[dir]; ok {
+		return e
+	}
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(c.filename(dir))
+	if err != nil {
+		return nil
+	}
+	e := &statusCacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		log.Debugf("reading status cache entry for %q: %s", dir, err)
+		return nil
+	}
+	c.entries[dir] = e
+	return e
+}
+
+func (c *StatusCache) put(dir string, e *statusCacheEntry) {
+	if c == nil {
+		return
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	c.entries[dir] = e
+	if c.dir == "" {
+		return
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Debugf("encoding status cache entry for %q: %s", dir, err)
+		return
+	}
+	if err := ioutil.WriteFile(c.filename(dir), data, 0644); err != nil {
+		log.Debugf("writing status cache entry for %q: %s", dir, err)
+	}
+}
+
+// Drop removes the cached status of a working directory
+func (c *StatusCache) Drop(dir string) {
+	if c == nil {
+		return
+	}
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	delete(c.entries, dir)
+	if c.dir != "" {
+		if err := os.Remove(c.filename(dir)); err != nil && !os.IsNotExist(err) {
+			log.Debugf("removing status cache entry for %q: %s", dir, err)
+		}
+	}
+}
+
+func (c *StatusCache) filename(dir string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s.json", b32Enc.EncodeToString([]byte(dir))))
+}
+
+var b32Enc = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// statComponentFiles stats every component file in a directory listing,
+// keyed by source file path
+func statComponentFiles(listing component.Component) (map[string]fileStat, error) {
+	files := map[string]fileStat{}
+	for _, comp := range listing.Base().Subcomponents {
+		path := comp.Base().SourceFile
+		if path == "" {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = fileStat{Mtime: fi.ModTime(), Size: fi.Size()}
+	}
+	return files, nil
+}
+
+// cachedStatus returns a copy of the cached status if no component file has
+// changed since it was calculated
+func (e *statusCacheEntry) cachedStatus(path string, files map[string]fileStat) ([]StatusItem, bool) {
+	if e == nil || e.Path != path || len(e.Files) != len(files) {
+		return nil, false
+	}
+	for p, st := range files {
+		if !e.unchanged(p, st) {
+			return nil, false
+		}
+	}
+	changes := make([]StatusItem, len(e.Status))
+	copy(changes, e.Status)
+	return changes, true
+}
+
+// knownStatuses determines component statuses that can be carried over from a
+// cache entry or calculated from body hashes, without loading component files.
+// Hashes calculated along the way are recorded in files
+func (fsi *FSI) knownStatuses(ctx context.Context, e *statusCacheEntry, path string, stored *dataset.Dataset, working component.Component, files map[string]fileStat) (known map[string]string, storedBodyHash string) {
+	known = map[string]string{}
+	if e != nil && e.Path == path {
+		storedBodyHash = e.StoredBodyHash
+	} else {
+		e = nil
+	}
+
+	sourceFile := func(compName string) string {
+		if comp := working.Base().GetSubcomponent(compName); comp != nil {
+			return comp.Base().SourceFile
+		}
+		return ""
+	}
+
+	for _, compName := range component.AllSubcomponentNames() {
+		if compName == "body" {
+			continue
+		}
+		src := sourceFile(compName)
+		if si, ok := e.item(compName); ok && si.SourceFile == src && e.unchanged(src, files[src]) {
+			known[compName] = si.Type
+		}
+	}
+
+	bodyFile := sourceFile("body")
+	if bodyFile == "" {
+		return known, storedBodyHash
+	}
+
+	st := files[bodyFile]
+	if e.unchanged(bodyFile, st) {
+		st.Hash = e.Files[bodyFile].Hash
+	} else {
+		var err error
+		if st.Hash, err = hashFile(bodyFile); err != nil {
+			log.Debugf("hashing body file: %s", err)
+			return known, storedBodyHash
+		}
+	}
+	files[bodyFile] = st
+
+	// identical bytes to the stored body can't be a change
+	if stored.BodyPath != "" {
+		if storedBodyHash == "" {
+			storedBodyHash = fsi.storedBodyHash(ctx, stored.BodyPath)
+		}
+		if storedBodyHash != "" && storedBodyHash == st.Hash {
+			known["body"] = STUnmodified
+			return known, storedBodyHash
+		}
+	}
+
+	// identical bytes to the last calculation, against an unchanged structure
+	// have the same status
+	if si, ok := e.item("body"); ok && si.SourceFile == bodyFile && st.Hash != "" && e.Files[bodyFile].Hash == st.Hash {
+		structureFile := sourceFile("structure")
+		if structureFile == "" || e.unchanged(structureFile, files[structureFile]) {
+			known["body"] = si.Type
+		}
+	}
+	return known, storedBodyHash
+}
+
+func (fsi *FSI) storedBodyHash(ctx context.Context, bodyPath string) string {
+	f, err := fsi.repo.Filesystem().Get(ctx, bodyPath)
+	if err != nil {
+		log.Debugf("opening stored body: %s", err)
+		return ""
+	}
+	defer f.Close()
+	h, err := hashReader(f)
+	if err != nil {
+		log.Debugf("hashing stored body: %s", err)
+		return ""
+	}
+	return h
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return hashReader(f)
+}
+
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,122 @@
+package fsi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/repo"
+)
+
+func TestStatusCache(t *testing.T) {
+	ctx := context.Background()
+	paths := NewTmpPaths()
+	defer paths.Close()
+
+	fsi := NewFSI(paths.testRepo, nil)
+	fsi.SetStatusCache(NewStatusCache(StatusCachePath(paths.homeDir)))
+	if _, _, err := fsi.CreateLink(paths.firstDir, "me/cities"); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := repo.ParseDatasetRef("me/cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.CanonicalizeDatasetRef(paths.testRepo, &ref); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := dsfs.LoadDataset(ctx, paths.testRepo.Store(), ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = base.OpenDataset(ctx, paths.testRepo.Filesystem(), ds); err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteComponents(ds, paths.firstDir, paths.testRepo.Filesystem()); err != nil {
+		t.Fatal(err)
+	}
+
+	// backdate files so the cache trusts their stats
+	old := time.Now().Add(-time.Hour)
+	metaPath := filepath.Join(paths.firstDir, "meta.json")
+	finfos, err := ioutil.ReadDir(paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range finfos {
+		if err := os.Chtimes(filepath.Join(paths.firstDir, fi.Name()), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := fsi.Status(ctx, paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := statusTypes(changes)
+	if expect["meta"] != STUnmodified || expect["body"] != STUnmodified {
+		t.Fatalf("expected a clean checkout, got: %v", expect)
+	}
+
+	// overwrite meta with the same number of invalid bytes, keeping mtime.
+	// a cached status never reads the file
+	data, err := ioutil.ReadFile(metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	garbage := make([]byte, len(data))
+	for i := range garbage {
+		garbage[i] = '!'
+	}
+	if err := ioutil.WriteFile(metaPath, garbage, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(metaPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new FSI reads the persisted cache
+	fsi = NewFSI(paths.testRepo, nil)
+	fsi.SetStatusCache(NewStatusCache(StatusCachePath(paths.homeDir)))
+	changes, err = fsi.Status(ctx, paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statusTypes(changes); got["meta"] != expect["meta"] {
+		t.Errorf("expected cached meta status %q, got: %q", expect["meta"], got["meta"])
+	}
+
+	// a changed mtime invalidates the cached meta status
+	if err := os.Chtimes(metaPath, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = fsi.Status(ctx, paths.firstDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := statusTypes(changes)
+	if got["meta"] != STParseError {
+		t.Errorf("expected meta status to be recalculated as %q, got: %q", STParseError, got["meta"])
+	}
+	if got["body"] != expect["body"] {
+		t.Errorf("expected body status %q to carry over, got: %q", expect["body"], got["body"])
+	}
+
+	fsi.statusCache.Drop(paths.firstDir)
+	if fsi.statusCache.get(paths.firstDir) != nil {
+		t.Error("expected dropped entry to be removed")
+	}
+}
+
+func statusTypes(changes []StatusItem) map[string]string {
+	types := map[string]string{}
+	for _, ch := range changes {
+		types[ch.Component] = ch.Type
+	}
+	return types
+}
@@ -23,6 +23,7 @@ require (
 	github.com/gofrs/flock v0.7.1 // indirect
 	github.com/google/flatbuffers v1.11.0
 	github.com/google/go-cmp v0.3.1
+	github.com/graphql-go/graphql v0.8.1
 	github.com/ipfs/go-cid v0.0.3
 	github.com/ipfs/go-datastore v0.1.1
 	github.com/ipfs/go-ds-badger v0.0.7 // indirect
@@ -45,6 +46,7 @@ require (
 	github.com/multiformats/go-multicodec v0.1.6
 	github.com/multiformats/go-multihash v0.0.8
 	github.com/pkg/errors v0.8.1
+	github.com/prometheus/client_golang v1.1.0
 	github.com/qri-io/apiutil v0.1.0
 	github.com/qri-io/dag v0.2.1-0.20200317231253-5cd938b03caf
 	github.com/qri-io/dataset v0.1.5-0.20200324184139-108a69072ede
@@ -62,6 +64,7 @@ require (
 	github.com/spf13/cobra v0.0.5
 	github.com/theckman/go-flock v0.7.1
 	github.com/ugorji/go/codec v1.1.7
+	github.com/whyrusleeping/go-logging v0.0.0-20170515211332-0457bb6b88fc
 	go.starlark.net v0.0.0-20200330013621-be5394c419b6
 	golang.org/x/crypto v0.0.0-20190926180335-cea2066c6411
 	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd
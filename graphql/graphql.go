@@ -0,0 +1,223 @@
+// Package graphql runs read-only GraphQL queries with graphql-go, adding the
+// pieces qri needs on top: a JSON scalar for data without a fixed shape, a
+// default resolver that reads qri's structs, and depth & cost limits that are
+// checked before any resolvers run
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Schema is a graphql-go schema with cost estimates for expensive fields
+type Schema struct {
+	gql.Schema
+	// Costs maps "Type.field" to the estimated cost of resolving a field.
+	// Fields that aren't listed cost 1
+	Costs map[string]int
+}
+
+// NewSchema creates a schema with a query root type. Fields without a resolver
+// read from their source with DefaultResolve
+func NewSchema(query *gql.Object, costs map[string]int) (*Schema, error) {
+	s, err := gql.NewSchema(gql.SchemaConfig{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	for name, t := range s.TypeMap() {
+		obj, ok := t.(*gql.Object)
+		if !ok || strings.HasPrefix(name, "__") {
+			continue
+		}
+		for _, fd := range obj.Fields() {
+			if fd.Resolve == nil {
+				fd.Resolve = DefaultResolve
+			}
+		}
+	}
+	return &Schema{Schema: s, Costs: costs}, nil
+}
+
+// Request is a GraphQL query, as sent in a POST body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response is the result of a request. Requests that fail before execution
+// have no data
+type Response = gql.Result
+
+// Options configure query execution
+type Options struct {
+	// MaxDepth limits how deeply selections can nest. 0 means no limit
+	MaxDepth int
+	// MaxCost limits the estimated cost of a query. 0 means no limit
+	MaxCost int
+	// DefaultListSize is the number of items assumed for list fields that
+	// don't have a "limit" argument when estimating cost, defaults to 25
+	DefaultListSize int
+}
+
+// Do parses, validates & executes a request. Depth & cost limits are checked
+// before graphql-go's full validation, so an expensive query is rejected
+// without walking it more than once
+func Do(ctx context.Context, schema *Schema, req Request, opts ...func(o *Options)) *Response {
+	o := &Options{DefaultListSize: 25}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	src := source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return errResponse(err)
+	}
+	// graphql-go's other validation rules recurse forever on fragments that
+	// spread themselves, reject those first
+	if vr := gql.ValidateDocument(&schema.Schema, doc, []gql.ValidationRuleFn{gql.NoFragmentCyclesRule}); !vr.IsValid {
+		return &Response{Errors: vr.Errors}
+	}
+	if err := checkLimits(schema, doc, req, o); err != nil {
+		return errResponse(err)
+	}
+	if vr := gql.ValidateDocument(&schema.Schema, doc, nil); !vr.IsValid {
+		return &Response{Errors: vr.Errors}
+	}
+
+	return gql.Execute(gql.ExecuteParams{
+		Schema:        schema.Schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+func errResponse(err error) *Response {
+	return &Response{Errors: gqlerrors.FormatErrors(err)}
+}
+
+// JSON is an arbitrary JSON value, for data without a fixed shape like
+// dataset bodies & schemas
+var JSON = gql.NewScalar(gql.ScalarConfig{
+	Name:        "JSON",
+	Description: "an arbitrary JSON value",
+	Serialize: func(v interface{}) interface{} {
+		if data, ok := v.([]byte); ok {
+			return json.RawMessage(data)
+		}
+		return v
+	},
+	ParseValue: func(v interface{}) interface{} {
+		return v
+	},
+	ParseLiteral: literalValue,
+})
+
+// literalValue converts a query literal to the value JSON decoding would give
+func literalValue(v ast.Value) interface{} {
+	switch x := v.(type) {
+	case *ast.ObjectValue:
+		obj := map[string]interface{}{}
+		for _, f := range x.Fields {
+			obj[f.Name.Value] = literalValue(f.Value)
+		}
+		return obj
+	case *ast.ListValue:
+		list := make([]interface{}, len(x.Values))
+		for i, item := range x.Values {
+			list[i] = literalValue(item)
+		}
+		return list
+	case *ast.IntValue, *ast.FloatValue:
+		var n float64
+		if err := json.Unmarshal([]byte(x.GetValue().(string)), &n); err != nil {
+			return nil
+		}
+		return n
+	}
+	return v.GetValue()
+}
+
+// DefaultResolve reads a field from a map key or struct field. Struct fields
+// match on their json tag, or on their name ignoring case, including the
+// fields of embedded structs. Times resolve to RFC 3339 strings
+func DefaultResolve(p gql.ResolveParams) (interface{}, error) {
+	v := resolveField(p.Source, p.Info.FieldName)
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return v, nil
+}
+
+func resolveField(source interface{}, name string) interface{} {
+	rv := reflect.ValueOf(source)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil
+		}
+		return v.Interface()
+	case reflect.Struct:
+		if v, ok := structField(rv, name); ok {
+			return v.Interface()
+		}
+	}
+	return nil
+}
+
+func structField(rv reflect.Value, name string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if tag == name || (tag == "" && strings.EqualFold(sf.Name, name)) {
+			return rv.Field(i), true
+		}
+	}
+	// check embedded structs after direct fields
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.Anonymous {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			if v, ok := structField(fv, name); ok {
+				return v, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,280 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+type testAuthor struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+}
+
+type testBook struct {
+	Title  string `json:"title"`
+	Pages  int
+	Author *testAuthor `json:"author"`
+}
+
+func testSchema(t *testing.T) *Schema {
+	author := gql.NewObject(gql.ObjectConfig{
+		Name: "Author",
+		Fields: gql.Fields{
+			"name":    {Type: gql.NewNonNull(gql.String)},
+			"created": {Type: gql.String},
+		},
+	})
+	book := gql.NewObject(gql.ObjectConfig{
+		Name: "Book",
+		Fields: gql.Fields{
+			"title":  {Type: gql.NewNonNull(gql.String)},
+			"pages":  {Type: gql.Int},
+			"author": {Type: author},
+			"meta":   {Type: JSON},
+			"broken": {
+				Type: gql.NewNonNull(gql.String),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return nil, fmt.Errorf("broken field")
+				},
+			},
+		},
+	})
+	books := []*testBook{
+		{Title: "a", Pages: 1, Author: &testAuthor{Name: "x", Created: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{Title: "b", Pages: 2},
+		{Title: "c", Pages: 3},
+	}
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"books": {
+				Type: gql.NewList(gql.NewNonNull(book)),
+				Args: gql.FieldConfigArgument{
+					"limit":  {Type: gql.Int, DefaultValue: 10},
+					"offset": {Type: gql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					offset, limit := p.Args["offset"].(int), p.Args["limit"].(int)
+					if offset > len(books) {
+						offset = len(books)
+					}
+					if offset+limit > len(books) {
+						limit = len(books) - offset
+					}
+					return books[offset : offset+limit], nil
+				},
+			},
+			"authors": {
+				Type: gql.NewList(author),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return []*testAuthor{books[0].Author}, nil
+				},
+			},
+			"book": {
+				Type: book,
+				Args: gql.FieldConfigArgument{"title": {Type: gql.NewNonNull(gql.String)}},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					for _, b := range books {
+						if b.Title == p.Args["title"] {
+							return b, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	schema, err := NewSchema(query, map[string]int{"Query.book": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func mustJSON(t *testing.T, res *Response) string {
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestDo(t *testing.T) {
+	schema := testSchema(t)
+	cases := []struct {
+		description string
+		req         Request
+		expect      string
+	}{
+		{"shorthand query",
+			Request{Query: `{ books(limit: 2) { title pages } }`},
+			`{"data":{"books":[{"pages":1,"title":"a"},{"pages":2,"title":"b"}]}}`},
+		{"aliases, nesting & typename",
+			Request{Query: `query { first: book(title: "a") { __typename title author { name created } } }`},
+			`{"data":{"first":{"__typename":"Book","author":{"created":"2020-01-01T00:00:00Z","name":"x"},"title":"a"}}}`},
+		{"variables & defaults",
+			Request{Query: `query Page($offset: Int = 1, $limit: Int) { books(offset: $offset, limit: $limit) { title } }`, Variables: map[string]interface{}{"limit": float64(1)}},
+			`{"data":{"books":[{"title":"b"}]}}`},
+		{"fragments & directives",
+			Request{Query: `query ($skip: Boolean!) { book(title: "b") { ...parts ... on Book { pages @skip(if: $skip) } } } fragment parts on Book { title author { name } }`, Variables: map[string]interface{}{"skip": true}},
+			`{"data":{"book":{"author":null,"title":"b"}}}`},
+		{"selecting an operation",
+			Request{Query: `query A { book(title: "a") { title } } query B { book(title: "c") { title } }`, OperationName: "B"},
+			`{"data":{"book":{"title":"c"}}}`},
+		{"field errors null the nearest nullable parent",
+			Request{Query: `{ book(title: "a") { title broken } }`},
+			`{"data":{"book":null},"errors":[{"message":"broken field","locations":[{"line":1,"column":28}],"path":["book","broken"]}]}`},
+	}
+
+	for _, c := range cases {
+		got := mustJSON(t, Do(context.Background(), schema, c.req))
+		if got != c.expect {
+			t.Errorf("case %q:\nwant: %s\ngot:  %s", c.description, c.expect, got)
+		}
+	}
+}
+
+func TestDoInvalid(t *testing.T) {
+	schema := testSchema(t)
+	cases := []struct {
+		description string
+		query       string
+		expect      string
+	}{
+		{"unknown fields", `{ book(title: "a") { isbn } }`, `Cannot query field "isbn" on type "Book"`},
+		{"missing required arguments", `{ book { title } }`, `argument "title" of type "String!" is required`},
+		{"bad argument types", `{ books(limit: "ten") { title } }`, `Argument "limit" has invalid value "ten"`},
+		{"object fields need selections", `{ book(title: "a") { author } }`, `must have a sub selection`},
+		{"fragment cycles", `{ book(title: "a") { ...a } } fragment a on Book { ...b } fragment b on Book { ...a }`, `Cannot spread fragment "a" within itself`},
+		{"syntax errors", `{ books { title }`, `Syntax Error`},
+	}
+
+	for _, c := range cases {
+		res := Do(context.Background(), schema, Request{Query: c.query})
+		if res.Data != nil || len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, c.expect) {
+			t.Errorf("case %q: expected an error containing %q and no data, got %s", c.description, c.expect, mustJSON(t, res))
+		}
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	schema := testSchema(t)
+	res := Do(context.Background(), schema, Request{Query: `{ __type(name: "Book") { name fields { name type { name kind ofType { name } } } } }`}, func(o *Options) {
+		o.MaxDepth = 2
+		o.MaxCost = 20
+	})
+	if len(res.Errors) != 0 {
+		t.Fatalf("unexpected errors: %s", mustJSON(t, res))
+	}
+	typ := res.Data.(map[string]interface{})["__type"].(map[string]interface{})
+	if typ["name"] != "Book" || len(typ["fields"].([]interface{})) != 5 {
+		t.Errorf("expected the Book type with 5 fields, got %s", mustJSON(t, res))
+	}
+}
+
+func TestLimits(t *testing.T) {
+	schema := testSchema(t)
+	limits := func(o *Options) {
+		o.MaxDepth = 2
+		o.MaxCost = 20
+	}
+	costErr := "query cost exceeds the limit of 20"
+
+	cases := []struct {
+		description string
+		req         Request
+		expect      string
+	}{
+		{"too deep", Request{Query: `{ book(title: "a") { author { name } } }`}, "query exceeds the maximum depth of 2"},
+		// 1 for books + 5 books * 2 fields
+		{"within the cost limit", Request{Query: `{ books(limit: 5) { title pages } }`}, ""},
+		{"over the cost limit", Request{Query: `{ books(limit: 10) { title pages } }`}, costErr},
+		{"limits from variables", Request{Query: `query ($n: Int) { books(limit: $n) { title pages } }`, Variables: map[string]interface{}{"n": float64(10)}}, costErr},
+		{"limits from argument defaults", Request{Query: `{ books { title pages } }`}, costErr},
+		// list fields without a limit argument are assumed to be DefaultListSize
+		{"default list size", Request{Query: `{ authors { name } }`}, costErr},
+		{"field costs", Request{Query: `{ a: book(title: "a") { title } b: book(title: "b") { title } c: book(title: "c") { title } d: book(title: "a") { title } }`}, costErr},
+		{"huge limits saturate", Request{Query: `{ books(limit: 2147483647) { title } }`}, costErr},
+	}
+
+	for _, c := range cases {
+		res := Do(context.Background(), schema, c.req, limits)
+		if c.expect == "" {
+			if len(res.Errors) != 0 {
+				t.Errorf("case %q: expected no errors, got %s", c.description, mustJSON(t, res))
+			}
+			continue
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Message != c.expect {
+			t.Errorf("case %q: expected error %q, got %s", c.description, c.expect, mustJSON(t, res))
+		}
+	}
+}
+
+func TestLimitsOverflow(t *testing.T) {
+	schema := testSchema(t)
+	limits := func(o *Options) { o.MaxCost = 1000 }
+
+	// multiplying large limits must not wrap around to a small cost
+	res := Do(context.Background(), schema, Request{Query: `{ books(limit: 2147483647) { author { name } } authors { name } }`}, limits)
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "query cost exceeds") {
+		t.Errorf("expected a cost error, got %s", mustJSON(t, res))
+	}
+
+	// each fragment spreads the one before it twice, doubling the cost. Walking
+	// every spread would take 2^30 steps
+	query := &strings.Builder{}
+	query.WriteString(`{ book(title: "a") { ...f30 } } fragment f0 on Book { title }`)
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(query, " fragment f%d on Book { ...f%d ...f%d }", i, i-1, i-1)
+	}
+	start := time.Now()
+	res = Do(context.Background(), schema, Request{Query: query.String()}, limits)
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "query cost exceeds") {
+		t.Errorf("expected a cost error, got %s", mustJSON(t, res))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected fragment costs to be cached, estimating took %s", elapsed)
+	}
+}
+
+func TestDefaultResolve(t *testing.T) {
+	type embedded struct {
+		Inner string `json:"inner"`
+	}
+	type outer struct {
+		embedded
+		Name    string `json:"name"`
+		NoTag   int
+		private string
+	}
+
+	src := &outer{embedded: embedded{Inner: "in"}, Name: "n", NoTag: 5, private: "p"}
+	cases := []struct {
+		name   string
+		expect interface{}
+	}{
+		{"name", "n"},
+		{"inner", "in"},
+		{"noTag", 5},
+		{"private", nil},
+		{"missing", nil},
+	}
+	for _, c := range cases {
+		if got := resolveField(src, c.name); got != c.expect {
+			t.Errorf("field %q: expected %v, got %v", c.name, c.expect, got)
+		}
+	}
+
+	if got := resolveField(map[string]interface{}{"a": 1}, "a"); got != 1 {
+		t.Errorf("expected map lookup to return 1, got %v", got)
+	}
+}
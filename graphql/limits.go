@@ -0,0 +1,257 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxEstimate caps cost estimates & list sizes, arithmetic on estimates
+// saturates here instead of overflowing
+const maxEstimate = math.MaxInt32
+
+// limitError is a depth or cost error at a position in the query
+type limitError struct {
+	msg  string
+	node ast.Node
+}
+
+func (e *limitError) Error() string { return e.msg }
+
+// estimate is the cost & depth of a selection set
+type estimate struct {
+	cost, depth int
+}
+
+// limits estimates the cost of an operation. It runs before graphql-go
+// validates the document, so unknown fields & fragments are skipped rather
+// than reported
+type limits struct {
+	schema *Schema
+	vars   map[string]interface{}
+	opts   *Options
+	frags  map[string]*ast.FragmentDefinition
+	// fragment estimates are cached so each fragment is walked once, no matter
+	// how many times it's spread
+	cache    map[string]estimate
+	visiting map[string]bool
+}
+
+func checkLimits(schema *Schema, doc *ast.Document, req Request, o *Options) error {
+	if o.MaxDepth <= 0 && o.MaxCost <= 0 {
+		return nil
+	}
+
+	l := &limits{
+		schema:   schema,
+		vars:     map[string]interface{}{},
+		opts:     o,
+		frags:    map[string]*ast.FragmentDefinition{},
+		cache:    map[string]estimate{},
+		visiting: map[string]bool{},
+	}
+	var ops []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.OperationDefinition:
+			ops = append(ops, d)
+		case *ast.FragmentDefinition:
+			l.frags[d.Name.Value] = d
+		}
+	}
+
+	for _, op := range ops {
+		if req.OperationName != "" && (op.Name == nil || op.Name.Value != req.OperationName) {
+			continue
+		}
+		if op.Operation != ast.OperationTypeQuery {
+			// graphql-go reports operations the schema doesn't support
+			continue
+		}
+		l.setVars(op, req.Variables)
+		est, err := l.selections(schema.QueryType(), op.SelectionSet)
+		if err != nil {
+			return err
+		}
+		if o.MaxDepth > 0 && est.depth > o.MaxDepth {
+			return gql.NewLocatedError(fmt.Sprintf("query exceeds the maximum depth of %d", o.MaxDepth), []ast.Node{op})
+		}
+	}
+	return nil
+}
+
+// setVars collects the variables of an operation, with defaults
+func (l *limits) setVars(op *ast.OperationDefinition, input map[string]interface{}) {
+	l.vars = map[string]interface{}{}
+	for _, vd := range op.VariableDefinitions {
+		name := vd.Variable.Name.Value
+		if v, ok := input[name]; ok {
+			l.vars[name] = v
+		} else if vd.DefaultValue != nil {
+			l.vars[name] = literalValue(vd.DefaultValue)
+		}
+	}
+}
+
+// selections estimates the cost of a selection set. List fields multiply the
+// cost of their selections by their "limit" argument. An estimate over the
+// cost limit returns an error as soon as it's found
+func (l *limits) selections(t gql.Type, set *ast.SelectionSet) (est estimate, err error) {
+	obj, ok := t.(*gql.Object)
+	if !ok || set == nil {
+		return est, nil
+	}
+
+	for _, sel := range set.Selections {
+		var sub estimate
+		switch s := sel.(type) {
+		case *ast.Field:
+			if sub, err = l.field(obj, s); err != nil {
+				return est, err
+			}
+		case *ast.FragmentSpread:
+			if sub, err = l.fragment(s.Name.Value); err != nil {
+				return est, err
+			}
+		case *ast.InlineFragment:
+			on := t
+			if s.TypeCondition != nil {
+				on = l.schema.Type(s.TypeCondition.Name.Value)
+			}
+			if sub, err = l.selections(on, s.SelectionSet); err != nil {
+				return est, err
+			}
+		}
+
+		est.cost = satAdd(est.cost, sub.cost)
+		if sub.depth > est.depth {
+			est.depth = sub.depth
+		}
+		if l.opts.MaxCost > 0 && est.cost > l.opts.MaxCost {
+			return est, gql.NewLocatedError(fmt.Sprintf("query cost exceeds the limit of %d", l.opts.MaxCost), []ast.Node{sel.(ast.Node)})
+		}
+	}
+	return est, nil
+}
+
+func (l *limits) field(obj *gql.Object, f *ast.Field) (estimate, error) {
+	name := f.Name.Value
+	if strings.HasPrefix(name, "__") {
+		// introspection is bounded by the size of the schema
+		return estimate{cost: 1, depth: 1}, nil
+	}
+	fd := obj.Fields()[name]
+	if fd == nil {
+		return estimate{cost: 1, depth: 1}, nil
+	}
+
+	cost := 1
+	if c, ok := l.schema.Costs[obj.Name()+"."+name]; ok {
+		cost = c
+	}
+	sub, err := l.selections(namedType(fd.Type), f.SelectionSet)
+	if err != nil {
+		return estimate{}, err
+	}
+	if isList(fd.Type) {
+		sub.cost = satMul(sub.cost, l.listSize(fd, f))
+	}
+	return estimate{cost: satAdd(cost, sub.cost), depth: sub.depth + 1}, nil
+}
+
+// fragment estimates a named fragment, caching the result. Do rejects
+// fragments that spread themselves before estimating, visiting guards
+// against them anyway
+func (l *limits) fragment(name string) (estimate, error) {
+	if est, ok := l.cache[name]; ok {
+		return est, nil
+	}
+	frag := l.frags[name]
+	if frag == nil || l.visiting[name] {
+		return estimate{}, nil
+	}
+
+	l.visiting[name] = true
+	est, err := l.selections(l.schema.Type(frag.TypeCondition.Name.Value), frag.SelectionSet)
+	delete(l.visiting, name)
+	if err != nil {
+		return est, err
+	}
+	l.cache[name] = est
+	return est, nil
+}
+
+// listSize is the number of items a list field is expected to resolve: its
+// "limit" argument, clamped to maxEstimate, or DefaultListSize
+func (l *limits) listSize(fd *gql.FieldDefinition, f *ast.Field) int {
+	var limit interface{}
+	for _, arg := range fd.Args {
+		if arg.Name() == "limit" {
+			limit = arg.DefaultValue
+		}
+	}
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		if v, ok := arg.Value.(*ast.Variable); ok {
+			limit = l.vars[v.Name.Value]
+		} else {
+			limit = literalValue(arg.Value)
+		}
+	}
+
+	var size float64
+	switch x := limit.(type) {
+	case int:
+		size = float64(x)
+	case float64:
+		size = x
+	}
+	if size <= 0 {
+		return l.opts.DefaultListSize
+	}
+	if size > maxEstimate {
+		return maxEstimate
+	}
+	return int(size)
+}
+
+// namedType strips list & non-null wrappers from a type
+func namedType(t gql.Type) gql.Type {
+	for {
+		switch x := t.(type) {
+		case *gql.NonNull:
+			t = x.OfType
+		case *gql.List:
+			t = x.OfType
+		default:
+			return t
+		}
+	}
+}
+
+func isList(t gql.Type) bool {
+	if nn, ok := t.(*gql.NonNull); ok {
+		t = nn.OfType
+	}
+	_, ok := t.(*gql.List)
+	return ok
+}
+
+func satAdd(a, b int) int {
+	if a > maxEstimate-b {
+		return maxEstimate
+	}
+	return a + b
+}
+
+func satMul(a, b int) int {
+	if a != 0 && b > maxEstimate/a {
+		return maxEstimate
+	}
+	return a * b
+}
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/errors"
+)
+
+// APITokenMethods manages tokens that authorize requests to the JSON API
+type APITokenMethods struct {
+	inst *Instance
+}
+
+// NewAPITokenMethods creates APITokenMethods from a qri Instance
+func NewAPITokenMethods(inst *Instance) *APITokenMethods {
+	return &APITokenMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m APITokenMethods) CoreRequestsName() string { return "apitoken" }
+
+// CreateAPITokenParams defines parameters for minting an api token
+type CreateAPITokenParams struct {
+	// human-readable description of what the token is for
+	Name string
+	// one of read, write, publish, admin
+	Scope string
+	// Profile is the peername of a tenant the token acts as. Tenant tokens
+	// can't have admin scope
+	Profile string
+}
+
+// CreateAPITokenResult holds a newly minted api token
+type CreateAPITokenResult struct {
+	// Secret is the token to send as a bearer token. It can't be recovered
+	// later
+	Secret string
+	Token  apitoken.Token
+}
+
+// Create mints a new api token
+func (m *APITokenMethods) Create(p *CreateAPITokenParams, res *CreateAPITokenResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("APITokenMethods.Create", p, res))
+	}
+	if p.Name == "" {
+		return errors.New(ErrBadArgs, "token name is required")
+	}
+	scope, err := apitoken.ParseScope(p.Scope)
+	if err != nil {
+		return errors.New(ErrBadArgs, err.Error())
+	}
+	store, err := m.store()
+	if err != nil {
+		return err
+	}
+	if p.Profile != "" && p.Profile != m.inst.cfg.Profile.Peername {
+		if scope.Allows(apitoken.ScopeAdmin) {
+			return errors.New(ErrBadArgs, "tenant tokens can't have admin scope")
+		}
+		if _, err := m.inst.Tenant(p.Profile); err != nil {
+			return err
+		}
+		res.Secret, res.Token, err = store.CreateForProfile(p.Name, scope, p.Profile)
+		return err
+	}
+	res.Secret, res.Token, err = store.Create(p.Name, scope)
+	return err
+}
+
+// List returns all api tokens. Token secrets aren't included
+func (m *APITokenMethods) List(p *bool, res *[]apitoken.Token) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("APITokenMethods.List", p, res))
+	}
+	store, err := m.store()
+	if err != nil {
+		return err
+	}
+	*res = store.List()
+	return nil
+}
+
+// Revoke removes the api token with the given id
+func (m *APITokenMethods) Revoke(id *string, res *bool) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("APITokenMethods.Revoke", id, res))
+	}
+	store, err := m.store()
+	if err != nil {
+		return err
+	}
+	if err := store.Revoke(*id); err != nil {
+		return err
+	}
+	*res = true
+	return nil
+}
+
+func (m *APITokenMethods) store() (*apitoken.Store, error) {
+	if m.inst.tenant != "" {
+		return nil, fmt.Errorf("api tokens are managed by the host")
+	}
+	if m.inst.apiTokens == nil {
+		return nil, fmt.Errorf("api tokens are not available")
+	}
+	return m.inst.apiTokens, nil
+}
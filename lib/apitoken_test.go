@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/apitoken"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/p2p"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestAPITokenMethods(t *testing.T) {
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, config.DefaultP2PForTesting())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	m := NewAPITokenMethods(inst)
+
+	bad := []struct {
+		p   CreateAPITokenParams
+		err string
+	}{
+		{CreateAPITokenParams{Scope: "read"}, "token name is required"},
+		{CreateAPITokenParams{Name: "ci", Scope: "root"}, `invalid scope "root", must be one of read, write, publish, admin`},
+	}
+	for i, c := range bad {
+		res := CreateAPITokenResult{}
+		if err := m.Create(&c.p, &res); err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+
+	res := CreateAPITokenResult{}
+	if err := m.Create(&CreateAPITokenParams{Name: "ci", Scope: "write"}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Secret == "" {
+		t.Fatal("expected a token secret")
+	}
+	if _, err := inst.APITokens().Verify(res.Secret); err != nil {
+		t.Errorf("expected created token to verify: %s", err)
+	}
+
+	list := []apitoken.Token{}
+	if err := m.List(nil, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != res.Token.ID {
+		t.Errorf("unexpected token list: %v", list)
+	}
+
+	revoked := false
+	if err := m.Revoke(&res.Token.ID, &revoked); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.APITokens().Verify(res.Secret); err == nil {
+		t.Error("expected revoked token not to verify")
+	}
+}
@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/watchfs"
+)
+
+// DefaultAutoSaveQuietPeriod is how long a working directory must go without
+// changes before it's automatically saved
+const DefaultAutoSaveQuietPeriod = 5 * time.Second
+
+// AutoSaveParams configures automatically saving a linked working directory
+type AutoSaveParams struct {
+	// reference to a dataset that's linked to a working directory
+	Ref string
+	// how long the working directory must go without changes before saving,
+	// defaults to DefaultAutoSaveQuietPeriod
+	QuietPeriod time.Duration
+	// only save if the body validates against the structure schema
+	RequireValid bool
+	// OnResult is called after each attempt to save, optional
+	OnResult func(AutoSaveResult)
+}
+
+// AutoSaveResult describes a single attempt to automatically save changes
+type AutoSaveResult struct {
+	// reference to the saved version, empty if nothing was saved
+	Ref reporef.DatasetRef
+	// why the working directory wasn't saved. empty if the save succeeded
+	Skipped string
+	// error encountered while saving
+	Err error
+}
+
+// AutoSave watches the working directory of a linked dataset, saving changes
+// once the directory has been quiet for the quiet period. Working directories
+// with parse errors or conflicts aren't saved. AutoSave runs until the context
+// is cancelled or the working directory is removed. AutoSave doesn't work
+// over RPC
+func (m *FSIMethods) AutoSave(ctx context.Context, p *AutoSaveParams) error {
+	if m.inst.rpc != nil {
+		return fmt.Errorf("auto-save can't run while another qri process holds the repo. stop `qri connect` and try again")
+	}
+	if m.inst.fsi == nil {
+		return fmt.Errorf("file system integration is not enabled")
+	}
+
+	ref, err := repo.ParseDatasetRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil && err != repo.ErrNoHistory {
+		return err
+	}
+	if ref.FSIPath == "" {
+		return fmt.Errorf("%s is not linked to a working directory", ref.AliasString())
+	}
+
+	quiet := p.QuietPeriod
+	if quiet <= 0 {
+		quiet = DefaultAutoSaveQuietPeriod
+	}
+	report := p.OnResult
+	if report == nil {
+		report = func(AutoSaveResult) {}
+	}
+
+	w := watchfs.NewFilesysWatcher(ctx, nil)
+	defer w.Watcher.Close()
+	w.FollowMoves(m.inst.fsi)
+	events := w.Begin([]watchfs.EventPath{{
+		Path:     ref.FSIPath,
+		Username: ref.Peername,
+		Dsname:   ref.Name,
+	}})
+
+	timer := time.NewTimer(quiet)
+	timer.Stop()
+	defer timer.Stop()
+
+	dir := ref.FSIPath
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-events:
+			switch e.Type {
+			case watchfs.RemoveFolderEvent:
+				return fmt.Errorf("working directory %q was removed", dir)
+			case watchfs.RenameFolderEvent:
+				dir = e.Destination
+			default:
+				// saving rewrites link files, which shouldn't trigger another save
+				if name := filepath.Base(e.Source); name == fsi.QriRefFilename || name == fsi.LinkMetaFilename {
+					continue
+				}
+				timer.Reset(quiet)
+			}
+		case <-timer.C:
+			report(m.autoSave(ctx, ref.AliasString(), dir, p.RequireValid))
+		}
+	}
+}
+
+// autoSave saves the working directory of a dataset if it has changes that
+// can be saved
+func (m *FSIMethods) autoSave(ctx context.Context, alias, dir string, requireValid bool) AutoSaveResult {
+	status, err := m.inst.fsi.Status(ctx, dir)
+	if err != nil {
+		return AutoSaveResult{Err: err}
+	}
+
+	changed := false
+	for _, si := range status {
+		switch si.Type {
+		case fsi.STUnmodified:
+		case fsi.STAdd, fsi.STChange, fsi.STRemoved:
+			changed = true
+		default:
+			// parse errors, conflicts & other problems reading files
+			return AutoSaveResult{Skipped: fmt.Sprintf("%s: %s", si.Component, si.Type)}
+		}
+	}
+	if !changed {
+		return AutoSaveResult{Skipped: "no changes"}
+	}
+
+	dsm := NewDatasetMethods(m.inst)
+	if requireValid {
+		valerrs := []jsonschema.ValError{}
+		if err := dsm.Validate(&ValidateDatasetParams{Ref: alias}, &valerrs); err != nil {
+			return AutoSaveResult{Err: err}
+		}
+		if len(valerrs) > 0 {
+			return AutoSaveResult{Skipped: fmt.Sprintf("%d validation errors", len(valerrs))}
+		}
+	}
+
+	res := reporef.DatasetRef{}
+	if err := dsm.Save(&SaveParams{Ref: alias}, &res); err != nil {
+		return AutoSaveResult{Err: err}
+	}
+	return AutoSaveResult{Ref: res}
+}
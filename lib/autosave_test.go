@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/p2p"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestFSIMethodsAutoSave(t *testing.T) {
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, config.DefaultP2PForTesting())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	methods := NewFSIMethods(inst)
+
+	tmpDir, err := ioutil.TempDir("", "QriTestFSIMethodsAutoSave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dir := filepath.Join(tmpDir, "cities")
+	var out string
+	if err := methods.Checkout(&CheckoutParams{Dir: dir, Ref: "me/cities"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := methods.AutoSave(context.Background(), &AutoSaveParams{Ref: "me/not_linked"}); err == nil {
+		t.Error("expected auto-saving an unlinked dataset to error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := make(chan AutoSaveResult, 10)
+	done := make(chan error)
+	go func() {
+		done <- methods.AutoSave(ctx, &AutoSaveParams{
+			Ref:         "me/cities",
+			QuietPeriod: 200 * time.Millisecond,
+			OnResult:    func(res AutoSaveResult) { results <- res },
+		})
+	}()
+	// give the watcher a moment to start
+	time.Sleep(100 * time.Millisecond)
+
+	next := func() AutoSaveResult {
+		select {
+		case res := <-results:
+			return res
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for auto-save")
+		}
+		return AutoSaveResult{}
+	}
+
+	// parse errors aren't saved
+	metaPath := filepath.Join(dir, "meta.json")
+	if err := ioutil.WriteFile(metaPath, []byte(`{"title":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res := next(); res.Skipped == "" || !res.Ref.IsEmpty() {
+		t.Errorf("expected a parse error to skip saving, got: %#v", res)
+	}
+
+	if err := ioutil.WriteFile(metaPath, []byte(`{"title":"auto-saved"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res := next()
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %s", res.Err)
+	}
+	if res.Ref.Path == "" {
+		t.Errorf("expected a saved version, got: %#v", res)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected cancelling to stop without error, got: %s", err)
+	}
+}
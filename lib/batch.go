@@ -0,0 +1,213 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/qri-io/qri/dsref"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// DefaultBatchConcurrency is the number of batch operations run at once when
+// BatchParams doesn't specify. Only publishes run alongside each other, saves,
+// removes & renames write to the repo one at a time
+const DefaultBatchConcurrency = 4
+
+// Batch operation names
+const (
+	BatchOpSave    = "save"
+	BatchOpRemove  = "remove"
+	BatchOpRename  = "rename"
+	BatchOpPublish = "publish"
+)
+
+// ErrBatchSkipped is the error of operations that don't run because an earlier
+// operation failed with StopOnError set
+var ErrBatchSkipped = fmt.Errorf("skipped because an earlier operation failed")
+
+// BatchOp is a single operation in a batch. Op names the operation, and the
+// matching params field must be set
+type BatchOp struct {
+	Op      string             `json:"op"`
+	Save    *SaveParams        `json:"save,omitempty"`
+	Remove  *RemoveParams      `json:"remove,omitempty"`
+	Rename  *RenameParams      `json:"rename,omitempty"`
+	Publish *PublicationParams `json:"publish,omitempty"`
+}
+
+// BatchParams configures a batch of dataset operations
+type BatchParams struct {
+	Ops []BatchOp `json:"ops"`
+	// Concurrency is the number of operations run at once, defaults to
+	// DefaultBatchConcurrency. Operations that write to the repo never overlap,
+	// but may start out of order. Operations on the same dataset should set
+	// Concurrency to 1 so they run in order
+	Concurrency int `json:"concurrency,omitempty"`
+	// StopOnError skips operations that haven't started once one fails.
+	// Operations already running finish
+	StopOnError bool `json:"stopOnError,omitempty"`
+}
+
+// BatchResult is the outcome of a single batch operation. The result field
+// matching Op is set when the operation succeeds
+type BatchResult struct {
+	Op      string              `json:"op"`
+	Ref     string              `json:"ref,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	Skipped bool                `json:"skipped,omitempty"`
+	Save    *reporef.DatasetRef `json:"save,omitempty"`
+	Remove  *RemoveResponse     `json:"remove,omitempty"`
+	Rename  *dsref.VersionInfo  `json:"rename,omitempty"`
+	Publish *dsref.Ref          `json:"publish,omitempty"`
+}
+
+// Batch runs a list of save, remove, rename & publish operations with bounded
+// concurrency. res holds a result for each operation, in the order given.
+// Failed operations don't fail the batch, check each result's Error
+func (m *DatasetMethods) Batch(p *BatchParams, res *[]BatchResult) error {
+	return m.BatchContext(context.TODO(), p, res)
+}
+
+// BatchContext is Batch with a context. Operations that haven't started when
+// ctx is cancelled are skipped
+func (m *DatasetMethods) BatchContext(ctx context.Context, p *BatchParams, res *[]BatchResult) error {
+	if len(p.Ops) == 0 {
+		return fmt.Errorf("batch has no operations")
+	}
+	// batched saves run concurrently, so can't share script output
+	ops := make([]BatchOp, len(p.Ops))
+	for i, op := range p.Ops {
+		if op.Save != nil && op.Save.ScriptOutput != nil {
+			sp := *op.Save
+			sp.ScriptOutput = nil
+			op.Save = &sp
+		}
+		ops[i] = op
+	}
+
+	if m.inst.rpc != nil {
+		params := *p
+		params.Ops = ops
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Batch", &params, res))
+	}
+
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > len(ops) {
+		concurrency = len(ops)
+	}
+
+	var (
+		results = make([]BatchResult, len(ops))
+		sem     = make(chan struct{}, concurrency)
+		wg      sync.WaitGroup
+		lk      sync.Mutex
+		failed  bool
+		// the refstore & logbook aren't safe for concurrent writes. writes hold
+		// repoLk. publishes only read them, and the refstore locks itself
+		repoLk sync.RWMutex
+	)
+	for i, op := range ops {
+		sem <- struct{}{}
+		lk.Lock()
+		skip := failed && p.StopOnError
+		lk.Unlock()
+		if skip || ctx.Err() != nil {
+			<-sem
+			results[i] = BatchResult{Op: op.Op, Ref: op.ref(), Skipped: true, Error: ErrBatchSkipped.Error()}
+			if ctx.Err() != nil {
+				results[i].Error = ctx.Err().Error()
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, op BatchOp) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if op.Op == BatchOpPublish {
+				repoLk.RLock()
+				defer repoLk.RUnlock()
+			} else {
+				repoLk.Lock()
+				defer repoLk.Unlock()
+			}
+			r := m.batchOp(ctx, op)
+			if r.Error != "" {
+				lk.Lock()
+				failed = true
+				lk.Unlock()
+			}
+			results[i] = r
+		}(i, op)
+	}
+	wg.Wait()
+
+	*res = results
+	return nil
+}
+
+// ref is the dataset an operation applies to
+func (op BatchOp) ref() string {
+	switch {
+	case op.Op == BatchOpSave && op.Save != nil:
+		return op.Save.Ref
+	case op.Op == BatchOpRemove && op.Remove != nil:
+		return op.Remove.Ref
+	case op.Op == BatchOpRename && op.Rename != nil:
+		return op.Rename.Current.Alias()
+	case op.Op == BatchOpPublish && op.Publish != nil:
+		return op.Publish.Ref
+	}
+	return ""
+}
+
+// batchOp runs a single operation
+func (m *DatasetMethods) batchOp(ctx context.Context, op BatchOp) BatchResult {
+	r := BatchResult{Op: op.Op, Ref: op.ref()}
+	var err error
+	switch op.Op {
+	case BatchOpSave:
+		if op.Save == nil {
+			break
+		}
+		r.Save = &reporef.DatasetRef{}
+		if err = m.SaveContext(ctx, op.Save, r.Save); err == nil {
+			r.Ref = r.Save.AliasString()
+		}
+	case BatchOpRemove:
+		if op.Remove == nil {
+			break
+		}
+		r.Remove = &RemoveResponse{}
+		err = m.RemoveContext(ctx, op.Remove, r.Remove)
+	case BatchOpRename:
+		if op.Rename == nil {
+			break
+		}
+		r.Rename = &dsref.VersionInfo{}
+		err = m.RenameContext(ctx, op.Rename, r.Rename)
+	case BatchOpPublish:
+		if op.Publish == nil {
+			break
+		}
+		r.Publish = &dsref.Ref{}
+		err = NewRemoteMethods(m.inst).PublishContext(ctx, op.Publish, r.Publish)
+	default:
+		err = fmt.Errorf("unknown batch operation %q", op.Op)
+	}
+	if err == nil && r.Save == nil && r.Remove == nil && r.Rename == nil && r.Publish == nil {
+		err = fmt.Errorf("%s operation is missing %s params", op.Op, op.Op)
+	}
+	if err != nil {
+		reqLog.Debugf(ctx, "batch %s %q: %s", op.Op, r.Ref, err)
+		r.Error = err.Error()
+		r.Save, r.Remove, r.Rename, r.Publish = nil, nil, nil, nil
+	}
+	return r
+}
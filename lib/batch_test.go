@@ -0,0 +1,116 @@
+package lib
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/p2p"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func newBatchTestMethods(t *testing.T) *DatasetMethods {
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, config.DefaultP2PForTesting())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	return NewDatasetMethods(inst)
+}
+
+func TestDatasetMethodsBatch(t *testing.T) {
+	m := newBatchTestMethods(t)
+
+	p := &BatchParams{
+		Ops: []BatchOp{
+			{Op: BatchOpRename, Rename: &RenameParams{
+				Current: dsref.Ref{Username: "peer", Name: "movies"},
+				Next:    dsref.Ref{Username: "peer", Name: "films"},
+			}},
+			{Op: BatchOpRemove, Remove: &RemoveParams{Ref: "peer/sitemap", Revision: dsref.NewAllRevisions()}},
+			{Op: BatchOpRemove},
+			{Op: "fold", Remove: &RemoveParams{Ref: "peer/cities"}},
+		},
+	}
+	res := []BatchResult{}
+	if err := m.Batch(p, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(p.Ops) {
+		t.Fatalf("expected %d results, got %d", len(p.Ops), len(res))
+	}
+
+	if res[0].Error != "" || res[0].Rename == nil || res[0].Rename.Name != "films" {
+		t.Errorf("expected rename to succeed, got %+v", res[0])
+	}
+	if res[1].Error != "" || res[1].Remove == nil || res[1].Ref != "peer/sitemap" {
+		t.Errorf("expected remove to succeed, got %+v", res[1])
+	}
+	if expect := "remove operation is missing remove params"; res[2].Error != expect {
+		t.Errorf("expected error %q, got %q", expect, res[2].Error)
+	}
+	if expect := `unknown batch operation "fold"`; res[3].Error != expect {
+		t.Errorf("expected error %q, got %q", expect, res[3].Error)
+	}
+
+	if err := m.Batch(&BatchParams{}, &res); err == nil {
+		t.Errorf("expected an empty batch to error")
+	}
+}
+
+func TestDatasetMethodsBatchStopOnError(t *testing.T) {
+	m := newBatchTestMethods(t)
+
+	p := &BatchParams{
+		Concurrency: 1,
+		StopOnError: true,
+		Ops: []BatchOp{
+			{Op: BatchOpRemove, Remove: &RemoveParams{Ref: "peer/not_a_dataset", Revision: dsref.NewAllRevisions()}},
+			{Op: BatchOpRemove, Remove: &RemoveParams{Ref: "peer/sitemap", Revision: dsref.NewAllRevisions()}},
+		},
+	}
+	res := []BatchResult{}
+	if err := m.Batch(p, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res[0].Error == "" || res[0].Skipped {
+		t.Errorf("expected first operation to fail, got %+v", res[0])
+	}
+	if !res[1].Skipped || res[1].Error != ErrBatchSkipped.Error() {
+		t.Errorf("expected second operation to be skipped, got %+v", res[1])
+	}
+}
+
+func TestDatasetMethodsBatchConcurrentSaves(t *testing.T) {
+	m := newBatchTestMethods(t)
+
+	p := &BatchParams{Concurrency: 8}
+	for i := 0; i < 8; i++ {
+		p.Ops = append(p.Ops, BatchOp{Op: BatchOpSave, Save: &SaveParams{
+			Ref:      fmt.Sprintf("me/batch_save_%d", i),
+			BodyPath: "testdata/jobs_by_automation/body.csv",
+		}})
+	}
+	res := []BatchResult{}
+	if err := m.Batch(p, &res); err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range res {
+		if r.Error != "" {
+			t.Errorf("save %d: %s", i, r.Error)
+		}
+	}
+
+	// every save must survive the others writing to the repo at the same time
+	for _, op := range p.Ops {
+		got := &GetResult{}
+		if err := m.Get(&GetParams{Refstr: op.Save.Ref}, got); err != nil {
+			t.Errorf("getting %s after the batch: %s", op.Save.Ref, err)
+		}
+	}
+}
@@ -2,11 +2,12 @@ package lib
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/rpc"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,8 @@ import (
 	"github.com/qri-io/dag"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/localfs"
@@ -24,12 +27,14 @@ import (
 	"github.com/qri-io/qri/dscache/build"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 	"github.com/qri-io/qri/resolver/loader"
+	"github.com/qri-io/qri/rpc"
 )
 
 // DatasetMethods encapsulates business logic for working with Datasets on Qri
@@ -52,11 +57,15 @@ func NewDatasetMethods(inst *Instance) *DatasetMethods {
 
 // List gets the reflist for either the local repo or a peer
 func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
+	return m.ListContext(context.TODO(), p, res)
+}
+
+// ListContext is List with a context
+func (m *DatasetMethods) ListContext(ctx context.Context, p *ListParams, res *[]dsref.VersionInfo) error {
 	if m.inst.rpc != nil {
 		p.RPC = true
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.List", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.List", p, res))
 	}
-	ctx := context.TODO()
 
 	// ensure valid limit value
 	if p.Limit <= 0 {
@@ -86,14 +95,14 @@ func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
 	if p.UseDscache {
 		c := m.inst.dscache
 		if c.IsEmpty() {
-			log.Infof("building dscache from repo's logbook, profile, and dsref")
+			reqLog.Infof(ctx, "building dscache from repo's logbook, profile, and dsref")
 			built, err := build.DscacheFromRepo(ctx, m.inst.repo)
 			if err != nil {
 				return err
 			}
 			err = c.Assign(built)
 			if err != nil {
-				log.Error(err)
+				reqLog.Errorf(ctx, "%s", err)
 			}
 		}
 		refs, err = c.ListRefs()
@@ -145,12 +154,12 @@ func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
 					ref.FSIPath = ""
 					if ref.Path == "" {
 						if err = m.inst.repo.DeleteRef(ref); err != nil {
-							log.Debugf("cannot delete ref for %q, err: %s", ref, err)
+							reqLog.Debugf(ctx, "cannot delete ref for %q, err: %s", ref, err)
 						}
 						continue
 					}
 					if err = m.inst.repo.PutRef(ref); err != nil {
-						log.Debugf("cannot put ref for %q, err: %s", ref, err)
+						reqLog.Debugf(ctx, "cannot put ref for %q, err: %s", ref, err)
 					}
 				}
 			}
@@ -170,11 +179,15 @@ func (m *DatasetMethods) List(p *ListParams, res *[]dsref.VersionInfo) error {
 
 // ListRawRefs gets the list of raw references as string
 func (m *DatasetMethods) ListRawRefs(p *ListParams, text *string) error {
+	return m.ListRawRefsContext(context.TODO(), p, text)
+}
+
+// ListRawRefsContext is ListRawRefs with a context
+func (m *DatasetMethods) ListRawRefsContext(ctx context.Context, p *ListParams, text *string) error {
 	var err error
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.ListRawRefs", p, text))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.ListRawRefs", p, text))
 	}
-	ctx := context.TODO()
 	if p.UseDscache {
 		c := m.inst.dscache
 		if c == nil || c.IsEmpty() {
@@ -220,66 +233,20 @@ type GetResult struct {
 // then res.Bytes is loaded with the body. If the selector is "stats", then res.Bytes is loaded
 // with the generated stats.
 func (m *DatasetMethods) Get(p *GetParams, res *GetResult) error {
-	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Get", p, res))
-	}
-	ctx := context.TODO()
-
-	// Check if the dataset ref uses bad-case characters, show a warning.
-	dr, err := dsref.Parse(p.Refstr)
-	if err == dsref.ErrBadCaseName {
-		log.Error(dsref.ErrBadCaseShouldRename)
-	}
-
-	var ds *dataset.Dataset
-	c := m.inst.dscache
-
-	if c.IsEmpty() {
-		// The old lookup path, using repo and refstore
-		ref, err := base.ToDatasetRef(p.Refstr, m.inst.repo, true)
-		if err != nil {
-			log.Debugf("Get dataset, base.ToDatasetRef %q failed, error: %s", p.Refstr, err)
-			return err
-		}
+	return m.GetContext(context.TODO(), p, res)
+}
 
-		if dr.Path == "" && ref.FSIPath != "" {
-			if ds, err = fsi.ReadDir(ref.FSIPath); err != nil {
-				log.Debugf("Get dataset, fsi.ReadDir %q failed, error: %s", ref.FSIPath, err)
-				return fmt.Errorf("loading linked dataset: %s", err)
-			}
-		} else {
-			ds, err = dsfs.LoadDataset(ctx, m.inst.repo.Store(), ref.Path)
-			if err != nil {
-				log.Debugf("Get dataset, dsfs.LoadDataset %q failed, error: %s", ref, err)
-				return fmt.Errorf("loading dataset: %s", err)
-			}
-		}
-		r := reporef.ConvertToDsref(*ref)
-		ds.Name = ref.Name
-		ds.Peername = ref.Peername
-		res.Ref = &r
-		res.Dataset = ds
-		res.FSIPath = ref.FSIPath
-		res.Published = ref.Published
-	} else {
-		// New lookup path, using dscache and resolver
-		rsolv := loader.NewDatasetResolver(c, m.inst.repo.Store())
-		loadedDs, initID, ref, info, err := rsolv.LoadDsref(ctx, p.Refstr)
-		if err != nil {
-			return fmt.Errorf("loading dataset: %s", err)
-		}
-		ds = loadedDs
-		res.Ref = &ref
-		res.Dataset = ds
-		res.FSIPath = info.FSIPath
-		res.Published = info.Published
-		_ = initID
+// GetContext is Get with a context
+func (m *DatasetMethods) GetContext(ctx context.Context, p *GetParams, res *GetResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Get", p, res))
 	}
 
-	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
-		log.Debugf("Get dataset, base.OpenDataset failed, error: %s", err)
+	dr, err := m.loadDataset(ctx, p.Refstr, res)
+	if err != nil {
 		return err
 	}
+	ds := res.Dataset
 
 	if p.Selector == "body" {
 		// `qri get body` loads the body
@@ -288,23 +255,27 @@ func (m *DatasetMethods) Get(p *GetParams, res *GetResult) error {
 		}
 		df, err := dataset.ParseDataFormatString(p.Format)
 		if err != nil {
-			log.Debugf("Get dataset, ParseDataFormatString %q failed, error: %s", p.Format, err)
+			reqLog.Debugf(ctx, "Get dataset, ParseDataFormatString %q failed, error: %s", p.Format, err)
 			return err
 		}
 
-		if dr.Path == "" && res.FSIPath != "" {
+		workingBody, err := m.readsWorkingBody(dr, res.FSIPath)
+		if err != nil {
+			return err
+		}
+		if workingBody {
 			// TODO(dustmop): Need to handle the special case where an FSI directory has a body
 			// but no structure, which should infer a schema in order to read the body. Once that
 			// works we can remove the fsi.GetBody call and just use base.ReadBody.
 			res.Bytes, err = fsi.GetBody(res.FSIPath, df, p.FormatConfig, p.Offset, p.Limit, p.All)
 			if err != nil {
-				log.Debugf("Get dataset, fsi.GetBody %q failed, error: %s", res.FSIPath, err)
+				reqLog.Debugf(ctx, "Get dataset, fsi.GetBody %q failed, error: %s", res.FSIPath, err)
 				return err
 			}
 		} else {
 			res.Bytes, err = base.ReadBody(ds, df, p.FormatConfig, p.Limit, p.Offset, p.All)
 			if err != nil {
-				log.Debugf("Get dataset, base.ReadBody %q failed, error: %s", ds, err)
+				reqLog.Debugf(ctx, "Get dataset, base.ReadBody %q failed, error: %s", ds, err)
 				return err
 			}
 		}
@@ -318,7 +289,7 @@ func (m *DatasetMethods) Get(p *GetParams, res *GetResult) error {
 			Dataset: res.Dataset,
 		}
 		statsRes := &StatsResponse{}
-		if err = m.Stats(statsParams, statsRes); err != nil {
+		if err = m.StatsContext(ctx, statsParams, statsRes); err != nil {
 			return err
 		}
 		res.Bytes = statsRes.StatsBytes
@@ -359,6 +330,168 @@ func (m *DatasetMethods) Get(p *GetParams, res *GetResult) error {
 	}
 }
 
+// loadDataset resolves refstr and opens the dataset it refers to, reading from
+// a linked working directory when refstr doesn't specify a version
+func (m *DatasetMethods) loadDataset(ctx context.Context, refstr string, res *GetResult) (dsref.Ref, error) {
+	// Check if the dataset ref uses bad-case characters, show a warning.
+	dr, err := dsref.Parse(refstr)
+	if err == dsref.ErrBadCaseName {
+		reqLog.Errorf(ctx, "%s", dsref.ErrBadCaseShouldRename)
+	}
+
+	var ds *dataset.Dataset
+	c := m.inst.dscache
+
+	if c.IsEmpty() {
+		// The old lookup path, using repo and refstore
+		ref, err := base.ToDatasetRef(refstr, m.inst.repo, true)
+		if err != nil {
+			reqLog.Debugf(ctx, "Get dataset, base.ToDatasetRef %q failed, error: %s", refstr, err)
+			return dr, err
+		}
+
+		if dr.Path == "" && ref.FSIPath != "" {
+			if ds, err = fsi.ReadWorkingDataset(ctx, ref.FSIPath, m.inst.repo.Store(), ref.Path); err != nil {
+				reqLog.Debugf(ctx, "Get dataset, fsi.ReadWorkingDataset %q failed, error: %s", ref.FSIPath, err)
+				return dr, fmt.Errorf("loading linked dataset: %s", err)
+			}
+		} else {
+			ds, err = dsfs.LoadDataset(ctx, m.inst.repo.Store(), ref.Path)
+			if err != nil {
+				reqLog.Debugf(ctx, "Get dataset, dsfs.LoadDataset %q failed, error: %s", ref, err)
+				return dr, fmt.Errorf("loading dataset: %s", err)
+			}
+		}
+		r := reporef.ConvertToDsref(*ref)
+		ds.Name = ref.Name
+		ds.Peername = ref.Peername
+		res.Ref = &r
+		res.Dataset = ds
+		res.FSIPath = ref.FSIPath
+		res.Published = ref.Published
+	} else {
+		// New lookup path, using dscache and resolver
+		rsolv := loader.NewDatasetResolver(c, m.inst.repo.Store())
+		loadedDs, initID, ref, info, err := rsolv.LoadDsref(ctx, refstr)
+		if err != nil {
+			return dr, fmt.Errorf("loading dataset: %s", err)
+		}
+		ds = loadedDs
+		res.Ref = &ref
+		res.Dataset = ds
+		res.FSIPath = info.FSIPath
+		res.Published = info.Published
+		_ = initID
+	}
+
+	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
+		reqLog.Debugf(ctx, "Get dataset, base.OpenDataset failed, error: %s", err)
+		return dr, err
+	}
+
+	return dr, nil
+}
+
+// readsWorkingBody reports whether the body of a loaded dataset should be read
+// from the body file in its working directory. Bodies that weren't checked out
+// in full come from the stored version instead
+func (m *DatasetMethods) readsWorkingBody(dr dsref.Ref, fsiPath string) (bool, error) {
+	if dr.Path != "" || fsiPath == "" {
+		return false, nil
+	}
+	return fsi.HasWorkingBody(fsiPath)
+}
+
+// BodyReader reads the entries of a dataset body
+type BodyReader struct {
+	dsio.EntryReader
+	// Dataset the body belongs to
+	Dataset *dataset.Dataset
+	// FSIPath is set when the body is read from a working directory
+	FSIPath string
+	// Version identifies the body being read: the path of a stored body,
+	// combined with the size & modification time of a working directory's
+	// body file, so it changes whenever the body does
+	Version string
+
+	file qfs.File
+}
+
+// Close closes both the entry reader and the underlying body file
+func (r *BodyReader) Close() error {
+	err := r.EntryReader.Close()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// OpenBody loads a dataset and opens a reader on its body, for callers that
+// stream entries instead of reading a whole page into memory. Callers must
+// close the returned reader. OpenBody doesn't work over RPC
+func (m *DatasetMethods) OpenBody(ctx context.Context, refstr string) (*BodyReader, error) {
+	if m.inst.rpc != nil {
+		return nil, fmt.Errorf("streaming a dataset body isn't supported over RPC")
+	}
+
+	res := &GetResult{}
+	dr, err := m.loadDataset(ctx, refstr, res)
+	if err != nil {
+		return nil, err
+	}
+	ds := res.Dataset
+
+	var (
+		file qfs.File
+		st   *dataset.Structure
+	)
+	workingBody, err := m.readsWorkingBody(dr, res.FSIPath)
+	if err != nil {
+		return nil, err
+	}
+	if workingBody {
+		// working directories may lack a structure, fsi.OpenBody detects one
+		if f := ds.BodyFile(); f != nil {
+			f.Close()
+		}
+		if file, st, err = fsi.OpenBody(res.FSIPath); err != nil {
+			return nil, err
+		}
+	} else {
+		if file = ds.BodyFile(); file == nil {
+			return nil, fmt.Errorf("no body file to read")
+		}
+		if st = ds.Structure; st == nil {
+			file.Close()
+			return nil, fmt.Errorf("dataset has no structure")
+		}
+	}
+
+	version := ds.BodyPath
+	if res.FSIPath != "" {
+		fileVersion, err := fsi.BodyFileVersion(res.FSIPath)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		version = fmt.Sprintf("%s:%s", version, fileVersion)
+	}
+
+	er, err := dsio.NewEntryReader(st, file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error allocating data reader: %s", err)
+	}
+
+	return &BodyReader{
+		EntryReader: er,
+		Dataset:     ds,
+		FSIPath:     res.FSIPath,
+		Version:     version,
+		file:        file,
+	}, nil
+}
+
 func scriptFileSelection(ds *dataset.Dataset, selector string) (qfs.File, bool) {
 	parts := strings.Split(selector, ".")
 	if len(parts) != 2 {
@@ -397,8 +530,8 @@ type SaveParams struct {
 	FilePaths []string
 	// secrets for transform execution
 	Secrets map[string]string
-	// optional writer to have transform script record standard output to
-	// note: this won't work over RPC, only on local calls
+	// optional writer to have transform script record standard output to.
+	// Over RPC, output is streamed from the daemon
 	ScriptOutput io.Writer
 
 	// Replace writes the entire given dataset as a new snapshot instead of
@@ -427,6 +560,15 @@ type SaveParams struct {
 	NewName bool
 	// whether to create a new dscache if none exists
 	UseDscache bool
+	// record http requests made by the transform into a fixture stored with
+	// the transform component
+	RecordHTTP bool
+	// re-run the transform offline against the http fixture recorded with the
+	// previous version
+	ReplayHTTP bool
+	// after saving, re-run the transforms of all datasets that load this one,
+	// in dependency order
+	Cascade bool
 }
 
 // AbsolutizePaths converts any relative path references to their absolute
@@ -450,11 +592,16 @@ func (p *SaveParams) AbsolutizePaths() error {
 
 // Save adds a history entry, updating a dataset
 func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
+	return m.SaveContext(context.TODO(), p, res)
+}
+
+// SaveContext is Save with a context. Cancelling ctx stops the save,
+// including saves running in a connected daemon
+func (m *DatasetMethods) SaveContext(ctx context.Context, p *SaveParams, res *reporef.DatasetRef) error {
 	if m.inst.rpc != nil {
-		p.ScriptOutput = nil
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Save", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Save", p, res))
 	}
-	ctx := context.TODO()
+	reqLog.Debugf(ctx, "save %q", p.Ref)
 
 	if p.Private {
 		return fmt.Errorf("option to make dataset private not yet implemented, refer to https://github.com/qri-io/qri/issues/291 for updates")
@@ -467,7 +614,7 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 			return err
 		}
 		// If dataset name already exists, just log a warning and then continue.
-		log.Error(dsref.ErrBadCaseShouldRename)
+		reqLog.Errorf(ctx, "%s", dsref.ErrBadCaseShouldRename)
 	} else if err == dsref.ErrEmptyRef {
 		// Okay if reference is empty. Later code will try to infer the name from other parameters.
 	} else if err != nil {
@@ -508,7 +655,7 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 		// When saving in an FSI directory, the ref should exist (due to `qri init`), and we
 		// need to load the previous version from the working directory.
 		if datasetRef.FSIPath != "" {
-			ds, err = fsi.ReadDir(datasetRef.FSIPath)
+			ds, err = fsi.ReadLinkedDir(datasetRef.FSIPath)
 			if err != nil {
 				return err
 			}
@@ -542,6 +689,18 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 		ds = p.Dataset
 	}
 
+	if p.RecordHTTP && p.ReplayHTTP {
+		return errors.New(ErrBadArgs, "cannot record and replay http requests in the same save")
+	}
+	if p.Cascade && p.DryRun {
+		return errors.New(ErrBadArgs, "cannot cascade a dry run")
+	}
+	// replaying only makes sense for a previous transform, recall it when no
+	// other transform is given
+	if p.ReplayHTTP && p.Recall == "" && ds.Transform == nil && len(p.FilePaths) == 0 {
+		p.Recall = "tf"
+	}
+
 	if p.Recall != "" {
 		datasetRef := reporef.DatasetRef{
 			Peername: ds.Peername,
@@ -585,7 +744,7 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 	}
 
 	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
-		log.Debugf("open ds error: %s", err.Error())
+		reqLog.Debugf(ctx, "open ds error: %s", err.Error())
 		return err
 	}
 
@@ -617,10 +776,12 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 		ShouldRender:        p.ShouldRender,
 		NewName:             p.NewName,
 		Drop:                p.Drop,
+		RecordHTTP:          p.RecordHTTP,
+		ReplayHTTP:          p.ReplayHTTP,
 	}
 	datasetRef, err = base.SaveDataset(ctx, m.inst.repo, m.inst.node.LocalStreams, ds, p.Secrets, p.ScriptOutput, switches)
 	if err != nil {
-		log.Debugf("create ds error: %s\n", err.Error())
+		reqLog.Debugf(ctx, "create ds error: %s\n", err.Error())
 		return err
 	}
 
@@ -660,11 +821,156 @@ func (m *DatasetMethods) Save(p *SaveParams, res *reporef.DatasetRef) error {
 	if fsiPath != "" && !p.DryRun {
 		// Need to pass filesystem here so that we can read the README component and write it
 		// properly back to disk.
-		fsi.WriteComponents(res.Dataset, datasetRef.FSIPath, m.inst.repo.Filesystem())
+		fsi.WriteLinkedComponents(res.Dataset, datasetRef.FSIPath, m.inst.repo.Filesystem())
+	}
+
+	if !p.DryRun {
+		m.inst.publish(ctx, event.ETDatasetSaved, event.DatasetSavedEvent{
+			Ref:     reporef.ConvertToDsref(datasetRef),
+			FSIPath: fsiPath,
+		})
+	}
+
+	if p.Cascade {
+		return m.cascade(ctx, datasetRef, p)
 	}
 	return nil
 }
 
+// DefaultPreviewBodyLimit is the number of body entries included in a save
+// preview when no limit is given
+const DefaultPreviewBodyLimit = 25
+
+// PreviewSaveParams encapsulates arguments to PreviewSave
+type PreviewSaveParams struct {
+	SaveParams
+	// number of body entries to include in the preview
+	BodyLimit int
+	// number of body entries to skip
+	BodyOffset int
+}
+
+// PreviewSaveResult describes the version a save would create, compared
+// against the current head version
+type PreviewSaveResult struct {
+	// reference to the version that would be created
+	Ref string `json:"ref"`
+	// the version that would be created, with a commit describing the changes
+	Dataset *dataset.Dataset `json:"dataset"`
+	// page of the would-be body, encoded as JSON
+	Body json.RawMessage `json:"body,omitempty"`
+	// path of the current head version, empty for new datasets
+	HeadPath string `json:"headPath,omitempty"`
+	// entry count of the head version body
+	HeadEntries int `json:"headEntries"`
+	// changes to the structure component, excluding the schema
+	Structure     []*Delta  `json:"structure,omitempty"`
+	StructureStat *DiffStat `json:"structureStat,omitempty"`
+	// changes to the structure schema
+	Schema     []*Delta  `json:"schema,omitempty"`
+	SchemaStat *DiffStat `json:"schemaStat,omitempty"`
+}
+
+// PreviewSave runs a save without committing, describing the resulting
+// version and how it differs from the current head version
+func (m *DatasetMethods) PreviewSave(p *PreviewSaveParams, res *PreviewSaveResult) error {
+	return m.PreviewSaveContext(context.TODO(), p, res)
+}
+
+// PreviewSaveContext is PreviewSave with a context
+func (m *DatasetMethods) PreviewSaveContext(ctx context.Context, p *PreviewSaveParams, res *PreviewSaveResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.PreviewSave", p, res))
+	}
+
+	if p.Publish {
+		return fmt.Errorf("can't use publish & dry-run together")
+	}
+	sp := p.SaveParams
+	sp.DryRun = true
+	sp.ReturnBody = false
+
+	ref := &reporef.DatasetRef{}
+	if err := m.SaveContext(ctx, &sp, ref); err != nil {
+		return err
+	}
+	next := ref.Dataset
+
+	limit := p.BodyLimit
+	if limit <= 0 {
+		limit = DefaultPreviewBodyLimit
+	}
+	if next.BodyFile() != nil && next.Structure != nil {
+		st := &dataset.Structure{
+			Format: "json",
+			Schema: next.Structure.Schema,
+		}
+		data, err := base.ConvertBodyFile(next.BodyFile(), next.Structure, st, limit, p.BodyOffset, false)
+		if err != nil {
+			return fmt.Errorf("previewing body: %s", err)
+		}
+		res.Body = json.RawMessage(data)
+	}
+	res.Ref = ref.String()
+	res.Dataset = next
+
+	head := &dataset.Dataset{}
+	headRef := reporef.DatasetRef{Peername: ref.Peername, Name: ref.Name}
+	if err := repo.CanonicalizeDatasetRef(m.inst.repo, &headRef); err == nil && headRef.Path != "" {
+		if head, err = dsfs.LoadDataset(ctx, m.inst.repo.Store(), headRef.Path); err != nil {
+			return fmt.Errorf("loading head version: %s", err)
+		}
+		res.HeadPath = headRef.Path
+		if head.Structure != nil {
+			res.HeadEntries = head.Structure.Entries
+		}
+	}
+
+	var (
+		prevSt, nextSt         = &dataset.Structure{}, &dataset.Structure{}
+		prevSchema, nextSchema map[string]interface{}
+	)
+	if head.Structure != nil {
+		prevSt.Assign(head.Structure)
+		prevSchema = prevSt.Schema
+	}
+	if next.Structure != nil {
+		nextSt.Assign(next.Structure)
+		nextSchema = nextSt.Schema
+	}
+	prevSt.Schema, nextSt.Schema = nil, nil
+	prevSt.DropTransientValues()
+	nextSt.DropTransientValues()
+
+	var err error
+	if res.Structure, res.StructureStat, err = diffValues(ctx, prevSt, nextSt); err != nil {
+		return err
+	}
+	res.Schema, res.SchemaStat, err = diffValues(ctx, prevSchema, nextSchema)
+	return err
+}
+
+// diffValues compares two values by their JSON representation
+func diffValues(ctx context.Context, a, b interface{}) ([]*Delta, *DiffStat, error) {
+	var left, right interface{}
+	for _, pair := range []struct {
+		in  interface{}
+		out *interface{}
+	}{{a, &left}, {b, &right}} {
+		data, err := json.Marshal(pair.in)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = json.Unmarshal(data, pair.out); err != nil {
+			return nil, nil, err
+		}
+		if *pair.out == nil {
+			*pair.out = map[string]interface{}{}
+		}
+	}
+	return deepdiff.New().StatDiff(ctx, left, right)
+}
+
 // This is somewhat of a hack, we shouldn't need to lookup anything about the dataset reference
 // before running Save. However, we need to check for now until we solve the problem of
 // dataset names existing with bad-case characters.
@@ -697,8 +1003,13 @@ type SetPublishStatusParams struct {
 
 // SetPublishStatus updates the publicity of a reference in the peer's namespace
 func (m *DatasetMethods) SetPublishStatus(p *SetPublishStatusParams, publishedRef *reporef.DatasetRef) error {
+	return m.SetPublishStatusContext(context.TODO(), p, publishedRef)
+}
+
+// SetPublishStatusContext is SetPublishStatus with a context
+func (m *DatasetMethods) SetPublishStatusContext(ctx context.Context, p *SetPublishStatusParams, publishedRef *reporef.DatasetRef) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.SetPublishStatus", p, publishedRef))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.SetPublishStatus", p, publishedRef))
 	}
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
@@ -715,6 +1026,7 @@ func (m *DatasetMethods) SetPublishStatus(p *SetPublishStatusParams, publishedRe
 	}
 
 	*publishedRef = ref
+	publishEvent(ctx, m.inst, ref, "")
 	return nil
 }
 
@@ -725,10 +1037,14 @@ type RenameParams struct {
 
 // Rename changes a user's given name for a dataset
 func (m *DatasetMethods) Rename(p *RenameParams, res *dsref.VersionInfo) error {
+	return m.RenameContext(context.TODO(), p, res)
+}
+
+// RenameContext is Rename with a context
+func (m *DatasetMethods) RenameContext(ctx context.Context, p *RenameParams, res *dsref.VersionInfo) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Rename", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Rename", p, res))
 	}
-	ctx := context.TODO()
 
 	if p.Current.IsEmpty() {
 		return fmt.Errorf("current name is required to rename a dataset")
@@ -760,10 +1076,14 @@ func (m *DatasetMethods) Rename(p *RenameParams, res *dsref.VersionInfo) error {
 	}
 
 	if err = base.ReadDataset(ctx, m.inst.repo, &readRef); err != nil && err != repo.ErrNoHistory {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return err
 	}
 	*res = *info
+	m.inst.publish(ctx, event.ETDatasetRenamed, event.DatasetRenamedEvent{
+		Previous: p.Current,
+		Ref:      info.SimpleRef(),
+	})
 	return nil
 }
 
@@ -788,12 +1108,16 @@ var ErrCantRemoveDirectoryDirty = fmt.Errorf("cannot remove files while working
 
 // Remove a dataset entirely or remove a certain number of revisions
 func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
+	return m.RemoveContext(context.TODO(), p, res)
+}
+
+// RemoveContext is Remove with a context
+func (m *DatasetMethods) RemoveContext(ctx context.Context, p *RemoveParams, res *RemoveResponse) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Remove", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Remove", p, res))
 	}
-	ctx := context.TODO()
 
-	log.Debugf("Remove dataset ref %q, revisions %v", p.Ref, p.Revision)
+	reqLog.Debugf(ctx, "Remove dataset ref %q, revisions %v", p.Ref, p.Revision)
 
 	if p.Revision.Gen == 0 {
 		return fmt.Errorf("invalid number of revisions to delete: 0")
@@ -809,11 +1133,11 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 	}
 
 	if canonErr := repo.CanonicalizeDatasetRef(m.inst.repo, &ref); canonErr != nil && canonErr != repo.ErrNoHistory {
-		log.Debugf("Remove, repo.CanonicalizeDatasetRef failed, error: %s", canonErr)
+		reqLog.Debugf(ctx, "Remove, repo.CanonicalizeDatasetRef failed, error: %s", canonErr)
 		if p.Force {
 			didRemove, _ := base.RemoveEntireDataset(ctx, m.inst.repo, reporef.ConvertToDsref(ref), []DatasetLogItem{})
 			if didRemove != "" {
-				log.Debugf("Remove cleaned up data found in %s", didRemove)
+				reqLog.Debugf(ctx, "Remove cleaned up data found in %s", didRemove)
 				res.Message = didRemove
 				return nil
 			}
@@ -830,17 +1154,17 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 			wdErr := m.inst.fsi.IsWorkingDirectoryClean(ctx, ref.FSIPath)
 			if wdErr != nil {
 				if wdErr == fsi.ErrWorkingDirectoryDirty {
-					log.Debugf("Remove, IsWorkingDirectoryDirty")
+					reqLog.Debugf(ctx, "Remove, IsWorkingDirectoryDirty")
 					return ErrCantRemoveDirectoryDirty
 				}
 				if strings.Contains(wdErr.Error(), "not a linked directory") {
 					// If the working directory has been removed (or renamed), could not get the
 					// status. However, don't let this stop the remove operation, since the files
 					// are already gone, and therefore won't be removed.
-					log.Debugf("Remove, couldn't get status for %s, maybe removed or renamed", ref.FSIPath)
+					reqLog.Debugf(ctx, "Remove, couldn't get status for %s, maybe removed or renamed", ref.FSIPath)
 					wdErr = nil
 				} else {
-					log.Debugf("Remove, IsWorkingDirectoryClean error: %s", err)
+					reqLog.Debugf(ctx, "Remove, IsWorkingDirectoryClean error: %s", err)
 					return wdErr
 				}
 			}
@@ -860,7 +1184,7 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 		// If the dataset has no history, treat this operation as deleting everything.
 		p.Revision.Gen = dsref.AllGenerations
 	} else if err != nil {
-		log.Debugf("Remove, base.DatasetLog failed, error: %s", err)
+		reqLog.Debugf(ctx, "Remove, base.DatasetLog failed, error: %s", err)
 		// Set history to a list of 0 elements. In the rest of this function, certain operations
 		// check the history to figure out what to delete, they will always see a blank history,
 		// which is a safer option for a destructive option such as remove.
@@ -874,7 +1198,7 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 			if err := m.inst.fsi.Unlink(ref.FSIPath, dr); err == nil {
 				res.Unlinked = true
 			} else {
-				log.Errorf("during Remove, dataset did not unlink: %s", err)
+				reqLog.Errorf(ctx, "during Remove, dataset did not unlink: %s", err)
 			}
 		}
 
@@ -896,10 +1220,10 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 					// If the working directory has already been removed (or renamed), it is
 					// not an error that this remove operation fails, since we were trying to
 					// remove them anyway.
-					log.Debugf("Remove, couldn't remove %s, maybe already removed or renamed", ref.FSIPath)
+					reqLog.Debugf(ctx, "Remove, couldn't remove %s, maybe already removed or renamed", ref.FSIPath)
 					err = nil
 				} else {
-					log.Debugf("Remove, os.Remove failed, error: %s", err)
+					reqLog.Debugf(ctx, "Remove, os.Remove failed, error: %s", err)
 					return err
 				}
 			}
@@ -908,7 +1232,7 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 		// Delete the specific number of revisions.
 		info, err := base.RemoveNVersionsFromStore(ctx, m.inst.repo, reporef.ConvertToDsref(ref), p.Revision.Gen)
 		if err != nil {
-			log.Debugf("Remove, base.RemoveNVersionsFromStore failed, error: %s", err)
+			reqLog.Debugf(ctx, "Remove, base.RemoveNVersionsFromStore failed, error: %s", err)
 			return err
 		}
 		res.NumDeleted = p.Revision.Gen
@@ -917,13 +1241,13 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 			// Load dataset version that is at head after newer versions are removed
 			ds, err := dsfs.LoadDataset(ctx, m.inst.repo.Store(), info.Path)
 			if err != nil {
-				log.Debugf("Remove, dsfs.LoadDataset failed, error: %s", err)
+				reqLog.Debugf(ctx, "Remove, dsfs.LoadDataset failed, error: %s", err)
 				return err
 			}
 			ds.Name = info.Name
 			ds.Peername = info.Username
 			if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
-				log.Debugf("Remove, base.OpenDataset failed, error: %s", err)
+				reqLog.Debugf(ctx, "Remove, base.OpenDataset failed, error: %s", err)
 				return err
 			}
 
@@ -933,14 +1257,19 @@ func (m *DatasetMethods) Remove(p *RemoveParams, res *RemoveResponse) error {
 			// Delete the old files
 			err = fsi.DeleteComponentFiles(info.FSIPath)
 			if err != nil {
-				log.Debug("Remove, fsi.DeleteComponentFiles failed, error: %s", err)
+				reqLog.Debugf(ctx, "Remove, fsi.DeleteComponentFiles failed, error: %s", err)
 			}
 
 			// Update the files in the working directory
-			fsi.WriteComponents(ds, info.FSIPath, m.inst.repo.Filesystem())
+			fsi.WriteLinkedComponents(ds, info.FSIPath, m.inst.repo.Filesystem())
 		}
 	}
-	log.Debugf("Remove finished")
+	reqLog.Debugf(ctx, "Remove finished")
+	m.inst.publish(ctx, event.ETDatasetRemoved, event.DatasetRemovedEvent{
+		Ref:        reporef.ConvertToDsref(ref),
+		NumDeleted: res.NumDeleted,
+		Unlinked:   res.Unlinked,
+	})
 	return nil
 }
 
@@ -954,14 +1283,18 @@ type AddParams struct {
 
 // Add adds an existing dataset to a peer's repository
 func (m *DatasetMethods) Add(p *AddParams, res *reporef.DatasetRef) error {
+	return m.AddContext(context.TODO(), p, res)
+}
+
+// AddContext is Add with a context
+func (m *DatasetMethods) AddContext(ctx context.Context, p *AddParams, res *reporef.DatasetRef) error {
 	if err := qfs.AbsPath(&p.LinkDir); err != nil {
 		return err
 	}
 
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Add", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Add", p, res))
 	}
-	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
 	if err != nil {
@@ -982,6 +1315,10 @@ func (m *DatasetMethods) Add(p *AddParams, res *reporef.DatasetRef) error {
 	}
 
 	*res = ref
+	m.inst.publish(ctx, event.ETDatasetPulled, event.DatasetPulledEvent{
+		Ref:    reporef.ConvertToDsref(ref),
+		Remote: p.RemoteAddr,
+	})
 
 	if p.LinkDir != "" {
 		checkoutp := &CheckoutParams{
@@ -990,7 +1327,7 @@ func (m *DatasetMethods) Add(p *AddParams, res *reporef.DatasetRef) error {
 		}
 		m := NewFSIMethods(m.inst)
 		checkoutRes := ""
-		if err = m.Checkout(checkoutp, &checkoutRes); err != nil {
+		if err = m.CheckoutContext(ctx, checkoutp, &checkoutRes); err != nil {
 			return err
 		}
 	}
@@ -1010,10 +1347,14 @@ type ValidateDatasetParams struct {
 
 // Validate gives a dataset of errors and issues for a given dataset
 func (m *DatasetMethods) Validate(p *ValidateDatasetParams, valerrs *[]jsonschema.ValError) error {
+	return m.ValidateContext(context.TODO(), p, valerrs)
+}
+
+// ValidateContext is Validate with a context
+func (m *DatasetMethods) ValidateContext(ctx context.Context, p *ValidateDatasetParams, valerrs *[]jsonschema.ValError) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Validate", p, valerrs))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Validate", p, valerrs))
 	}
-	ctx := context.TODO()
 
 	// TODO: restore validating data from a URL
 	// if p.URL != "" && ref.IsEmpty() && o.Schema == nil {
@@ -1057,7 +1398,7 @@ func (m *DatasetMethods) Validate(p *ValidateDatasetParams, valerrs *[]jsonschem
 
 	if p.Ref != "" {
 		if ref.FSIPath != "" {
-			if ds, err = fsi.ReadDir(ref.FSIPath); err != nil {
+			if ds, err = fsi.ReadWorkingDataset(ctx, ref.FSIPath, m.inst.repo.Store(), ref.Path); err != nil {
 				return fmt.Errorf("loading linked dataset: %s", err)
 			}
 		} else {
@@ -1127,12 +1468,211 @@ func (m *DatasetMethods) Validate(p *ValidateDatasetParams, valerrs *[]jsonschem
 	return err
 }
 
+// ReproduceParams defines parameters for reproducing a dataset version
+type ReproduceParams struct {
+	Ref string
+	// secrets for transform execution
+	Secrets map[string]string
+	// optional writer to have transform script record standard output to.
+	// Over RPC, output is streamed from the daemon
+	ScriptOutput io.Writer
+}
+
+// ReproduceResult is the outcome of re-running a version's transform
+type ReproduceResult struct {
+	Ref string
+	// hex-encoded sha256 sum of the stored body
+	BodyHash string
+	// hex-encoded sha256 sum of the body the transform produced
+	ReproducedBodyHash string
+	// Reproduced is true when both body hashes match
+	Reproduced bool
+}
+
+// Reproduce re-runs the transform of a dataset version offline against its
+// recorded http fixture and previous version, checking the transform yields
+// the same body
+func (m *DatasetMethods) Reproduce(p *ReproduceParams, res *ReproduceResult) error {
+	return m.ReproduceContext(context.TODO(), p, res)
+}
+
+// ReproduceContext is Reproduce with a context
+func (m *DatasetMethods) ReproduceContext(ctx context.Context, p *ReproduceParams, res *ReproduceResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Reproduce", p, res))
+	}
+
+	ref, err := repo.ParseDatasetRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil {
+		if err == repo.ErrNotFound {
+			return fmt.Errorf("cannot find dataset: %s", ref)
+		}
+		return err
+	}
+
+	ds, err := dsfs.LoadDataset(ctx, m.inst.repo.Store(), ref.Path)
+	if err != nil {
+		return fmt.Errorf("loading dataset: %s", err)
+	}
+	if ds.Transform == nil {
+		return fmt.Errorf("dataset %s has no transform to reproduce", ref.AliasString())
+	}
+	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
+		return err
+	}
+	defer base.CloseDataset(ds)
+
+	bodyHash, err := sha256Hex(ds.BodyFile())
+	if err != nil {
+		return fmt.Errorf("reading body: %s", err)
+	}
+
+	changes := &dataset.Dataset{
+		Peername:  ref.Peername,
+		Name:      ref.Name,
+		Transform: ds.Transform,
+	}
+	switches := base.SaveSwitches{
+		DryRun:           true,
+		ForceIfNoChanges: true,
+		ReplayHTTP:       true,
+		ReplayDatasets:   true,
+	}
+	// run against the version's own parent & the dataset versions it loaded,
+	// which is what the transform saw when the version was saved
+	reproduced, err := base.SaveDatasetFrom(ctx, m.inst.repo, m.inst.node.LocalStreams, changes, ds.PreviousPath, p.Secrets, p.ScriptOutput, switches)
+	if err != nil {
+		return err
+	}
+
+	reproducedHash, err := sha256Hex(reproduced.Dataset.BodyFile())
+	if err != nil {
+		return fmt.Errorf("reading reproduced body: %s", err)
+	}
+
+	*res = ReproduceResult{
+		Ref:                ref.String(),
+		BodyHash:           bodyHash,
+		ReproducedBodyHash: reproducedHash,
+		Reproduced:         bodyHash == reproducedHash,
+	}
+	return nil
+}
+
+// DepsParams defines parameters for listing dataset dependencies
+type DepsParams struct {
+	Ref string
+}
+
+// DepsResult lists the datasets connected to a dataset through transforms
+type DepsResult struct {
+	Ref string
+	// datasets the dataset's transform loads
+	Upstream []string
+	// datasets with transforms that load the dataset
+	Downstream []string
+	// every dataset that depends on this one, in the order a cascading save
+	// re-runs their transforms
+	Cascade []string
+}
+
+// Deps lists the upstream & downstream datasets of a dataset across the repo
+func (m *DatasetMethods) Deps(p *DepsParams, res *DepsResult) error {
+	return m.DepsContext(context.TODO(), p, res)
+}
+
+// DepsContext is Deps with a context
+func (m *DatasetMethods) DepsContext(ctx context.Context, p *DepsParams, res *DepsResult) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Deps", p, res))
+	}
+
+	ref, err := repo.ParseDatasetRef(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil {
+		if err == repo.ErrNotFound {
+			return fmt.Errorf("cannot find dataset: %s", ref)
+		}
+		return err
+	}
+
+	g, err := base.LoadDepGraph(ctx, m.inst.repo)
+	if err != nil {
+		return err
+	}
+	alias := ref.AliasString()
+	cascade, err := g.Cascade(alias)
+	if err != nil {
+		return err
+	}
+
+	*res = DepsResult{
+		Ref:        alias,
+		Upstream:   g.Upstream[alias],
+		Downstream: g.Downstream[alias],
+		Cascade:    cascade,
+	}
+	return nil
+}
+
+// cascade re-runs the transforms of every dataset downstream of ref
+func (m *DatasetMethods) cascade(ctx context.Context, ref reporef.DatasetRef, p *SaveParams) error {
+	g, err := base.LoadDepGraph(ctx, m.inst.repo)
+	if err != nil {
+		return err
+	}
+	downstream, err := g.Cascade(ref.AliasString())
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range downstream {
+		if p.ScriptOutput != nil {
+			fmt.Fprintf(p.ScriptOutput, "🔁 cascading to %s\n", alias)
+		}
+		res := reporef.DatasetRef{}
+		err := m.Save(&SaveParams{
+			Ref:          alias,
+			Message:      fmt.Sprintf("cascade from %s", ref.AliasString()),
+			Recall:       "tf",
+			Secrets:      p.Secrets,
+			ScriptOutput: p.ScriptOutput,
+			UseDscache:   p.UseDscache,
+		}, &res)
+		// downstream transforms that yield the same result don't need a new version
+		if err != nil && !errors.Is(err, dsfs.ErrNoChanges) {
+			return fmt.Errorf("cascading to %s: %s", alias, err)
+		}
+	}
+	return nil
+}
+
+func sha256Hex(r io.Reader) (string, error) {
+	if r == nil {
+		return "", fmt.Errorf("no body file")
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // Manifest generates a manifest for a dataset path
 func (m *DatasetMethods) Manifest(refstr *string, mfst *dag.Manifest) error {
+	return m.ManifestContext(context.TODO(), refstr, mfst)
+}
+
+// ManifestContext is Manifest with a context
+func (m *DatasetMethods) ManifestContext(ctx context.Context, refstr *string, mfst *dag.Manifest) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Manifest", refstr, mfst))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Manifest", refstr, mfst))
 	}
-	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(*refstr)
 	if err != nil {
@@ -1153,10 +1693,14 @@ func (m *DatasetMethods) Manifest(refstr *string, mfst *dag.Manifest) error {
 
 // ManifestMissing generates a manifest of blocks that are not present on this repo for a given manifest
 func (m *DatasetMethods) ManifestMissing(a, b *dag.Manifest) error {
+	return m.ManifestMissingContext(context.TODO(), a, b)
+}
+
+// ManifestMissingContext is ManifestMissing with a context
+func (m *DatasetMethods) ManifestMissingContext(ctx context.Context, a, b *dag.Manifest) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Manifest", a, b))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Manifest", a, b))
 	}
-	ctx := context.TODO()
 
 	var mf *dag.Manifest
 	mf, err := m.inst.node.MissingManifest(ctx, a)
@@ -1174,10 +1718,14 @@ type DAGInfoParams struct {
 
 // DAGInfo generates a dag.Info for a dataset path. If a label is given, DAGInfo will generate a sub-dag.Info at that label.
 func (m *DatasetMethods) DAGInfo(s *DAGInfoParams, i *dag.Info) error {
+	return m.DAGInfoContext(context.TODO(), s, i)
+}
+
+// DAGInfoContext is DAGInfo with a context
+func (m *DatasetMethods) DAGInfoContext(ctx context.Context, s *DAGInfoParams, i *dag.Info) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.DAGInfo", s, i))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.DAGInfo", s, i))
 	}
-	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(s.RefStr)
 	if err != nil {
@@ -1212,11 +1760,15 @@ type StatsResponse struct {
 
 // Stats generates stats for a dataset
 func (m *DatasetMethods) Stats(p *StatsParams, res *StatsResponse) error {
+	return m.StatsContext(context.TODO(), p, res)
+}
+
+// StatsContext is Stats with a context
+func (m *DatasetMethods) StatsContext(ctx context.Context, p *StatsParams, res *StatsResponse) error {
 	var err error
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetMethods.Stats", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Stats", p, res))
 	}
-	ctx := context.TODO()
 	if p.Dataset == nil {
 		ref := &reporef.DatasetRef{}
 		ref, err := base.ToDatasetRef(p.Ref, m.inst.repo, false)
@@ -26,6 +26,7 @@ import (
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/p2p"
 	p2ptest "github.com/qri-io/qri/p2p/test"
 	"github.com/qri-io/qri/repo"
@@ -168,6 +169,138 @@ func TestDatasetRequestsForceSave(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsPreviewSave(t *testing.T) {
+	node := newTestQriNode(t)
+	ref := addCitiesDataset(t, node)
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	m := NewDatasetMethods(inst)
+
+	metaPath := tempDatasetFile(t, "*-meta.json", &dataset.Dataset{Meta: &dataset.Meta{Title: "preview title"}})
+	defer os.RemoveAll(metaPath)
+
+	res := &PreviewSaveResult{}
+	p := &PreviewSaveParams{
+		SaveParams: SaveParams{
+			Ref:       ref.AliasString(),
+			FilePaths: []string{metaPath},
+		},
+		BodyLimit: 2,
+	}
+	if err := m.PreviewSave(p, res); err != nil {
+		t.Fatal(err)
+	}
+	if res.HeadPath != ref.Path {
+		t.Errorf("head path mismatch. expected: %q, got: %q", ref.Path, res.HeadPath)
+	}
+	if res.Dataset.Commit == nil || res.Dataset.Commit.Title == "" {
+		t.Error("expected preview to include a generated commit title")
+	}
+	if len(res.Structure) != 0 || len(res.Schema) != 0 {
+		t.Errorf("expected no structure or schema changes, got: %d structure, %d schema", len(res.Structure), len(res.Schema))
+	}
+	entries := []interface{}{}
+	if err := json.Unmarshal(res.Body, &entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 body entries in preview, got: %d", len(entries))
+	}
+
+	head := &reporef.DatasetRef{Peername: ref.Peername, Name: ref.Name}
+	if err := repo.CanonicalizeDatasetRef(node.Repo, head); err != nil {
+		t.Fatal(err)
+	}
+	if head.Path != ref.Path {
+		t.Error("expected preview not to create a new version")
+	}
+}
+
+func TestDatasetRequestsReproduce(t *testing.T) {
+	ctx := context.Background()
+	node := newTestQriNode(t)
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	m := NewDatasetMethods(inst)
+
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(strconv.Itoa(requests)))
+	}))
+	defer s.Close()
+
+	// each version appends the response to the previous version's body
+	versions := []reporef.DatasetRef{}
+	for i := 0; i < 3; i++ {
+		ds := &dataset.Dataset{
+			Peername: "me",
+			Name:     "append_tf",
+			Transform: &dataset.Transform{
+				ScriptPath: "testdata/append_tf/transform.star",
+				Config:     map[string]interface{}{"url": s.URL},
+			},
+		}
+		ref, err := base.SaveDataset(ctx, node.Repo, devNull, ds, nil, nil, base.SaveSwitches{Pin: true, RecordHTTP: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, ref)
+	}
+	s.Close()
+
+	for i, ref := range versions {
+		res := &ReproduceResult{}
+		if err := m.Reproduce(&ReproduceParams{Ref: ref.String()}, res); err != nil {
+			t.Fatalf("version %d: %s", i, err)
+		}
+		if !res.Reproduced {
+			t.Errorf("version %d: expected body to reproduce. stored: %s, reproduced: %s", i, res.BodyHash, res.ReproducedBodyHash)
+		}
+	}
+}
+
+func TestDatasetRequestsReproduceLoadDataset(t *testing.T) {
+	ctx := context.Background()
+	node := newTestQriNode(t)
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	m := NewDatasetMethods(inst)
+
+	saveUpstream := func(body string) {
+		res := &reporef.DatasetRef{}
+		err := m.Save(&SaveParams{
+			Ref: "me/upstream",
+			Dataset: &dataset.Dataset{
+				BodyPath:  "body.json",
+				BodyBytes: []byte(body),
+			},
+		}, res)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saveUpstream("[1,2]")
+	ds := &dataset.Dataset{
+		Peername:  "me",
+		Name:      "load_tf",
+		Transform: &dataset.Transform{ScriptPath: "testdata/load_tf/transform.star"},
+	}
+	ref, err := base.SaveDataset(ctx, node.Repo, devNull, ds, nil, nil, base.SaveSwitches{Pin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reproducing must load the upstream version the transform saw, not the
+	// current head
+	saveUpstream("[3,4,5]")
+	res := &ReproduceResult{}
+	if err := m.Reproduce(&ReproduceParams{Ref: ref.String()}, res); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Reproduced {
+		t.Errorf("expected body to reproduce. stored: %s, reproduced: %s", res.BodyHash, res.ReproducedBodyHash)
+	}
+}
+
 func TestDatasetRequestsSaveRecallDrop(t *testing.T) {
 	node := newTestQriNode(t)
 	ref := addNowTransformDataset(t, node)
@@ -639,6 +772,66 @@ func TestDatasetRequestsGetP2p(t *testing.T) {
 	wg.Wait()
 }
 
+func TestDatasetMethodsEvents(t *testing.T) {
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, config.DefaultP2PForTesting())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	m := NewDatasetMethods(inst)
+
+	events := inst.Bus().Subscribe(event.ETDatasetSaved, event.ETDatasetRenamed, event.ETDatasetRemoved)
+	next := func() event.Event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+		return event.Event{}
+	}
+
+	saved := reporef.DatasetRef{}
+	if err := m.Save(&SaveParams{Ref: "me/cities", Dataset: &dataset.Dataset{Meta: &dataset.Meta{Title: "event test"}}}, &saved); err != nil {
+		t.Fatal(err)
+	}
+	e := next()
+	if e.Topic != event.ETDatasetSaved {
+		t.Fatalf("expected saved event, got: %s", e.Topic)
+	}
+	if got := e.Payload.(event.DatasetSavedEvent).Ref.Path; got != saved.Path {
+		t.Errorf("saved event path mismatch. expected: %s, got: %s", saved.Path, got)
+	}
+
+	renamed := &dsref.VersionInfo{}
+	if err := m.Rename(&RenameParams{Current: dsref.Ref{Username: "peer", Name: "cities"}, Next: dsref.Ref{Username: "peer", Name: "event_cities"}}, renamed); err != nil {
+		t.Fatal(err)
+	}
+	e = next()
+	if e.Topic != event.ETDatasetRenamed {
+		t.Fatalf("expected renamed event, got: %s", e.Topic)
+	}
+	if got := e.Payload.(event.DatasetRenamedEvent); got.Previous.Name != "cities" || got.Ref.Name != "event_cities" {
+		t.Errorf("unexpected renamed event payload: %#v", got)
+	}
+
+	removed := RemoveResponse{}
+	if err := m.Remove(&RemoveParams{Ref: "peer/event_cities", Revision: dsref.NewAllRevisions()}, &removed); err != nil {
+		t.Fatal(err)
+	}
+	e = next()
+	if e.Topic != event.ETDatasetRemoved {
+		t.Fatalf("expected removed event, got: %s", e.Topic)
+	}
+	if got := e.Payload.(event.DatasetRemovedEvent); got.NumDeleted != dsref.AllGenerations {
+		t.Errorf("expected entire dataset to be removed, got: %#v", got)
+	}
+}
+
 func TestDatasetRequestsRename(t *testing.T) {
 	mr, err := testrepo.NewTestRepo()
 	if err != nil {
@@ -47,6 +47,11 @@ type DiffResponse struct {
 
 // Diff computes the diff of two datasets
 func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) error {
+	return m.DiffContext(context.TODO(), p, res)
+}
+
+// DiffContext is Diff with a context
+func (m *DatasetMethods) DiffContext(ctx context.Context, p *DiffParams, res *DiffResponse) error {
 	var err error
 	// absolutize any local paths before a possible trip over RPC to another local process
 	if !dsref.IsRefString(p.LeftPath) {
@@ -61,9 +66,8 @@ func (m *DatasetMethods) Diff(p *DiffParams, res *DiffResponse) error {
 	}
 
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("DatasetRequests.Diff", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "DatasetMethods.Diff", p, res))
 	}
-	ctx := context.TODO()
 
 	if p.LeftPath == "" && p.RightPath == "" {
 		return fmt.Errorf("nothing to diff")
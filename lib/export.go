@@ -3,13 +3,13 @@ package lib
 import (
 	"context"
 	"fmt"
-	"net/rpc"
 
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/archive"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/rpc"
 )
 
 // ExportRequests encapsulates business logic of export operation
@@ -45,6 +45,11 @@ type ExportParams struct {
 
 // Export exports a dataset in the specified format
 func (r *ExportRequests) Export(p *ExportParams, fileWritten *string) (err error) {
+	return r.ExportContext(context.TODO(), p, fileWritten)
+}
+
+// ExportContext is Export with a context
+func (r *ExportRequests) ExportContext(ctx context.Context, p *ExportParams, fileWritten *string) (err error) {
 	if p.TargetDir == "" {
 		p.TargetDir = "."
 		if err = qfs.AbsPath(&p.TargetDir); err != nil {
@@ -53,9 +58,8 @@ func (r *ExportRequests) Export(p *ExportParams, fileWritten *string) (err error
 	}
 
 	if r.cli != nil {
-		return checkRPCError(r.cli.Call("ExportRequests.Export", p, fileWritten))
+		return checkRPCError(r.cli.CallContext(ctx, "ExportRequests.Export", p, fileWritten))
 	}
-	ctx := context.TODO()
 
 	if p.Ref == "" {
 		return repo.ErrEmptyRef
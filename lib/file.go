@@ -120,6 +120,13 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			ds.Transform.SetScriptFile(qfs.NewMemfileReader("transform.star", f))
 			return &ds, "tf", nil
 
+		case ".sql":
+			// sql files are assumed to be a transform script that queries other
+			// datasets
+			ds.Transform = &dataset.Transform{ScriptPath: path, Syntax: "sql"}
+			ds.Transform.SetScriptFile(qfs.NewMemfileReader("transform.sql", f))
+			return &ds, "tf", nil
+
 		case ".html":
 			// html files are assumped to be a viz script with no additional viz
 			// component details
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
@@ -12,6 +13,8 @@ import (
 	"github.com/qri-io/qri/base/component"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
@@ -108,10 +111,14 @@ type StatusItem = fsi.StatusItem
 // Status checks for any modifications or errors in a linked directory against its previous
 // version in the repo. Must only be called if FSI is enabled for this dataset.
 func (m *FSIMethods) Status(dir *string, res *[]StatusItem) (err error) {
+	return m.StatusContext(context.TODO(), dir, res)
+}
+
+// StatusContext is Status with a context
+func (m *FSIMethods) StatusContext(ctx context.Context, dir *string, res *[]StatusItem) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Status", dir, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.Status", dir, res))
 	}
-	ctx := context.TODO()
 
 	*res, err = m.inst.fsi.Status(ctx, *dir)
 	return err
@@ -121,10 +128,14 @@ func (m *FSIMethods) Status(dir *string, res *[]StatusItem) (err error) {
 // the status of its current working directory. It is an error to call this for a reference that
 // is not linked.
 func (m *FSIMethods) StatusForAlias(alias *string, res *[]StatusItem) (err error) {
+	return m.StatusForAliasContext(context.TODO(), alias, res)
+}
+
+// StatusForAliasContext is StatusForAlias with a context
+func (m *FSIMethods) StatusForAliasContext(ctx context.Context, alias *string, res *[]StatusItem) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.AliasStatus", alias, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.AliasStatus", alias, res))
 	}
-	ctx := context.TODO()
 
 	dir, err := m.inst.fsi.AliasToLinkedDir(*alias)
 	if err != nil {
@@ -137,10 +148,14 @@ func (m *FSIMethods) StatusForAlias(alias *string, res *[]StatusItem) (err error
 // WhatChanged gets changes that happened at a particular version in the history of the given
 // dataset reference. Not used for FSI.
 func (m *FSIMethods) WhatChanged(ref *string, res *[]StatusItem) (err error) {
+	return m.WhatChangedContext(context.TODO(), ref, res)
+}
+
+// WhatChangedContext is WhatChanged with a context
+func (m *FSIMethods) WhatChangedContext(ctx context.Context, ref *string, res *[]StatusItem) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.WhatChanged", ref, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.WhatChanged", ref, res))
 	}
-	ctx := context.TODO()
 
 	*res, err = m.inst.fsi.StatusAtVersion(ctx, *ref)
 	return err
@@ -150,16 +165,28 @@ func (m *FSIMethods) WhatChanged(ref *string, res *[]StatusItem) (err error) {
 type CheckoutParams struct {
 	Dir string
 	Ref string
+	// names of components to write, empty writes all components. components
+	// that aren't written stay in the repo as placeholders
+	Components []string
+	// maximum number of body entries to write, 0 writes the whole body
+	BodyLimit int
+	// format to write the body in, converted back to the stored format on
+	// save. defaults to the stored format
+	BodyFormat string
 }
 
 // Checkout method writes a dataset to a directory as individual files.
 func (m *FSIMethods) Checkout(p *CheckoutParams, out *string) (err error) {
+	return m.CheckoutContext(context.TODO(), p, out)
+}
+
+// CheckoutContext is Checkout with a context
+func (m *FSIMethods) CheckoutContext(ctx context.Context, p *CheckoutParams, out *string) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Checkout", p, out))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.Checkout", p, out))
 	}
-	ctx := context.TODO()
 
-	log.Debugf("Checkout started, stat'ing %q", p.Dir)
+	reqLog.Debugf(ctx, "Checkout started, stat'ing %q", p.Dir)
 
 	// TODO(dlong): Fail if Dir is "", should be required to specify a location. Should probably
 	// only allow absolute paths. Add tests.
@@ -182,49 +209,71 @@ func (m *FSIMethods) Checkout(p *CheckoutParams, out *string) (err error) {
 		return
 	}
 
-	log.Debugf("Checkout for ref %q", ref)
+	reqLog.Debugf(ctx, "Checkout for ref %q", ref)
 
 	// Fail early if link already exists
 	if err := m.inst.fsi.EnsureRefNotLinked(ref); err != nil {
 		return err
 	}
+	if err := fsi.ValidateCheckoutComponents(p.Components); err != nil {
+		return errors.New(ErrBadArgs, err.Error())
+	}
+	if p.BodyLimit < 0 {
+		return errors.New(ErrBadArgs, "body limit can't be negative")
+	}
+	if p.BodyFormat != "" {
+		if _, err := dataset.ParseDataFormatString(p.BodyFormat); err != nil {
+			return errors.New(ErrBadArgs, fmt.Sprintf("invalid body format %q", p.BodyFormat))
+		}
+	}
 
 	// Load dataset that is being checked out.
 	ds, err := dsfs.LoadDataset(ctx, m.inst.repo.Store(), ref.Path)
 	if err != nil {
-		log.Debugf("Checkout, dsfs.LoadDataset failed, error: %s", err)
+		reqLog.Debugf(ctx, "Checkout, dsfs.LoadDataset failed, error: %s", err)
 		return fmt.Errorf("error loading dataset")
 	}
 	ds.Name = ref.Name
 	ds.Peername = ref.Peername
 	if err = base.OpenDataset(ctx, m.inst.repo.Filesystem(), ds); err != nil {
-		log.Debugf("Checkout, base.OpenDataset failed, error: %s", ref)
+		reqLog.Debugf(ctx, "Checkout, base.OpenDataset failed, error: %s", ref)
 		return
 	}
-	log.Debugf("Checkout loaded dataset %q", ref)
+	reqLog.Debugf(ctx, "Checkout loaded dataset %q", ref)
 
 	// Create a directory.
 	if err := os.Mkdir(p.Dir, os.ModePerm); err != nil {
-		log.Debugf("Checkout, Mkdir failed, error: %s", ref)
+		reqLog.Debugf(ctx, "Checkout, Mkdir failed, error: %s", ref)
 		return err
 	}
-	log.Debugf("Checkout made directory %q", p.Dir)
+	reqLog.Debugf(ctx, "Checkout made directory %q", p.Dir)
 
 	// Create the link file, containing the dataset reference.
 	if _, _, err = m.inst.fsi.CreateLink(p.Dir, p.Ref); err != nil {
-		log.Debugf("Checkout, fsi.CreateLink failed, error: %s", ref)
+		reqLog.Debugf(ctx, "Checkout, fsi.CreateLink failed, error: %s", ref)
 		return err
 	}
-	log.Debugf("Checkout created link for %q <-> %q", p.Dir, p.Ref)
+	reqLog.Debugf(ctx, "Checkout created link for %q <-> %q", p.Dir, p.Ref)
 
 	// Write components of the dataset to the working directory.
-	err = fsi.WriteComponents(ds, p.Dir, m.inst.node.Repo.Filesystem())
+	lm := &fsi.LinkMeta{
+		Components: p.Components,
+		BodyLimit:  p.BodyLimit,
+		BodyFormat: p.BodyFormat,
+	}
+	err = fsi.WriteCheckout(ds, p.Dir, m.inst.node.Repo.Filesystem(), lm)
 	if err != nil {
-		log.Debugf("Checkout, fsi.WriteComponents failed, error: %s", ref)
+		reqLog.Debugf(ctx, "Checkout, fsi.WriteCheckout failed, error: %s", ref)
+		return err
 	}
-	log.Debugf("Checkout wrote components, successfully checked out dataset")
+	reqLog.Debugf(ctx, "Checkout wrote components, successfully checked out dataset")
 
-	log.Debugf("Checkout successfully checked out dataset")
+	m.inst.publish(ctx, event.ETFSICheckoutEvent, event.FSICheckoutEvent{
+		Ref:        reporef.ConvertToDsref(*ref),
+		FSIPath:    p.Dir,
+		Components: p.Components,
+	})
+	reqLog.Debugf(ctx, "Checkout successfully checked out dataset")
 	return nil
 }
 
@@ -236,10 +285,14 @@ type FSIWriteParams struct {
 
 // Write mutates a linked dataset on the filesystem
 func (m *FSIMethods) Write(p *FSIWriteParams, res *[]StatusItem) (err error) {
+	return m.WriteContext(context.TODO(), p, res)
+}
+
+// WriteContext is Write with a context
+func (m *FSIMethods) WriteContext(ctx context.Context, p *FSIWriteParams, res *[]StatusItem) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Write", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.Write", p, res))
 	}
-	ctx := context.TODO()
 
 	if p.Ref == "" {
 		return repo.ErrEmptyRef
@@ -279,10 +332,14 @@ type RestoreParams struct {
 
 // Restore method restores a component or all of the component files of a dataset from the repo
 func (m *FSIMethods) Restore(p *RestoreParams, out *string) (err error) {
+	return m.RestoreContext(context.TODO(), p, out)
+}
+
+// RestoreContext is Restore with a context
+func (m *FSIMethods) RestoreContext(ctx context.Context, p *RestoreParams, out *string) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("FSIMethods.Restore", p, out))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.Restore", p, out))
 	}
-	ctx := context.TODO()
 
 	if p.Ref == "" {
 		return repo.ErrEmptyRef
@@ -332,8 +389,23 @@ func (m *FSIMethods) Restore(p *RestoreParams, out *string) (err error) {
 		return err
 	}
 
+	lm, err := fsi.ReadLinkMeta(p.Dir)
+	if err != nil {
+		return err
+	}
+	restore := map[string]bool{p.Component: true}
+	if lm.BodyFormat != "" && (p.Component == "body" || p.Component == "structure") {
+		// body and structure return to the stored format together
+		restore = map[string]bool{"body": true, "structure": true}
+	}
+
 	for _, compName := range component.AllSubcomponentNames() {
-		if p.Component == "" || p.Component == compName {
+		if p.Component == "" || restore[compName] {
+			if lm.BodyFormat != "" && compName == "body" {
+				// remove the body in the working format, which may have a
+				// different filename than the stored body
+				fsi.DeleteComponent(diskContainer, compName, p.Dir)
+			}
 			if repoContainer.Base().GetSubcomponent(compName) == nil {
 				fsi.DeleteComponent(diskContainer, compName, p.Dir)
 			} else {
@@ -341,7 +413,16 @@ func (m *FSIMethods) Restore(p *RestoreParams, out *string) (err error) {
 			}
 		}
 	}
-	return nil
+
+	// Restored components are no longer placeholders.
+	if p.Component == "" {
+		lm.CheckOut()
+	} else {
+		for compName := range restore {
+			lm.CheckOut(compName)
+		}
+	}
+	return fsi.WriteLinkMeta(p.Dir, lm)
 }
 
 // InitFSIDatasetParams proxies parameters to initialization
@@ -388,3 +469,120 @@ func (m *FSIMethods) EnsureRef(p *EnsureParams, out *bool) error {
 
 	return m.inst.fsi.ModifyLinkDirectory(p.Dir, p.Ref)
 }
+
+// StashParams holds parameters for stashing working directory changes
+type StashParams struct {
+	Ref     string
+	Message string
+}
+
+// Stash sets aside uncommitted changes in a dataset's working directory
+// without creating a version, returning the directory to the dataset's head
+func (m *FSIMethods) Stash(p *StashParams, res *fsi.StashEntry) (err error) {
+	return m.StashContext(context.TODO(), p, res)
+}
+
+// StashContext is Stash with a context
+func (m *FSIMethods) StashContext(ctx context.Context, p *StashParams, res *fsi.StashEntry) (err error) {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.Stash", p, res))
+	}
+
+	dir, err := m.linkedDir(p.Ref)
+	if err != nil {
+		return err
+	}
+	e, err := m.inst.fsi.Stash(ctx, dir, p.Message)
+	if e != nil {
+		*res = *e
+	}
+	return err
+}
+
+// StashPopParams holds parameters for re-applying stashed changes
+type StashPopParams struct {
+	Ref string
+	// stash entry to pop, 0 is the most recent
+	Index int
+	// apply changes even if the working directory isn't clean or the stash
+	// conflicts with new versions of the dataset
+	Force bool
+}
+
+// StashPopResult describes re-applied stash changes
+type StashPopResult struct {
+	Entry fsi.StashEntry
+	// components changed both in the stash & in versions saved since
+	Conflicts []string
+}
+
+// StashPop re-applies stashed changes to a dataset's working directory
+func (m *FSIMethods) StashPop(p *StashPopParams, res *StashPopResult) (err error) {
+	return m.StashPopContext(context.TODO(), p, res)
+}
+
+// StashPopContext is StashPop with a context
+func (m *FSIMethods) StashPopContext(ctx context.Context, p *StashPopParams, res *StashPopResult) (err error) {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "FSIMethods.StashPop", p, res))
+	}
+
+	dir, err := m.linkedDir(p.Ref)
+	if err != nil {
+		return err
+	}
+	e, conflicts, err := m.inst.fsi.StashPop(ctx, dir, p.Index, p.Force)
+	if e != nil {
+		res.Entry = *e
+	}
+	res.Conflicts = conflicts
+	if err == fsi.ErrStashConflict {
+		return errors.New(err, fmt.Sprintf("stashed changes conflict with new versions of the dataset: %s. use --force to apply them anyway", strings.Join(conflicts, ", ")))
+	}
+	return err
+}
+
+// StashListParams holds parameters for listing stashed changes
+type StashListParams struct {
+	Ref string
+}
+
+// StashList lists stashed changes for a dataset, most recent first
+func (m *FSIMethods) StashList(p *StashListParams, res *[]fsi.StashEntry) (err error) {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("FSIMethods.StashList", p, res))
+	}
+
+	dir, err := m.linkedDir(p.Ref)
+	if err != nil {
+		return err
+	}
+	list, err := m.inst.fsi.StashList(dir)
+	if err != nil {
+		return err
+	}
+	entries := make([]fsi.StashEntry, len(list))
+	for i, e := range list {
+		entries[i] = *e
+	}
+	*res = entries
+	return nil
+}
+
+// linkedDir resolves a dataset reference to its working directory
+func (m *FSIMethods) linkedDir(refstr string) (string, error) {
+	if refstr == "" {
+		return "", repo.ErrEmptyRef
+	}
+	ref, err := repo.ParseDatasetRef(refstr)
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a valid dataset reference", refstr)
+	}
+	if err = repo.CanonicalizeDatasetRef(m.inst.node.Repo, &ref); err != nil && err != repo.ErrNoHistory {
+		return "", err
+	}
+	if ref.FSIPath == "" {
+		return "", fmt.Errorf("%s is not linked to a working directory", ref.AliasString())
+	}
+	return ref.FSIPath, nil
+}
@@ -9,8 +9,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net"
-	"net/rpc"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,6 +18,7 @@ import (
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/cafs"
+	"github.com/qri-io/qri/apitoken"
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/config/migrate"
@@ -28,6 +27,7 @@ import (
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logging"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/registry/regclient"
 	"github.com/qri-io/qri/remote"
@@ -35,6 +35,7 @@ import (
 	"github.com/qri-io/qri/repo/buildrepo"
 	fsrepo "github.com/qri-io/qri/repo/fs"
 	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/rpc"
 	"github.com/qri-io/qri/stats"
 	"github.com/qri-io/qri/watchfs"
 )
@@ -49,6 +50,8 @@ var (
 	defaultIPFSLocation = "$HOME/.ipfs"
 
 	log = golog.Logger("lib")
+	// reqLog logs lines tagged with the request ID of a context
+	reqLog = logging.NewLogger("lib")
 )
 
 func init() {
@@ -79,6 +82,9 @@ func Receivers(inst *Instance) []Methods {
 		NewSQLMethods(inst),
 		NewRenderRequests(r, nil),
 		NewFSIMethods(inst),
+		NewTransformMethods(inst),
+		NewAPITokenMethods(inst),
+		NewTenantMethods(inst),
 	}
 }
 
@@ -307,15 +313,23 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		streams:  o.Streams,
 		registry: o.regclient,
 		logbook:  o.logbook,
-		bus:      event.NewBus(ctx),
 	}
 	qri = inst
 
+	if inst.bus, err = newEventBus(ctx, repoPath, cfg); err != nil {
+		return nil, err
+	}
+
 	// configure logging straight away
 	if cfg != nil && cfg.Logging != nil {
 		for name, level := range cfg.Logging.Levels {
 			golog.SetLogLevel(name, level)
 		}
+		if cfg.Logging.Format != "" {
+			if err := logging.SetFormat(cfg.Logging.Format, os.Stderr); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// if logAll is enabled, turn on debug level logging for all qri packages. Packages need to
@@ -330,13 +344,14 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 
 	// check if we're operating over RPC
 	if cfg.RPC.Enabled {
-		addr := fmt.Sprintf(":%d", cfg.RPC.Port)
-		conn, err := net.Dial("tcp", addr)
-		if err == nil {
+		socket := rpc.SocketPath(repoPath, cfg.RPC.Socket)
+		if cli, err := rpc.Dial(ctx, socket); err == nil {
 			// we have a connection
-			log.Debugf("using RPC address %s", addr)
-			inst.rpc = rpc.NewClient(conn)
-			return qri, err
+			log.Debugf("using RPC socket %s", socket)
+			inst.rpc = cli
+			return qri, nil
+		} else if err == rpc.ErrUnauthorized {
+			return nil, fmt.Errorf("connecting to qri daemon: %s", err)
 		}
 	}
 
@@ -398,8 +413,20 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		_ = base.SetFileHidden(inst.repoPath)
 
 		inst.fsi = fsi.NewFSI(inst.repo, inst.bus)
+		// persist working directory statuses between commands
+		inst.fsi.SetStatusCache(fsi.NewStatusCache(fsi.StatusCachePath(inst.repoPath)))
+		inst.fsi.SetStashStore(fsi.NewStashStore(fsi.StashPath(inst.repoPath)))
 	}
 
+	tokensPath := ""
+	if inst.repoPath != "" {
+		tokensPath = apitoken.StorePath(inst.repoPath)
+	}
+	if inst.apiTokens, err = apitoken.NewStore(tokensPath); err != nil {
+		return nil, err
+	}
+	inst.tenants = &tenants{instances: map[string]*Instance{}}
+
 	if inst.node == nil {
 		if inst.node, err = p2p.NewQriNode(inst.repo, cfg.P2P); err != nil {
 			log.Error("intializing p2p:", err.Error())
@@ -481,8 +508,17 @@ func newDscache(ctx context.Context, fs qfs.Filesystem, book *logbook.Book, cfg
 	return dscache.NewDscache(ctx, fs, book, dscachePath), nil
 }
 
-func newEventBus(ctx context.Context) event.Bus {
-	return event.NewBus(ctx)
+func newEventBus(ctx context.Context, repoPath string, cfg *config.Config) (event.Bus, error) {
+	// The event journal lives at repoPath/events.jsonl, and is only kept
+	// when configured with a size
+	if repoPath == "" || cfg.Repo == nil || cfg.Repo.EventJournalSize == 0 {
+		return event.NewBus(ctx), nil
+	}
+	j, err := event.NewJournal(filepath.Join(repoPath, event.JournalFilename), cfg.Repo.EventJournalSize)
+	if err != nil {
+		return nil, fmt.Errorf("opening event journal: %s", err)
+	}
+	return event.NewBusWithJournal(ctx, j), nil
 }
 
 func newRepo(path string, cfg *config.Config, store cafs.Filestore, fs qfs.Filesystem, book *logbook.Book, cache *dscache.Dscache) (r repo.Repo, err error) {
@@ -558,6 +594,7 @@ func NewInstanceFromConfigAndNode(cfg *config.Config, node *p2p.QriNode) *Instan
 		inst.bus = event.NewBus(ctx)
 		inst.fsi = fsi.NewFSI(inst.repo, inst.bus)
 	}
+	inst.apiTokens, _ = apitoken.NewStore("")
 
 	return inst
 }
@@ -588,6 +625,13 @@ type Instance struct {
 	logbook      *logbook.Book
 	dscache      *dscache.Dscache
 	bus          event.Bus
+	apiTokens    *apitoken.Store
+
+	// tenant is the peername of the tenant profile this instance acts as,
+	// empty for a host
+	tenant string
+	// tenants a host has opened, nil for instances that can't host tenants
+	tenants *tenants
 
 	Watcher *watchfs.FilesysWatcher
 
@@ -634,17 +678,36 @@ func (inst *Instance) Bus() event.Bus {
 	return inst.bus
 }
 
-// ChangeConfig implements the ConfigSetter interface
+// APITokens returns the store of tokens that authorize API requests
+func (inst *Instance) APITokens() *apitoken.Store {
+	return inst.apiTokens
+}
+
+// publish sends an event to the instance bus, if one exists. Events carry
+// the request ID of ctx, so RPC clients only see events their calls caused
+func (inst *Instance) publish(ctx context.Context, t event.Topic, payload interface{}) {
+	if inst.bus != nil {
+		inst.bus.PublishContext(ctx, t, payload)
+	}
+}
+
+// ChangeConfig implements the ConfigSetter interface. Tenants only persist
+// changes to their profile
 func (inst *Instance) ChangeConfig(cfg *config.Config) (err error) {
 	cfg = cfg.WithPrivateValues(inst.cfg)
 
-	if path := inst.cfg.Path(); path != "" {
+	if inst.tenant != "" {
+		if err = writeTenantProfile(inst.repoPath, cfg.Profile); err != nil {
+			return
+		}
+	} else if path := inst.cfg.Path(); path != "" {
 		if err = cfg.WriteToFile(path); err != nil {
 			return
 		}
 	}
 
 	inst.cfg = cfg
+	inst.publish(inst.ctx, event.ETConfigChanged, event.ConfigChangedEvent{Config: cfg.WithoutPrivateValues()})
 	return nil
 }
 
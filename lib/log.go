@@ -39,10 +39,14 @@ type DatasetLogItem = logbook.DatasetLogItem
 
 // Log returns the history of changes for a given dataset
 func (m *LogMethods) Log(params *LogParams, res *[]DatasetLogItem) error {
+	return m.LogContext(context.TODO(), params, res)
+}
+
+// LogContext is Log with a context
+func (m *LogMethods) LogContext(ctx context.Context, params *LogParams, res *[]DatasetLogItem) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.Log", params, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "LogMethods.Log", params, res))
 	}
-	ctx := context.TODO()
 
 	if params.Ref == "" {
 		return repo.ErrEmptyRef
@@ -85,10 +89,14 @@ type LogEntry = logbook.LogEntry
 
 // Logbook lists log entries for actions taken on a given dataset
 func (m *LogMethods) Logbook(p *RefListParams, res *[]LogEntry) error {
+	return m.LogbookContext(context.TODO(), p, res)
+}
+
+// LogbookContext is Logbook with a context
+func (m *LogMethods) LogbookContext(ctx context.Context, p *RefListParams, res *[]LogEntry) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.Logbook", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "LogMethods.Logbook", p, res))
 	}
-	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
 	if err != nil {
@@ -113,11 +121,15 @@ type PlainLogs = []logbook.PlainLog
 
 // PlainLogs encodes the full logbook as human-oriented json
 func (m *LogMethods) PlainLogs(p *PlainLogsParams, res *PlainLogs) error {
+	return m.PlainLogsContext(context.TODO(), p, res)
+}
+
+// PlainLogsContext is PlainLogs with a context
+func (m *LogMethods) PlainLogsContext(ctx context.Context, p *PlainLogsParams, res *PlainLogs) error {
 	var err error
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("LogMethods.PlainLogs", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "LogMethods.PlainLogs", p, res))
 	}
-	ctx := context.TODO()
 	*res, err = m.inst.repo.Logbook().PlainLogs(ctx)
 	return err
 }
@@ -160,10 +160,14 @@ func (m *ProfileMethods) SaveProfile(p *config.ProfilePod, res *config.ProfilePo
 
 // ProfilePhoto fetches the byte slice of a given user's profile photo
 func (m *ProfileMethods) ProfilePhoto(req *config.ProfilePod, res *[]byte) (err error) {
+	return m.ProfilePhotoContext(context.TODO(), req, res)
+}
+
+// ProfilePhotoContext is ProfilePhoto with a context
+func (m *ProfileMethods) ProfilePhotoContext(ctx context.Context, req *config.ProfilePod, res *[]byte) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.ProfilePhoto", req, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "ProfileMethods.ProfilePhoto", req, res))
 	}
-	ctx := context.TODO()
 
 	r := m.inst.repo
 
@@ -194,10 +198,14 @@ type FileParams struct {
 
 // SetProfilePhoto changes this peer's profile image
 func (m *ProfileMethods) SetProfilePhoto(p *FileParams, res *config.ProfilePod) error {
+	return m.SetProfilePhotoContext(context.TODO(), p, res)
+}
+
+// SetProfilePhotoContext is SetProfilePhoto with a context
+func (m *ProfileMethods) SetProfilePhotoContext(ctx context.Context, p *FileParams, res *config.ProfilePod) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.SetProfilePhoto", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "ProfileMethods.SetProfilePhoto", p, res))
 	}
-	ctx := context.TODO()
 
 	r := m.inst.repo
 
@@ -208,7 +216,7 @@ func (m *ProfileMethods) SetProfilePhoto(p *FileParams, res *config.ProfilePod)
 	// TODO - make the reader be a sizefile to avoid this double-read
 	data, err := ioutil.ReadAll(p.Data)
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return fmt.Errorf("error reading file data: %s", err.Error())
 	}
 	if len(data) > 250000 {
@@ -225,7 +233,7 @@ func (m *ProfileMethods) SetProfilePhoto(p *FileParams, res *config.ProfilePod)
 	// TODO - if file extension is .jpg / .jpeg ipfs does weird shit that makes this not work
 	path, err := r.Store().Put(ctx, qfs.NewMemfileBytes("plz_just_encode", data))
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return fmt.Errorf("error saving photo: %s", err.Error())
 	}
 
@@ -260,10 +268,14 @@ func (m *ProfileMethods) SetProfilePhoto(p *FileParams, res *config.ProfilePod)
 
 // PosterPhoto fetches the byte slice of a given user's poster photo
 func (m *ProfileMethods) PosterPhoto(req *config.ProfilePod, res *[]byte) (err error) {
+	return m.PosterPhotoContext(context.TODO(), req, res)
+}
+
+// PosterPhotoContext is PosterPhoto with a context
+func (m *ProfileMethods) PosterPhotoContext(ctx context.Context, req *config.ProfilePod, res *[]byte) (err error) {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.PostPhoto", req, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "ProfileMethods.PostPhoto", req, res))
 	}
-	ctx := context.TODO()
 
 	r := m.inst.repo
 	pro, e := m.getProfile(r, req.ID, req.Peername)
@@ -286,10 +298,14 @@ func (m *ProfileMethods) PosterPhoto(req *config.ProfilePod, res *[]byte) (err e
 
 // SetPosterPhoto changes this peer's poster image
 func (m *ProfileMethods) SetPosterPhoto(p *FileParams, res *config.ProfilePod) error {
+	return m.SetPosterPhotoContext(context.TODO(), p, res)
+}
+
+// SetPosterPhotoContext is SetPosterPhoto with a context
+func (m *ProfileMethods) SetPosterPhotoContext(ctx context.Context, p *FileParams, res *config.ProfilePod) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("ProfileMethods.SetPosterPhoto", p, res))
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "ProfileMethods.SetPosterPhoto", p, res))
 	}
-	ctx := context.TODO()
 
 	if p.Data == nil {
 		return fmt.Errorf("file is required")
@@ -300,7 +316,7 @@ func (m *ProfileMethods) SetPosterPhoto(p *FileParams, res *config.ProfilePod) e
 	// TODO - make the reader be a sizefile to avoid this double-read
 	data, err := ioutil.ReadAll(p.Data)
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 		return fmt.Errorf("error reading file data: %s", err.Error())
 	}
 
@@ -318,7 +334,7 @@ func (m *ProfileMethods) SetPosterPhoto(p *FileParams, res *config.ProfilePod) e
 	// TODO - if file extension is .jpg / .jpeg ipfs does weird shit that makes this not work
 	path, err := r.Store().Put(ctx, qfs.NewMemfileBytes("plz_just_encode", data))
 	if err != nil {
-		log.Debug(err.Error())
+		reqLog.Debugf(ctx, "%s", err)
 
 		return fmt.Errorf("error saving photo: %s", err.Error())
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo"
@@ -40,8 +41,13 @@ type FetchParams struct {
 
 // Fetch pulls a logbook from a remote
 func (r *RemoteMethods) Fetch(p *FetchParams, res *[]DatasetLogItem) error {
+	return r.FetchContext(context.TODO(), p, res)
+}
+
+// FetchContext is Fetch with a context
+func (r *RemoteMethods) FetchContext(ctx context.Context, p *FetchParams, res *[]DatasetLogItem) error {
 	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Fetch", p, res))
+		return checkRPCError(r.inst.rpc.CallContext(ctx, "RemoteMethods.Fetch", p, res))
 	}
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
@@ -62,7 +68,6 @@ func (r *RemoteMethods) Fetch(p *FetchParams, res *[]DatasetLogItem) error {
 	}
 
 	// TODO (b5) - need contexts yo
-	ctx := context.TODO()
 	logs, err := r.inst.RemoteClient().FetchLogs(ctx, reporef.ConvertToDsref(ref), addr)
 	if err != nil {
 		return err
@@ -80,7 +85,7 @@ func (r *RemoteMethods) Fetch(p *FetchParams, res *[]DatasetLogItem) error {
 	}
 
 	items := logbook.ConvertLogsToItems(logs, reporef.ConvertToDsref(ref))
-	log.Debugf("found %d items: %v", len(items), items)
+	reqLog.Debugf(ctx, "found %d items: %v", len(items), items)
 	if len(items) == 0 {
 		return repo.ErrNoHistory
 	}
@@ -116,8 +121,14 @@ type PublicationParams struct {
 
 // Publish posts a dataset version to a remote
 func (r *RemoteMethods) Publish(p *PublicationParams, res *dsref.Ref) error {
+	return r.PublishContext(context.TODO(), p, res)
+}
+
+// PublishContext is Publish with a context. The request ID of ctx is sent to
+// the remote
+func (r *RemoteMethods) PublishContext(ctx context.Context, p *PublicationParams, res *dsref.Ref) error {
 	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Publish", p, res))
+		return checkRPCError(r.inst.rpc.CallContext(ctx, "RemoteMethods.Publish", p, res))
 	}
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
@@ -136,15 +147,12 @@ func (r *RemoteMethods) Publish(p *PublicationParams, res *dsref.Ref) error {
 		return err
 	}
 
-	// TODO (b5) - need contexts yo
-	ctx := context.TODO()
-
 	// TODO (b5) - we're early in log syncronization days. This is going to fail a bunch
 	// while we work to upgrade the stack. Long term we may want to consider a mechanism
 	// for allowing partial completion where only one of logs or dataset pushing works
 	// by doing both in parallel and reporting issues on both
 	if pushLogsErr := r.inst.RemoteClient().PushLogs(ctx, reporef.ConvertToDsref(ref), addr); pushLogsErr != nil {
-		log.Errorf("pushing logs: %s", pushLogsErr)
+		reqLog.Errorf(ctx, "pushing logs: %s", pushLogsErr)
 	}
 
 	if err = r.inst.RemoteClient().PushDataset(ctx, ref, addr); err != nil {
@@ -157,13 +165,20 @@ func (r *RemoteMethods) Publish(p *PublicationParams, res *dsref.Ref) error {
 	}
 
 	*res = reporef.ConvertToDsref(ref)
+	publishEvent(ctx, r.inst, ref, p.RemoteName)
 	return nil
 }
 
 // Unpublish asks a remote to remove a dataset
 func (r *RemoteMethods) Unpublish(p *PublicationParams, res *dsref.Ref) error {
+	return r.UnpublishContext(context.TODO(), p, res)
+}
+
+// UnpublishContext is Unpublish with a context. The request ID of ctx is sent
+// to the remote
+func (r *RemoteMethods) UnpublishContext(ctx context.Context, p *PublicationParams, res *dsref.Ref) error {
 	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Unpublish", p, res))
+		return checkRPCError(r.inst.rpc.CallContext(ctx, "RemoteMethods.Unpublish", p, res))
 	}
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
@@ -184,15 +199,12 @@ func (r *RemoteMethods) Unpublish(p *PublicationParams, res *dsref.Ref) error {
 		return err
 	}
 
-	// TODO (b5) - need contexts yo
-	ctx := context.TODO()
-
 	// TODO (b5) - we're early in log syncronization days. This is going to fail a bunch
 	// while we work to upgrade the stack. Long term we may want to consider a mechanism
 	// for allowing partial completion where only one of logs or dataset pushing works
 	// by doing both in parallel and reporting issues on both
 	if removeLogsErr := r.inst.RemoteClient().RemoveLogs(ctx, reporef.ConvertToDsref(ref), addr); removeLogsErr != nil {
-		log.Errorf("removing logs: %s", removeLogsErr.Error())
+		reqLog.Errorf(ctx, "removing logs: %s", removeLogsErr.Error())
 	}
 
 	if err := r.inst.RemoteClient().RemoveDataset(ctx, ref, addr); err != nil {
@@ -205,13 +217,32 @@ func (r *RemoteMethods) Unpublish(p *PublicationParams, res *dsref.Ref) error {
 	}
 
 	*res = reporef.ConvertToDsref(ref)
+	publishEvent(ctx, r.inst, ref, p.RemoteName)
 	return nil
 }
 
+// publishEvent announces a change to the publicity of a dataset
+func publishEvent(ctx context.Context, inst *Instance, ref reporef.DatasetRef, remoteName string) {
+	t := event.ETDatasetUnpublished
+	if ref.Published {
+		t = event.ETDatasetPublished
+	}
+	inst.publish(ctx, t, event.DatasetPublicationEvent{
+		Ref:    reporef.ConvertToDsref(ref),
+		Remote: remoteName,
+	})
+}
+
 // PullDataset fetches a dataset ref from a remote
 func (r *RemoteMethods) PullDataset(p *PublicationParams, res *bool) error {
+	return r.PullDatasetContext(context.TODO(), p, res)
+}
+
+// PullDatasetContext is PullDataset with a context. The request ID of ctx is
+// sent to the remote
+func (r *RemoteMethods) PullDatasetContext(ctx context.Context, p *PublicationParams, res *bool) error {
 	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.PullDataset", p, res))
+		return checkRPCError(r.inst.rpc.CallContext(ctx, "RemoteMethods.PullDataset", p, res))
 	}
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
@@ -219,20 +250,27 @@ func (r *RemoteMethods) PullDataset(p *PublicationParams, res *bool) error {
 		return err
 	}
 
-	// TODO (b5) - need contexts yo
-	ctx := context.TODO()
-
-	err = r.inst.RemoteClient().PullDataset(ctx, &ref, p.RemoteName)
-	return err
+	if err = r.inst.RemoteClient().PullDataset(ctx, &ref, p.RemoteName); err != nil {
+		return err
+	}
+	r.inst.publish(ctx, event.ETDatasetPulled, event.DatasetPulledEvent{
+		Ref:    reporef.ConvertToDsref(ref),
+		Remote: p.RemoteName,
+	})
+	return nil
 }
 
 // Feeds returns a listing of datasets from a number of feeds like featured and
 // popular. Each feed is keyed by string in the response
 func (r *RemoteMethods) Feeds(remoteName *string, res *map[string][]dsref.VersionInfo) error {
+	return r.FeedsContext(context.TODO(), remoteName, res)
+}
+
+// FeedsContext is Feeds with a context
+func (r *RemoteMethods) FeedsContext(ctx context.Context, remoteName *string, res *map[string][]dsref.VersionInfo) error {
 	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Feeds", remoteName, res))
+		return checkRPCError(r.inst.rpc.CallContext(ctx, "RemoteMethods.Feeds", remoteName, res))
 	}
-	ctx := context.TODO()
 
 	addr, err := remote.Address(r.inst.Config(), *remoteName)
 	if err != nil {
@@ -256,10 +294,14 @@ type PreviewParams struct {
 
 // Preview requests a dataset preview from a remote
 func (r *RemoteMethods) Preview(p *PreviewParams, res *dataset.Dataset) error {
+	return r.PreviewContext(context.TODO(), p, res)
+}
+
+// PreviewContext is Preview with a context
+func (r *RemoteMethods) PreviewContext(ctx context.Context, p *PreviewParams, res *dataset.Dataset) error {
 	if r.inst.rpc != nil {
-		return checkRPCError(r.inst.rpc.Call("RemoteMethods.Preview", p, res))
+		return checkRPCError(r.inst.rpc.CallContext(ctx, "RemoteMethods.Preview", p, res))
 	}
-	ctx := context.TODO()
 
 	ref, err := repo.ParseDatasetRef(p.Ref)
 	if err != nil {
@@ -3,7 +3,6 @@ package lib
 import (
 	"context"
 	"fmt"
-	"net/rpc"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/base"
@@ -11,6 +10,7 @@ import (
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/rpc"
 )
 
 // RenderRequests encapsulates business logic for this node's
@@ -62,10 +62,14 @@ func (p *RenderParams) Validate() error {
 
 // RenderViz renders a viz component as html
 func (r *RenderRequests) RenderViz(p *RenderParams, res *[]byte) (err error) {
+	return r.RenderVizContext(context.TODO(), p, res)
+}
+
+// RenderVizContext is RenderViz with a context
+func (r *RenderRequests) RenderVizContext(ctx context.Context, p *RenderParams, res *[]byte) (err error) {
 	if r.cli != nil {
-		return checkRPCError(r.cli.Call("RenderRequests.RenderViz", p, res))
+		return checkRPCError(r.cli.CallContext(ctx, "RenderRequests.RenderViz", p, res))
 	}
-	ctx := context.TODO()
 
 	if err = p.Validate(); err != nil {
 		return err
@@ -92,10 +96,14 @@ func (r *RenderRequests) RenderViz(p *RenderParams, res *[]byte) (err error) {
 
 // RenderReadme renders the readme into html for the given dataset
 func (r *RenderRequests) RenderReadme(p *RenderParams, res *string) (err error) {
+	return r.RenderReadmeContext(context.TODO(), p, res)
+}
+
+// RenderReadmeContext is RenderReadme with a context
+func (r *RenderRequests) RenderReadmeContext(ctx context.Context, p *RenderParams, res *string) (err error) {
 	if r.cli != nil {
-		return checkRPCError(r.cli.Call("RenderRequests.RenderReadme", p, res))
+		return checkRPCError(r.cli.CallContext(ctx, "RenderRequests.RenderReadme", p, res))
 	}
-	ctx := context.TODO()
 
 	if err = p.Validate(); err != nil {
 		return err
@@ -111,7 +119,7 @@ func (r *RenderRequests) RenderReadme(p *RenderParams, res *string) (err error)
 		}
 
 		if ref.FSIPath != "" {
-			if ds, err = fsi.ReadDir(ref.FSIPath); err != nil {
+			if ds, err = fsi.ReadWorkingDataset(ctx, ref.FSIPath, r.repo.Store(), ref.Path); err != nil {
 				return fmt.Errorf("loading linked dataset: %s", err)
 			}
 		} else {
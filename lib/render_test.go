@@ -2,10 +2,6 @@ package lib
 
 import (
 	"context"
-	"net"
-	"net/http"
-	"net/http/httptest"
-	"net/rpc"
 	"testing"
 	"time"
 
@@ -18,6 +14,7 @@ import (
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
 	testrepo "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/rpc"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
@@ -34,20 +31,13 @@ func TestNewRenderRequests(t *testing.T) {
 		return
 	}
 
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte{}) }))
-	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
-	if err != nil {
-		t.Errorf("error allocating listener: %s", err.Error())
-		return
-	}
-
 	reqs := NewRenderRequests(tr, nil)
 	if reqs.CoreRequestsName() != "render" {
 		t.Errorf("invalid requests name. expected: '%s', got: '%s'", "render", reqs.CoreRequestsName())
 	}
 
 	// this should panic, triggering the defer statement above
-	NewRenderRequests(tr, rpc.NewClient(conn))
+	NewRenderRequests(tr, &rpc.Client{})
 }
 
 func TestRenderRequestsRender(t *testing.T) {
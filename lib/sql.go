@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 
+	"github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/sql"
 )
 
@@ -27,26 +29,48 @@ func (m SQLMethods) CoreRequestsName() string { return "sql" }
 type SQLQueryParams struct {
 	Query        string
 	OutputFormat string
+	// MaxRows truncates results after this many rows. 0 means no limit.
+	// ExecStream returns sql.ErrRowLimit when results are truncated
+	MaxRows int
 }
 
 // Exec runs an SQL query
 func (m *SQLMethods) Exec(p *SQLQueryParams, results *[]byte) error {
+	return m.ExecContext(context.TODO(), p, results)
+}
+
+// ExecContext is Exec with a context. Cancelling ctx stops the query,
+// including queries running in a connected daemon
+func (m *SQLMethods) ExecContext(ctx context.Context, p *SQLQueryParams, results *[]byte) error {
 	if m.inst.rpc != nil {
-		return checkRPCError(m.inst.rpc.Call("SQLMethods.Exec", p, results))
-	}
-	if p == nil {
-		return fmt.Errorf("error: search params cannot be nil")
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "SQLMethods.Exec", p, results))
 	}
-	ctx := context.TODO()
-
-	svc := sql.New(m.inst.repo)
 
 	buf := &bytes.Buffer{}
-
-	if err := svc.Exec(ctx, buf, p.OutputFormat, p.Query); err != nil {
+	if err := m.exec(ctx, buf, p); err != nil && !errors.Is(err, sql.ErrRowLimit) {
 		return err
 	}
 
 	*results = buf.Bytes()
 	return nil
 }
+
+// ExecStream runs an SQL query, writing results to w as they're produced.
+// Queries stop when ctx is done. ExecStream doesn't work over RPC
+func (m *SQLMethods) ExecStream(ctx context.Context, w io.Writer, p *SQLQueryParams) error {
+	if m.inst.rpc != nil {
+		return fmt.Errorf("streaming SQL results isn't supported over RPC")
+	}
+	return m.exec(ctx, w, p)
+}
+
+func (m *SQLMethods) exec(ctx context.Context, w io.Writer, p *SQLQueryParams) error {
+	if p == nil {
+		return fmt.Errorf("error: search params cannot be nil")
+	}
+
+	svc := sql.New(m.inst.repo, func(o *sql.Options) {
+		o.MaxRows = p.MaxRows
+	})
+	return svc.Exec(ctx, w, p.OutputFormat, p.Query)
+}
@@ -0,0 +1,334 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+// ErrTenantNotFound is returned when a host has no tenant with a peername
+var ErrTenantNotFound = fmt.Errorf("tenant not found")
+
+// ErrTenantsRequireAuth is returned when a host that doesn't require API
+// authorization is asked to add or serve tenants. Without it, requests that
+// carry no token act as the host
+var ErrTenantsRequireAuth = fmt.Errorf("hosting tenants requires API authorization. run `qri config set api.requireauth true` to require it")
+
+const (
+	// tenantsDir is the directory within a host repo that holds tenant repos
+	tenantsDir = "tenants"
+	// tenantProfileFilename is the file within a tenant repo that stores the
+	// tenant's profile & private key
+	tenantProfileFilename = "profile.json"
+)
+
+// tenants keeps the instances of tenants a host has opened
+type tenants struct {
+	lk        sync.Mutex
+	instances map[string]*Instance
+}
+
+// tenantPath is the repo directory of a tenant
+func tenantPath(repoPath, peername string) string {
+	return filepath.Join(repoPath, tenantsDir, peername)
+}
+
+// Tenant returns the instance of a tenant profile this instance hosts.
+// Tenants have their own profile, keypair, logbook & refs, and share the
+// host's content store. An empty peername, or the host's own peername,
+// returns the host
+func (inst *Instance) Tenant(peername string) (*Instance, error) {
+	if peername == "" || peername == inst.cfg.Profile.Peername {
+		return inst, nil
+	}
+	if inst.tenants == nil {
+		return nil, fmt.Errorf("this instance can't host tenants")
+	}
+	if !inst.requiresAuth() {
+		return nil, ErrTenantsRequireAuth
+	}
+
+	inst.tenants.lk.Lock()
+	defer inst.tenants.lk.Unlock()
+	if t, ok := inst.tenants.instances[peername]; ok {
+		return t, nil
+	}
+	t, err := inst.openTenant(peername)
+	if err != nil {
+		return nil, err
+	}
+	inst.tenants.instances[peername] = t
+	return t, nil
+}
+
+// requiresAuth reports whether the API this instance serves requires
+// requests to be authorized
+func (inst *Instance) requiresAuth() bool {
+	return inst.cfg.API != nil && inst.cfg.API.RequireAuth
+}
+
+// TenantName returns the peername of the tenant an instance acts as, or an
+// empty string for a host
+func (inst *Instance) TenantName() string {
+	if inst == nil {
+		return ""
+	}
+	return inst.tenant
+}
+
+// openTenant creates an instance for a tenant repo. Tenants are offline:
+// they don't run a p2p node or a remote of their own, and have their own
+// event bus. Config changes a tenant makes only persist its profile
+func (inst *Instance) openTenant(peername string) (*Instance, error) {
+	dir := tenantPath(inst.repoPath, peername)
+	pro, err := readTenantProfile(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := inst.cfg.Copy()
+	cfg.SetPath("")
+	cfg.Profile = pro
+
+	ctx, teardown := context.WithCancel(inst.ctx)
+	t := &Instance{
+		ctx:       ctx,
+		teardown:  teardown,
+		repoPath:  dir,
+		cfg:       cfg,
+		tenant:    peername,
+		streams:   inst.streams,
+		registry:  inst.registry,
+		stats:     inst.stats,
+		apiTokens: inst.apiTokens,
+		bus:       event.NewBus(ctx),
+	}
+
+	// use the host repo's store, which changes when the host goes online
+	hostRepo := inst.Repo()
+	t.store = hostRepo.Store()
+	t.qfs = hostRepo.Filesystem()
+
+	if t.logbook, err = newLogbook(t.qfs, cfg, dir); err != nil {
+		return nil, fmt.Errorf("newLogbook: %w", err)
+	}
+	if t.dscache, err = newDscache(ctx, t.qfs, t.logbook, cfg, dir); err != nil {
+		return nil, fmt.Errorf("newDscache: %w", err)
+	}
+	if t.repo, err = newRepo(dir, cfg, t.store, t.qfs, t.logbook, t.dscache); err != nil {
+		return nil, fmt.Errorf("newRepo: %s", err)
+	}
+
+	t.fsi = fsi.NewFSI(t.repo, t.bus)
+	t.fsi.SetStatusCache(fsi.NewStatusCache(fsi.StatusCachePath(dir)))
+	t.fsi.SetStashStore(fsi.NewStashStore(fsi.StashPath(dir)))
+
+	if t.node, err = p2p.NewQriNode(t.repo, cfg.P2P); err != nil {
+		return nil, err
+	}
+	t.node.LocalStreams = inst.streams
+	if _, e := t.node.IPFSCoreAPI(); e == nil {
+		if t.remoteClient, err = remote.NewClient(t.node); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Debugf("opened tenant %s", peername)
+	return t, nil
+}
+
+// readTenantProfile loads the profile stored in a tenant repo
+func readTenantProfile(dir string) (*config.ProfilePod, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, tenantProfileFilename))
+	if os.IsNotExist(err) {
+		return nil, ErrTenantNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	pro := &config.ProfilePod{}
+	if err := json.Unmarshal(data, pro); err != nil {
+		return nil, fmt.Errorf("decoding tenant profile: %s", err)
+	}
+	return pro, nil
+}
+
+// writeTenantProfile stores a tenant profile. The file holds a private key,
+// so only the current user can read it
+func writeTenantProfile(dir string, pro *config.ProfilePod) error {
+	data, err := json.MarshalIndent(pro, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, tenantProfileFilename), data, 0600)
+}
+
+// TenantMethods manages the tenant profiles a host serves. Each tenant has
+// its own keypair, logbook & refs, and is selected per API request by
+// issuing api tokens for the tenant
+type TenantMethods struct {
+	inst *Instance
+}
+
+// NewTenantMethods creates TenantMethods from a qri Instance
+func NewTenantMethods(inst *Instance) *TenantMethods {
+	return &TenantMethods{inst: inst}
+}
+
+// CoreRequestsName implements the Methods interface
+func (m TenantMethods) CoreRequestsName() string { return "tenant" }
+
+// AddTenantParams defines parameters for adding a tenant
+type AddTenantParams struct {
+	Peername string
+	// PrivKey is the base64-encoded private key of the tenant profile
+	PrivKey string
+	// ProfileID is the ID of the tenant profile, derived from the key
+	ProfileID string
+}
+
+// Add creates a tenant profile
+func (m *TenantMethods) Add(p *AddTenantParams, res *config.ProfilePod) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("TenantMethods.Add", p, res))
+	}
+	if err := m.ensureHost(); err != nil {
+		return err
+	}
+	if !m.inst.requiresAuth() {
+		return ErrTenantsRequireAuth
+	}
+	if err := dsref.EnsureValidUsername(p.Peername); err != nil {
+		return errors.New(ErrBadArgs, err.Error())
+	}
+	if p.Peername == m.inst.cfg.Profile.Peername {
+		return errors.New(ErrBadArgs, "tenant peername can't be the host's peername")
+	}
+	if p.PrivKey == "" || p.ProfileID == "" {
+		return errors.New(ErrBadArgs, "tenant private key and profile ID are required")
+	}
+
+	now := time.Now()
+	pro := &config.ProfilePod{
+		ID:       p.ProfileID,
+		PrivKey:  p.PrivKey,
+		Peername: p.Peername,
+		Created:  now,
+		Updated:  now,
+		Type:     "peer",
+	}
+	if _, err := profile.NewProfile(pro); err != nil {
+		return errors.New(ErrBadArgs, err.Error())
+	}
+
+	dir := tenantPath(m.inst.repoPath, p.Peername)
+	if _, err := os.Stat(dir); err == nil {
+		return errors.New(ErrBadArgs, fmt.Sprintf("tenant %q already exists", p.Peername))
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := writeTenantProfile(dir, pro); err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+
+	*res = *pro
+	res.PrivKey = ""
+	return nil
+}
+
+// List returns the profiles of all tenants, sorted by peername. Private keys
+// aren't included
+func (m *TenantMethods) List(p *bool, res *[]config.ProfilePod) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("TenantMethods.List", p, res))
+	}
+	if err := m.ensureHost(); err != nil {
+		return err
+	}
+
+	infos, err := ioutil.ReadDir(filepath.Join(m.inst.repoPath, tenantsDir))
+	if os.IsNotExist(err) {
+		*res = []config.ProfilePod{}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	list := make([]config.ProfilePod, 0, len(infos))
+	for _, fi := range infos {
+		if !fi.IsDir() {
+			continue
+		}
+		pro, err := readTenantProfile(filepath.Join(m.inst.repoPath, tenantsDir, fi.Name()))
+		if err != nil {
+			log.Debugf("reading tenant %s: %s", fi.Name(), err)
+			continue
+		}
+		pro.PrivKey = ""
+		list = append(list, *pro)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Peername < list[j].Peername })
+	*res = list
+	return nil
+}
+
+// Remove deletes a tenant, including its repo & api tokens. Content the
+// tenant added to the shared store is kept
+func (m *TenantMethods) Remove(peername *string, res *bool) error {
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.Call("TenantMethods.Remove", peername, res))
+	}
+	if err := m.ensureHost(); err != nil {
+		return err
+	}
+	if *peername == "" || dsref.EnsureValidUsername(*peername) != nil {
+		return errors.New(ErrBadArgs, "a valid tenant peername is required")
+	}
+
+	dir := tenantPath(m.inst.repoPath, *peername)
+	if _, err := readTenantProfile(dir); err != nil {
+		return err
+	}
+
+	m.inst.tenants.lk.Lock()
+	if t, ok := m.inst.tenants.instances[*peername]; ok {
+		t.Teardown()
+		delete(m.inst.tenants.instances, *peername)
+	}
+	m.inst.tenants.lk.Unlock()
+
+	if m.inst.apiTokens != nil {
+		if err := m.inst.apiTokens.RevokeProfile(*peername); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	*res = true
+	return nil
+}
+
+// ensureHost checks tenants can be managed from this instance
+func (m *TenantMethods) ensureHost() error {
+	if m.inst.tenants == nil {
+		return fmt.Errorf("tenants can only be managed by a host with a repo on disk")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	cfgtest "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/p2p"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestTenantMethods(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_lib_tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, config.DefaultP2PForTesting())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(config.DefaultConfigForTesting(), node)
+	inst.repoPath = dir
+	inst.tenants = &tenants{instances: map[string]*Instance{}}
+	m := NewTenantMethods(inst)
+
+	info := cfgtest.GetTestPeerInfo(1)
+	if err := m.Add(&AddTenantParams{Peername: "alice", PrivKey: info.EncodedPrivKey, ProfileID: info.EncodedPeerID}, &config.ProfilePod{}); err != ErrTenantsRequireAuth {
+		t.Errorf("expected adding a tenant without api authorization to fail, got: %v", err)
+	}
+	inst.cfg.API.RequireAuth = true
+
+	bad := []struct {
+		p   AddTenantParams
+		err string
+	}{
+		{AddTenantParams{Peername: inst.cfg.Profile.Peername, PrivKey: info.EncodedPrivKey, ProfileID: info.EncodedPeerID}, "tenant peername can't be the host's peername"},
+		{AddTenantParams{Peername: "alice"}, "tenant private key and profile ID are required"},
+	}
+	for i, c := range bad {
+		res := config.ProfilePod{}
+		if err := m.Add(&c.p, &res); err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: %q, got: %v", i, c.err, err)
+		}
+	}
+
+	pro := config.ProfilePod{}
+	if err := m.Add(&AddTenantParams{Peername: "alice", PrivKey: info.EncodedPrivKey, ProfileID: info.EncodedPeerID}, &pro); err != nil {
+		t.Fatal(err)
+	}
+	if pro.PrivKey != "" {
+		t.Error("expected tenant private key to be omitted from the result")
+	}
+
+	list := []config.ProfilePod{}
+	if err := m.List(nil, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Peername != "alice" || list[0].PrivKey != "" {
+		t.Errorf("unexpected tenant list: %v", list)
+	}
+
+	alice, err := inst.Tenant("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alicePro, err := alice.Repo().Profile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alice.TenantName() != "alice" || alicePro.Peername != "alice" {
+		t.Errorf("expected tenant instance to act as alice")
+	}
+	if alicePro.ID.String() != info.EncodedPeerID {
+		t.Errorf("expected tenant to have its own profile ID, got %s", alicePro.ID)
+	}
+	if alice.Repo().Store() != inst.Repo().Store() {
+		t.Error("expected tenant to share the host store")
+	}
+	if again, _ := inst.Tenant("alice"); again != alice {
+		t.Error("expected tenant instance to be reused")
+	}
+	if host, _ := inst.Tenant(""); host != inst {
+		t.Error("expected an empty peername to return the host")
+	}
+	if _, err := inst.Tenant("bob"); err != ErrTenantNotFound {
+		t.Errorf("expected unknown tenant to be not found, got: %v", err)
+	}
+
+	// tenant tokens
+	tokens := NewAPITokenMethods(inst)
+	res := CreateAPITokenResult{}
+	if err := tokens.Create(&CreateAPITokenParams{Name: "alice", Scope: "admin", Profile: "alice"}, &res); err == nil {
+		t.Error("expected tenant admin token to be rejected")
+	}
+	if err := tokens.Create(&CreateAPITokenParams{Name: "alice", Scope: "publish", Profile: "alice"}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Token.Profile != "alice" {
+		t.Errorf("expected token to be issued for alice, got %q", res.Token.Profile)
+	}
+	if err := NewAPITokenMethods(alice).Create(&CreateAPITokenParams{Name: "x", Scope: "read"}, &res); err == nil {
+		t.Error("expected tenants to be unable to manage api tokens")
+	}
+
+	inst.cfg.API.RequireAuth = false
+	if _, err := inst.Tenant("alice"); err != ErrTenantsRequireAuth {
+		t.Errorf("expected tenants not to be served without api authorization, got: %v", err)
+	}
+	inst.cfg.API.RequireAuth = true
+
+	removed := false
+	name := "alice"
+	if err := m.Remove(&name, &removed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.APITokens().Verify(res.Secret); err == nil {
+		t.Error("expected removing a tenant to revoke its tokens")
+	}
+	if _, err := inst.Tenant("alice"); err != ErrTenantNotFound {
+		t.Errorf("expected removed tenant to be not found, got: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/startf"
+)
+
+// TransformScriptFilename is the name of the transform script in a working
+// directory
+const TransformScriptFilename = "transform.star"
+
+// TransformTestFilename is the name of the transform test script in a working
+// directory
+const TransformTestFilename = "transform_test.star"
+
+// TransformMethods encapsulates business logic for working with transforms
+type TransformMethods struct {
+	inst *Instance
+}
+
+// NewTransformMethods creates TransformMethods from a qri Instance
+func NewTransformMethods(inst *Instance) *TransformMethods {
+	return &TransformMethods{inst: inst}
+}
+
+// CoreRequestsName implements the requests interface
+func (m TransformMethods) CoreRequestsName() string { return "transform" }
+
+// TransformTestParams defines parameters for running transform tests
+type TransformTestParams struct {
+	// working directory containing transform.star & transform_test.star
+	Dir string
+	// reference to a linked dataset, used to find Dir when Dir is empty
+	Ref string
+	// optional writer to have transform script record standard output to.
+	// Over RPC, output is streamed from the daemon
+	ScriptOutput io.Writer
+}
+
+// Test runs the test_ functions defined in a working directory's
+// transform_test.star against its transform.star
+func (m *TransformMethods) Test(p *TransformTestParams, res *[]*startf.TestResult) (err error) {
+	return m.TestContext(context.TODO(), p, res)
+}
+
+// TestContext is Test with a context. Cancelling ctx stops running tests,
+// including tests running in a connected daemon
+func (m *TransformMethods) TestContext(ctx context.Context, p *TransformTestParams, res *[]*startf.TestResult) (err error) {
+	if p.Dir != "" {
+		if err = qfs.AbsPath(&p.Dir); err != nil {
+			return err
+		}
+	}
+
+	if m.inst.rpc != nil {
+		return checkRPCError(m.inst.rpc.CallContext(ctx, "TransformMethods.Test", p, res))
+	}
+
+	dir := p.Dir
+	if dir == "" {
+		if p.Ref == "" {
+			return fmt.Errorf("a working directory or dataset reference is required")
+		}
+		ref, err := repo.ParseDatasetRef(p.Ref)
+		if err != nil {
+			return err
+		}
+		if err = repo.CanonicalizeDatasetRef(m.inst.repo, &ref); err != nil && err != repo.ErrNoHistory {
+			return err
+		}
+		if ref.FSIPath == "" {
+			return fmt.Errorf("%s is not linked to a working directory", ref.AliasString())
+		}
+		dir = ref.FSIPath
+	}
+
+	script, err := openScript(filepath.Join(dir, TransformScriptFilename))
+	if err != nil {
+		return err
+	}
+	defer script.Close()
+
+	testScript, err := openScript(filepath.Join(dir, TransformTestFilename))
+	if err != nil {
+		return err
+	}
+	defer testScript.Close()
+
+	*res, err = startf.RunTests(ctx, script, testScript, startf.SetErrWriter(p.ScriptOutput))
+	return err
+}
+
+func openScript(path string) (qfs.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found in %s", filepath.Base(path), filepath.Dir(path))
+		}
+		return nil, err
+	}
+	return qfs.NewMemfileReader(filepath.Base(path), f), nil
+}
@@ -0,0 +1,48 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/qri/startf"
+)
+
+func TestTransformMethodsTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transform_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewTransformMethods(&Instance{})
+	res := []*startf.TestResult{}
+	if err := m.Test(&TransformTestParams{Dir: dir}, &res); err == nil {
+		t.Error("expected missing transform script to error")
+	}
+
+	script := `def transform(ds, ctx):
+  ds.set_body([ctx.get_config("n")])
+`
+	tests := `def test_config():
+  ds = run_transform(config={"n": 5})
+  assert.eq(ds.get_body(), [5])
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, TransformScriptFilename), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, TransformTestFilename), []byte(tests), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Test(&TransformTestParams{Dir: dir}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 test result, got: %d", len(res))
+	}
+	if !res[0].Passed {
+		t.Errorf("expected test to pass. error: %s", res[0].Error)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/identity"
 	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logging"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
@@ -33,6 +34,9 @@ func (c *httpClient) put(ctx context.Context, author identity.Author, r io.Reade
 		return err
 	}
 	req = req.WithContext(ctx)
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
 
 	if err := addAuthorHTTPHeaders(req.Header, author); err != nil {
 		return err
@@ -58,6 +62,9 @@ func (c *httpClient) get(ctx context.Context, author identity.Author, ref dsref.
 		return nil, nil, err
 	}
 	req = req.WithContext(ctx)
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
 
 	if err := addAuthorHTTPHeaders(req.Header, author); err != nil {
 		return nil, nil, err
@@ -87,6 +94,9 @@ func (c *httpClient) del(ctx context.Context, author identity.Author, ref dsref.
 		return err
 	}
 	req = req.WithContext(ctx)
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
 
 	if err := addAuthorHTTPHeaders(req.Header, author); err != nil {
 		return err
@@ -133,6 +143,7 @@ func senderFromHTTPHeaders(h http.Header) (identity.Author, error) {
 // that interlocks with methods exposed by httpClient
 func HTTPHandler(lsync *Logsync) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(logging.WithRequestID(r.Context(), r.Header.Get(logging.RequestIDHeader)))
 		sender, err := senderFromHTTPHeaders(r.Header)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
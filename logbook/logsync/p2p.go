@@ -16,6 +16,7 @@ import (
 	"github.com/qri-io/dag/dsync/p2putil"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/identity"
+	"github.com/qri-io/qri/logging"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
@@ -57,6 +58,7 @@ func (c *p2pClient) put(ctx context.Context, author identity.Author, r io.Reader
 	if err != nil {
 		return err
 	}
+	headers = addRequestIDP2PHeader(ctx, headers)
 	msg := p2putil.NewMessage(c.host.ID(), mtPut, data).WithHeaders(headers...)
 	_, err = c.sendMessage(ctx, msg, c.remotePeerID)
 	return err
@@ -71,6 +73,7 @@ func (c *p2pClient) get(ctx context.Context, author identity.Author, ref dsref.R
 	if err != nil {
 		return nil, nil, err
 	}
+	headers = addRequestIDP2PHeader(ctx, headers)
 
 	msg := p2putil.NewMessage(c.host.ID(), mtGet, nil).WithHeaders(headers...)
 
@@ -92,6 +95,7 @@ func (c *p2pClient) del(ctx context.Context, author identity.Author, ref dsref.R
 	if err != nil {
 		return err
 	}
+	headers = addRequestIDP2PHeader(ctx, headers)
 
 	msg := p2putil.NewMessage(c.host.ID(), mtDel, nil).WithHeaders(headers...)
 	_, err = c.sendMessage(ctx, msg, c.remotePeerID)
@@ -108,6 +112,14 @@ func addAuthorP2PHeaders(h []string, author identity.Author) ([]string, error) {
 	return append(h, "author_id", author.AuthorID(), "pub_key", pubKey), nil
 }
 
+// addRequestIDP2PHeader adds the request ID of ctx to message headers
+func addRequestIDP2PHeader(ctx context.Context, h []string) []string {
+	if id := logging.RequestID(ctx); id != "" {
+		return append(h, logging.RequestIDHeader, id)
+	}
+	return h
+}
+
 func authorFromP2PHeaders(msg p2putil.Message) (identity.Author, error) {
 	data, err := base64.StdEncoding.DecodeString(msg.Header("pub_key"))
 	if err != nil {
@@ -152,7 +164,7 @@ func (c *p2pHandler) LibP2PStreamHandler(s net.Stream) {
 // be sent with each block
 func (c *p2pHandler) HandlePut(ws *p2putil.WrappedStream, msg p2putil.Message) (hangup bool) {
 	if msg.Header("phase") == "request" {
-		ctx := context.Background()
+		ctx := logging.WithRequestID(context.Background(), msg.Header(logging.RequestIDHeader))
 		author, err := authorFromP2PHeaders(msg)
 		if err != nil {
 			return true
@@ -181,7 +193,7 @@ func (c *p2pHandler) HandlePut(ws *p2putil.WrappedStream, msg p2putil.Message) (
 // HandleGet places a block on the remote
 func (c *p2pHandler) HandleGet(ws *p2putil.WrappedStream, msg p2putil.Message) (hangup bool) {
 	if msg.Header("phase") == "request" {
-		ctx := context.Background()
+		ctx := logging.WithRequestID(context.Background(), msg.Header(logging.RequestIDHeader))
 		author, err := authorFromP2PHeaders(msg)
 		if err != nil {
 			return true
@@ -222,7 +234,7 @@ func (c *p2pHandler) HandleGet(ws *p2putil.WrappedStream, msg p2putil.Message) (
 // HandleDel asks the remote for a manifest specified by the root ID of a DAG
 func (c *p2pHandler) HandleDel(ws *p2putil.WrappedStream, msg p2putil.Message) (hangup bool) {
 	if msg.Header("phase") == "request" {
-		ctx := context.Background()
+		ctx := logging.WithRequestID(context.Background(), msg.Header(logging.RequestIDHeader))
 		author, err := authorFromP2PHeaders(msg)
 		if err != nil {
 			return true
@@ -0,0 +1,183 @@
+// Package logging correlates log lines across qri subsystems. A request ID
+// is created when a request enters qri (an API call, a command sent to the
+// daemon), carried in the context, and sent along with the calls it makes to
+// other qri nodes. Loggers from this package prefix lines with the request
+// ID of the context they're given. Logs can be written as plain text or as
+// JSON objects, one per line
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	gologging "github.com/whyrusleeping/go-logging"
+)
+
+// RequestIDHeader is the HTTP header & p2p message header that carries a
+// request ID
+const RequestIDHeader = "X-Request-Id"
+
+// Log output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type ctxKey string
+
+const requestIDKey = ctxKey("requestID")
+
+// validRequestID matches request IDs we accept from other nodes & clients
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// NewRequestID creates a random request ID
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ValidRequestID reports whether id is acceptable as a request ID
+func ValidRequestID(id string) bool {
+	return validRequestID.MatchString(id)
+}
+
+// WithRequestID adds a request ID to a context. Invalid IDs are ignored
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if !ValidRequestID(id) {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// Logger logs lines prefixed with the request ID of a context. Levels are
+// shared with the go-log logger of the same name
+type Logger struct {
+	l *gologging.Logger
+}
+
+// NewLogger creates a logger. name should match the package's go-log logger
+func NewLogger(name string) *Logger {
+	golog.Logger(name)
+	l := gologging.MustGetLogger(name)
+	// skip Logger methods when reporting the calling file
+	l.ExtraCalldepth = 1
+	return &Logger{l: l}
+}
+
+// Debugf logs at debug level
+func (l *Logger) Debugf(ctx context.Context, format string, args ...interface{}) {
+	l.l.Debugf(prefix(ctx)+format, args...)
+}
+
+// Infof logs at info level
+func (l *Logger) Infof(ctx context.Context, format string, args ...interface{}) {
+	l.l.Infof(prefix(ctx)+format, args...)
+}
+
+// Warningf logs at warning level
+func (l *Logger) Warningf(ctx context.Context, format string, args ...interface{}) {
+	l.l.Warningf(prefix(ctx)+format, args...)
+}
+
+// Errorf logs at error level
+func (l *Logger) Errorf(ctx context.Context, format string, args ...interface{}) {
+	l.l.Errorf(prefix(ctx)+format, args...)
+}
+
+// prefix formats a request ID for the start of a log line. Valid IDs have no
+// formatting verbs
+func prefix(ctx context.Context) string {
+	if id := RequestID(ctx); id != "" {
+		return "[" + id + "] "
+	}
+	return ""
+}
+
+// linePrefix matches the request ID prefix of a log line
+var linePrefix = regexp.MustCompile(`^\[([A-Za-z0-9._-]{1,64})\] `)
+
+// SetFormat switches the output format of all loggers, writing to w. Log
+// levels are kept
+func SetFormat(format string, w io.Writer) error {
+	var backend gologging.Backend
+	switch format {
+	case "", FormatText:
+		backend = gologging.NewLogBackend(w, "", 0)
+	case FormatJSON:
+		backend = &jsonBackend{w: w}
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	// replacing the backend resets levels, so copy them over
+	subsystems := golog.GetSubsystems()
+	levels := make(map[string]gologging.Level, len(subsystems))
+	for _, name := range subsystems {
+		levels[name] = gologging.GetLevel(name)
+	}
+	defaultLevel := gologging.GetLevel("")
+
+	gologging.SetBackend(backend)
+	gologging.SetLevel(defaultLevel, "")
+	for name, lvl := range levels {
+		gologging.SetLevel(lvl, name)
+	}
+	return nil
+}
+
+// Entry is a log line written in JSON format
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`
+	Logger    string    `json:"logger"`
+	RequestID string    `json:"requestID,omitempty"`
+	Message   string    `json:"msg"`
+}
+
+// jsonBackend writes log records as JSON objects, one per line
+type jsonBackend struct {
+	lk sync.Mutex
+	w  io.Writer
+}
+
+func (b *jsonBackend) Log(level gologging.Level, calldepth int, rec *gologging.Record) error {
+	e := Entry{
+		Time:    rec.Time,
+		Level:   level.String(),
+		Logger:  rec.Module,
+		Message: rec.Message(),
+	}
+	if m := linePrefix.FindStringSubmatch(e.Message); m != nil {
+		e.RequestID = m[1]
+		e.Message = e.Message[len(m[0]):]
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	_, err = b.w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	golog "github.com/ipfs/go-log"
+)
+
+func TestRequestID(t *testing.T) {
+	ctx := context.Background()
+	if id := RequestID(ctx); id != "" {
+		t.Errorf("expected empty context to have no request ID, got %q", id)
+	}
+
+	id := NewRequestID()
+	if !ValidRequestID(id) {
+		t.Fatalf("expected new request ID %q to be valid", id)
+	}
+	if got := RequestID(WithRequestID(ctx, id)); got != id {
+		t.Errorf("expected request ID %q, got %q", id, got)
+	}
+
+	for _, bad := range []string{"", "has space", "%s", strings.Repeat("a", 65)} {
+		if got := RequestID(WithRequestID(ctx, bad)); got != "" {
+			t.Errorf("expected invalid request ID %q to be ignored, got %q", bad, got)
+		}
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := SetFormat(FormatJSON, buf); err != nil {
+		t.Fatal(err)
+	}
+	defer SetFormat(FormatText, &bytes.Buffer{})
+
+	l := NewLogger("logging_test")
+	golog.SetLogLevel("logging_test", "info")
+
+	ctx := WithRequestID(context.Background(), "abc123")
+	l.Infof(ctx, "saved %d datasets", 2)
+	l.Debugf(ctx, "not logged at info level")
+	l.Errorf(context.Background(), "no request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	e := Entry{}
+	if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.RequestID != "abc123" || e.Message != "saved 2 datasets" || e.Logger != "logging_test" || e.Level != "INFO" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	e = Entry{}
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.RequestID != "" || e.Message != "no request" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	if err := SetFormat("xml", buf); err == nil {
+		t.Error("expected an unknown format to error")
+	}
+}
@@ -0,0 +1,129 @@
+// Package metrics collects measurements of a running qri node, served in
+// Prometheus text format. Packages record to the collectors declared here,
+// measurements that depend on node state are gathered when metrics are
+// requested
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes all qri metric names
+const Namespace = "qri"
+
+// Registry holds the process-wide qri collectors, along with go runtime &
+// process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// APIRequests counts JSON API requests by route, method & status code
+	APIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "JSON API requests by route, method & status code",
+	}, []string{"route", "method", "code"})
+
+	// APIRequestDuration measures the time JSON API requests take by route &
+	// method
+	APIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to respond to JSON API requests by route & method",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// RemoteSyncs counts completed pushes & pulls to this node acting as a
+	// remote, by protocol (dsync or logsync) & direction (push or pull)
+	RemoteSyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "remote",
+		Name:      "syncs_total",
+		Help:      "Pushes & pulls by protocol & direction",
+	}, []string{"protocol", "direction"})
+
+	// RemoteSyncBytes counts bytes transferred by remote pushes & pulls, by
+	// protocol & direction
+	RemoteSyncBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "remote",
+		Name:      "sync_bytes_total",
+		Help:      "Bytes pushed & pulled by protocol & direction",
+	}, []string{"protocol", "direction"})
+
+	// StatsCacheLookups counts stats cache lookups by result (hit or miss)
+	StatsCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "stats",
+		Name:      "cache_lookups_total",
+		Help:      "Stats cache lookups by result",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		APIRequests,
+		APIRequestDuration,
+		RemoteSyncs,
+		RemoteSyncBytes,
+		StatsCacheLookups,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves metrics from Registry and any additional gatherers
+func Handler(gatherers ...prometheus.Gatherer) http.Handler {
+	g := append(prometheus.Gatherers{Registry}, gatherers...)
+	return promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a finished JSON API request
+func ObserveRequest(route, method string, code int, took time.Duration) {
+	APIRequests.WithLabelValues(route, method, statusCode(code)).Inc()
+	APIRequestDuration.WithLabelValues(route, method).Observe(took.Seconds())
+}
+
+// ObserveSync records a push or pull completed by a remote
+func ObserveSync(protocol, direction string) {
+	RemoteSyncs.WithLabelValues(protocol, direction).Inc()
+}
+
+// ObserveSyncBytes records bytes transferred by a remote
+func ObserveSyncBytes(protocol, direction string, n int64) {
+	if n > 0 {
+		RemoteSyncBytes.WithLabelValues(protocol, direction).Add(float64(n))
+	}
+}
+
+// ObserveStatsCache records a stats cache lookup
+func ObserveStatsCache(hit bool) {
+	if hit {
+		StatsCacheLookups.WithLabelValues("hit").Inc()
+		return
+	}
+	StatsCacheLookups.WithLabelValues("miss").Inc()
+}
+
+// GaugeFunc creates a gauge that calls fn for its value when gathered
+func GaugeFunc(subsystem, name, help string, fn func() float64) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, fn)
+}
+
+func statusCode(code int) string {
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveRequest("/list", "GET", 0, 20*time.Millisecond)
+	ObserveSync("dsync", "push")
+	ObserveSyncBytes("dsync", "push", 512)
+	ObserveSyncBytes("dsync", "pull", 0)
+	ObserveStatsCache(true)
+	ObserveStatsCache(false)
+
+	extra := prometheus.NewRegistry()
+	extra.MustRegister(GaugeFunc("repo", "datasets", "Datasets in the repo", func() float64 { return 3 }))
+
+	w := httptest.NewRecorder()
+	Handler(extra).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != 200 {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+
+	expect := []string{
+		`qri_api_requests_total{code="200",method="GET",route="/list"} 1`,
+		`qri_api_request_duration_seconds_count{method="GET",route="/list"} 1`,
+		`qri_remote_syncs_total{direction="push",protocol="dsync"} 1`,
+		`qri_remote_sync_bytes_total{direction="push",protocol="dsync"} 512`,
+		`qri_stats_cache_lookups_total{result="hit"} 1`,
+		`qri_stats_cache_lookups_total{result="miss"} 1`,
+		`qri_repo_datasets 3`,
+		`go_goroutines`,
+	}
+	for _, e := range expect {
+		if !strings.Contains(body, e) {
+			t.Errorf("expected metrics to include %q", e)
+		}
+	}
+	if strings.Contains(body, `direction="pull",protocol="dsync"`) {
+		t.Errorf("expected empty transfers not to be recorded")
+	}
+}
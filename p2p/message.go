@@ -1,11 +1,13 @@
 package p2p
 
 import (
+	"context"
 	"encoding/json"
 	"math/rand"
 	"time"
 
 	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/qri-io/qri/logging"
 )
 
 // MsgType indicates the type of message being sent
@@ -84,6 +86,27 @@ func (m Message) WithHeaders(keyval ...string) Message {
 	}
 }
 
+// withRequestID adds the request ID of ctx to a copy of a message's headers
+func (m Message) withRequestID(ctx context.Context) Message {
+	id := logging.RequestID(ctx)
+	if id == "" {
+		return m
+	}
+	headers := make(map[string]string, len(m.Headers)+1)
+	for key, val := range m.Headers {
+		headers[key] = val
+	}
+	headers[logging.RequestIDHeader] = id
+	m.Headers = headers
+	return m
+}
+
+// Context returns a context carrying the request ID the message was sent
+// with
+func (m Message) Context(ctx context.Context) context.Context {
+	return logging.WithRequestID(ctx, m.Header(logging.RequestIDHeader))
+}
+
 // Header gets a header value for a given key
 func (m Message) Header(key string) (value string) {
 	if m.Headers == nil {
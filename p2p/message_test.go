@@ -2,8 +2,11 @@ package p2p
 
 import (
 	"bytes"
+	"context"
 	"testing"
 	"time"
+
+	"github.com/qri-io/qri/logging"
 )
 
 func TestMessageUpdate(t *testing.T) {
@@ -20,3 +23,22 @@ func TestMessageUpdate(t *testing.T) {
 		t.Errorf("payload mismatch. expected %s, got: %s", "bar", string(b.Body))
 	}
 }
+
+func TestMessageRequestID(t *testing.T) {
+	a := Message{ID: "a", Headers: map[string]string{"phase": "request"}}
+	if b := a.withRequestID(context.Background()); b.Header(logging.RequestIDHeader) != "" {
+		t.Errorf("expected no request ID header without a request ID")
+	}
+
+	ctx := logging.WithRequestID(context.Background(), "abc123")
+	b := a.withRequestID(ctx)
+	if b.Header(logging.RequestIDHeader) != "abc123" || b.Header("phase") != "request" {
+		t.Errorf("expected request ID to be added to headers, got %v", b.Headers)
+	}
+	if a.Header(logging.RequestIDHeader) != "" {
+		t.Errorf("expected original message headers to be unchanged")
+	}
+	if id := logging.RequestID(b.Context(context.Background())); id != "abc123" {
+		t.Errorf("expected message context to carry request ID abc123, got %q", id)
+	}
+}
@@ -356,6 +356,7 @@ func makeBasicHost(ctx context.Context, ps pstore.Peerstore, p2pconf *config.P2P
 
 // SendMessage opens a stream & sends a message from p to one ore more peerIDs
 func (n *QriNode) SendMessage(ctx context.Context, msg Message, replies chan Message, pids ...peer.ID) error {
+	msg = msg.withRequestID(ctx)
 	for _, peerID := range pids {
 		if peerID == n.ID {
 			// can't send messages to yourself, silly
@@ -412,11 +413,13 @@ func (n *QriNode) handleStream(ws *WrappedStream, replies chan Message) {
 			n.msgChan <- msg
 		}()
 
+		ctx := msg.Context(context.Background())
 		handler, ok := n.handlers[msg.Type]
 		if !ok {
-			log.Infof("peer %s sent unrecognized message type '%s', hanging up", n.ID, msg.Type)
+			reqLog.Infof(ctx, "peer %s sent unrecognized message type '%s', hanging up", n.ID, msg.Type)
 			break
 		}
+		reqLog.Debugf(ctx, "received %s message %s from %s", msg.Type, msg.ID, msg.Initiator)
 
 		if hangup := handler(ws, msg); hangup {
 			break
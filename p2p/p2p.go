@@ -8,10 +8,15 @@ import (
 
 	protocol "github.com/libp2p/go-libp2p-core/protocol"
 	identify "github.com/libp2p/go-libp2p/p2p/protocol/identify"
+	"github.com/qri-io/qri/logging"
 	"github.com/qri-io/qri/version"
 )
 
-var log = golog.Logger("qrip2p")
+var (
+	log = golog.Logger("qrip2p")
+	// reqLog logs lines tagged with the request ID of a context
+	reqLog = logging.NewLogger("qrip2p")
+)
 
 const (
 	// QriProtocolID is the top level Protocol Identifier
@@ -4,8 +4,12 @@ import (
 	"context"
 
 	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/logging"
 )
 
+// requestIDParam is the dsync meta & remove param that carries a request ID
+const requestIDParam = "requestID"
+
 // The ctxKey type is unexported to prevent collisions with context keys defined
 // in other packages.
 type ctxKey int
@@ -24,3 +28,16 @@ func OplogFromContext(ctx context.Context) (l *oplog.Log, ok bool) {
 	l, ok = ctx.Value(oplogKey).(*oplog.Log)
 	return l, ok
 }
+
+// addRequestID adds the request ID of ctx to dsync meta params
+func addRequestID(ctx context.Context, params map[string]string) map[string]string {
+	if id := logging.RequestID(ctx); id != "" {
+		params[requestIDParam] = id
+	}
+	return params
+}
+
+// metaContext adds the request ID sent in dsync meta params to ctx
+func metaContext(ctx context.Context, meta map[string]string) context.Context {
+	return logging.WithRequestID(ctx, meta[requestIDParam])
+}
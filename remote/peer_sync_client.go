@@ -21,6 +21,7 @@ import (
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/logbook/logsync"
 	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/logging"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
@@ -164,7 +165,7 @@ func (c *PeerSyncClient) PushDataset(ctx context.Context, ref reporef.DatasetRef
 	if t := addressType(remoteAddr); t == "http" {
 		remoteAddr = remoteAddr + "/remote/dsync"
 	}
-	log.Debugf("pushing dataset %s to %s", ref.Path, remoteAddr)
+	reqLog.Debugf(ctx, "pushing dataset %s to %s", ref.Path, remoteAddr)
 	push, err := c.ds.NewPush(ref.Path, remoteAddr, true)
 	if err != nil {
 		return err
@@ -174,7 +175,7 @@ func (c *PeerSyncClient) PushDataset(ctx context.Context, ref reporef.DatasetRef
 	if err != nil {
 		return err
 	}
-	push.SetMeta(params)
+	push.SetMeta(addRequestID(ctx, params))
 
 	go func() {
 		updates := push.Updates()
@@ -200,7 +201,7 @@ func (c *PeerSyncClient) PullDataset(ctx context.Context, ref *reporef.DatasetRe
 	if c == nil {
 		return ErrNoRemoteClient
 	}
-	log.Debugf("pulling dataset: %s from %s", ref.String(), remoteAddr)
+	reqLog.Debugf(ctx, "pulling dataset: %s from %s", ref.String(), remoteAddr)
 
 	if ref.Path == "" {
 		if err := c.ResolveHeadRef(ctx, ref, remoteAddr); err != nil {
@@ -215,7 +216,7 @@ func (c *PeerSyncClient) PullDataset(ctx context.Context, ref *reporef.DatasetRe
 		return err
 	}
 
-	pull, err := c.ds.NewPull(ref.Path, remoteAddr+"/remote/dsync", params)
+	pull, err := c.ds.NewPull(ref.Path, remoteAddr+"/remote/dsync", addRequestID(ctx, params))
 	if err != nil {
 		log.Error("creating pull: ", err)
 		return err
@@ -230,11 +231,12 @@ func (c *PeerSyncClient) RemoveDataset(ctx context.Context, ref reporef.DatasetR
 		return ErrNoRemoteClient
 	}
 
-	log.Debugf("requesting remove dataset %s from remote %s", ref.Path, remoteAddr)
+	reqLog.Debugf(ctx, "requesting remove dataset %s from remote %s", ref.Path, remoteAddr)
 	params, err := sigParams(c.pk, ref)
 	if err != nil {
 		return err
 	}
+	addRequestID(ctx, params)
 
 	switch addressType(remoteAddr) {
 	case "http":
@@ -279,6 +281,9 @@ func resolveHeadRefHTTP(ctx context.Context, ref *reporef.DatasetRef, remoteAddr
 	}
 
 	req = req.WithContext(ctx)
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
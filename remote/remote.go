@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -19,13 +20,19 @@ import (
 	"github.com/qri-io/qri/identity"
 	"github.com/qri-io/qri/logbook/logsync"
 	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/logging"
+	"github.com/qri-io/qri/metrics"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
 
-var log = golog.Logger("remote")
+var (
+	log = golog.Logger("remote")
+	// reqLog logs lines tagged with the request ID of a context
+	reqLog = logging.NewLogger("remote")
+)
 
 // Hook is a function called at specific points in the sync cycle
 // hook contexts may be populated with request parameters
@@ -176,9 +183,9 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, opts ...func(o *Options))
 		r.logsync = logsync.New(book, func(lso *logsync.Options) {
 			lso.PushPreCheck = r.logHook(o.LogPushPreCheck)
 			lso.PushFinalCheck = r.logHook(o.LogPushFinalCheck)
-			lso.Pushed = r.logHook(o.LogPushed)
+			lso.Pushed = observeLogSync("push", r.logHook(o.LogPushed))
 			lso.PullPreCheck = r.logHook(o.LogPullPreCheck)
-			lso.Pulled = r.logHook(o.LogPulled)
+			lso.Pulled = observeLogSync("pull", r.logHook(o.LogPulled))
 			lso.RemovePreCheck = r.logHook(o.LogRemovePreCheck)
 			lso.Removed = r.logHook(o.LogRemoved)
 		})
@@ -230,11 +237,12 @@ func (r *Remote) ResolveHeadRef(ctx context.Context, peername, name string) (*re
 // the dataset ref from the refstore and add the (n + 1)th to the refstore
 // gen = -1 should indicate that we remove all the dataset versions
 func (r *Remote) RemoveDataset(ctx context.Context, params map[string]string) error {
+	ctx = metaContext(ctx, params)
 	pid, ref, err := r.pidAndRefFromMeta(params)
 	if err != nil {
 		return err
 	}
-	log.Debugf("remove dataset %s", ref)
+	reqLog.Debugf(ctx, "remove dataset %s", ref)
 
 	// run pre check hook
 	if r.datasetRemovePreCheck != nil {
@@ -273,6 +281,7 @@ func (r *Remote) RemoveDataset(ctx context.Context, params map[string]string) er
 }
 
 func (r *Remote) dsPushPreCheck(ctx context.Context, info dag.Info, meta map[string]string) error {
+	ctx = metaContext(ctx, meta)
 	if r.acceptSizeMax == 0 {
 		return fmt.Errorf("not accepting any datasets")
 	}
@@ -305,6 +314,7 @@ func (r *Remote) dsPushPreCheck(ctx context.Context, info dag.Info, meta map[str
 }
 
 func (r *Remote) dsPushFinalCheck(ctx context.Context, info dag.Info, meta map[string]string) error {
+	ctx = metaContext(ctx, meta)
 	if r.datasetPushFinalCheck != nil {
 		pid, ref, err := r.pidAndRefFromMeta(meta)
 		if err != nil {
@@ -319,10 +329,12 @@ func (r *Remote) dsPushFinalCheck(ctx context.Context, info dag.Info, meta map[s
 }
 
 func (r *Remote) dsPushComplete(ctx context.Context, info dag.Info, meta map[string]string) error {
+	ctx = metaContext(ctx, meta)
 	pid, ref, err := r.pidAndRefFromMeta(meta)
 	if err != nil {
 		return err
 	}
+	reqLog.Debugf(ctx, "dataset pushed %s", ref)
 
 	if err := repo.CanonicalizeDatasetRef(r.node.Repo, &ref); err != nil {
 		if err == repo.ErrNotFound {
@@ -338,6 +350,8 @@ func (r *Remote) dsPushComplete(ctx context.Context, info dag.Info, meta map[str
 		}
 	}
 
+	metrics.ObserveSync("dsync", "push")
+
 	// mark ref as published b/c someone just published to us
 	ref.Published = true
 
@@ -348,6 +362,7 @@ func (r *Remote) dsPushComplete(ctx context.Context, info dag.Info, meta map[str
 }
 
 func (r *Remote) dsRemovePreCheck(ctx context.Context, info dag.Info, meta map[string]string) error {
+	ctx = metaContext(ctx, meta)
 	pid, ref, err := r.pidAndRefFromMeta(meta)
 	if err != nil {
 		return err
@@ -362,18 +377,20 @@ func (r *Remote) dsRemovePreCheck(ctx context.Context, info dag.Info, meta map[s
 }
 
 func (r *Remote) dsGetDagInfo(ctx context.Context, into dag.Info, meta map[string]string) error {
+	ctx = metaContext(ctx, meta)
 	pid, ref, err := r.pidAndRefFromMeta(meta)
 	if err != nil {
-		log.Errorf("ref from meta: %s", err.Error())
+		reqLog.Errorf(ctx, "ref from meta: %s", err.Error())
 		return err
 	}
 
 	if r.datasetPulled != nil {
 		if err = r.datasetPulled(ctx, pid, ref); err != nil {
-			log.Errorf("dataset pulled hook: %s", err.Error())
+			reqLog.Errorf(ctx, "dataset pulled hook: %s", err.Error())
 			return err
 		}
 	}
+	metrics.ObserveSync("dsync", "pull")
 	return nil
 }
 
@@ -425,6 +442,19 @@ func (r *Remote) logHook(h Hook) logsync.Hook {
 	}
 }
 
+// observeLogSync records logsync pushes & pulls that hook h allows
+func observeLogSync(direction string, h logsync.Hook) logsync.Hook {
+	return func(ctx context.Context, author identity.Author, ref dsref.Ref, l *oplog.Log) error {
+		if err := h(ctx, author, ref, l); err != nil {
+			reqLog.Debugf(ctx, "logsync %s %s: %s", direction, ref, err)
+			return err
+		}
+		reqLog.Debugf(ctx, "logsync %s %s", direction, ref)
+		metrics.ObserveSync("logsync", direction)
+		return nil
+	}
+}
+
 // AddDefaultRoutes attaches routes a remote client will expect to an HTTP muxer
 func (r *Remote) AddDefaultRoutes(mux *http.ServeMux) {
 	mux.Handle("/remote/dsync", r.DsyncHTTPHandler())
@@ -443,12 +473,12 @@ func (r *Remote) AddDefaultRoutes(mux *http.ServeMux) {
 
 // DsyncHTTPHandler provides an http handler for dsync
 func (r *Remote) DsyncHTTPHandler() http.HandlerFunc {
-	return dsync.HTTPRemoteHandler(r.dsync)
+	return observeSyncBytes("dsync", dsync.HTTPRemoteHandler(r.dsync))
 }
 
 // LogsyncHTTPHandler provides an http handler for synchronizing logs
 func (r *Remote) LogsyncHTTPHandler() http.HandlerFunc {
-	return logsync.HTTPHandler(r.logsync)
+	return observeSyncBytes("logsync", logsync.HTTPHandler(r.logsync))
 }
 
 // FeedsHTTPHandler provides access to the home feed
@@ -542,6 +572,7 @@ func (r *Remote) ComponentHTTPHandler(prefix string) http.HandlerFunc {
 // RefsHTTPHandler handles requests for dataset references
 func (r *Remote) RefsHTTPHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
+		req = req.WithContext(logging.WithRequestID(req.Context(), req.Header.Get(logging.RequestIDHeader)))
 		switch req.Method {
 		case "GET":
 			ref := &reporef.DatasetRef{
@@ -583,3 +614,44 @@ func (r *Remote) RefsHTTPHandler() http.HandlerFunc {
 		}
 	}
 }
+
+// observeSyncBytes records the bytes clients push in request bodies, and
+// pull in the bodies of GET responses
+func observeSyncBytes(protocol string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			cw := &countingResponseWriter{ResponseWriter: w}
+			handler(cw, r)
+			metrics.ObserveSyncBytes(protocol, "pull", cw.n)
+			return
+		}
+		if r.Body != nil {
+			cr := &countingReader{ReadCloser: r.Body}
+			r.Body = cr
+			defer func() { metrics.ObserveSyncBytes(protocol, "push", cr.n) }()
+		}
+		handler(w, r)
+	}
+}
+
+type countingResponseWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -4,6 +4,7 @@ package fsrepo
 import (
 	"fmt"
 	"os"
+	"sync"
 
 	golog "github.com/ipfs/go-log"
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
@@ -60,7 +61,7 @@ func NewRepo(store cafs.Filestore, fsys qfs.Filesystem, book *logbook.Book, cach
 		logbook:  book,
 		dscache:  cache,
 
-		Refstore: Refstore{basepath: bp, store: store, file: FileRefs},
+		Refstore: Refstore{basepath: bp, store: store, file: FileRefs, lk: &sync.RWMutex{}},
 
 		profiles: NewProfileStore(bp),
 	}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"sort"
+	"sync"
 
 	"github.com/qri-io/qfs/cafs"
 	"github.com/qri-io/qri/repo"
@@ -19,6 +20,8 @@ type Refstore struct {
 	file File
 	// filestore for checking dataset integrity
 	store cafs.Filestore
+	// lk guards the refs file. Writes read, modify & replace the whole file
+	lk *sync.RWMutex
 }
 
 // PutRef adds a reference to the store
@@ -38,6 +41,8 @@ func (rs Refstore) PutRef(r reporef.DatasetRef) (err error) {
 		return repo.ErrPeernameRequired
 	}
 
+	rs.lk.Lock()
+	defer rs.lk.Unlock()
 	if refs, err = rs.refs(); err != nil {
 		return err
 	}
@@ -59,6 +64,8 @@ func (rs Refstore) PutRef(r reporef.DatasetRef) (err error) {
 
 // GetRef completes a partially-known reference
 func (rs Refstore) GetRef(get reporef.DatasetRef) (reporef.DatasetRef, error) {
+	rs.lk.RLock()
+	defer rs.lk.RUnlock()
 	refs, err := rs.refs()
 	if err != nil {
 		return reporef.DatasetRef{}, err
@@ -73,6 +80,8 @@ func (rs Refstore) GetRef(get reporef.DatasetRef) (reporef.DatasetRef, error) {
 
 // DeleteRef removes a name from the store
 func (rs Refstore) DeleteRef(del reporef.DatasetRef) error {
+	rs.lk.Lock()
+	defer rs.lk.Unlock()
 	refs, err := rs.refs()
 	if err != nil {
 		return err
@@ -90,6 +99,8 @@ func (rs Refstore) DeleteRef(del reporef.DatasetRef) error {
 
 // References gives a set of dataset references from the store
 func (rs Refstore) References(offset, limit int) ([]reporef.DatasetRef, error) {
+	rs.lk.RLock()
+	defer rs.lk.RUnlock()
 	refs, err := rs.refs()
 	if err != nil {
 		return nil, err
@@ -111,6 +122,8 @@ func (rs Refstore) References(offset, limit int) ([]reporef.DatasetRef, error) {
 func (rs Refstore) RefCount() (int, error) {
 	// TODO (b5) - there's no need to unmarshal here
 	// could just read the length of the flatbuffer ref vector
+	rs.lk.RLock()
+	defer rs.lk.RUnlock()
 	refs, err := rs.refs()
 	if err != nil {
 		log.Debug(err.Error())
@@ -0,0 +1,51 @@
+package fsrepo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/qri-io/qri/repo/profile"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestRefstoreConcurrentWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_refstore_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rs := Refstore{basepath: basepath(dir), file: FileRefs, lk: &sync.RWMutex{}}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ref := reporef.DatasetRef{
+				Peername:  "peer",
+				ProfileID: profile.IDB58MustDecode("QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt"),
+				Name:      fmt.Sprintf("ds_%d", i),
+				Path:      fmt.Sprintf("/map/QmData%d", i),
+			}
+			if err := rs.PutRef(ref); err != nil {
+				t.Error(err)
+			}
+			if _, err := rs.References(0, 100); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	count, err := rs.RefCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 20 {
+		t.Errorf("expected every ref to survive concurrent writes, got %d of 20", count)
+	}
+}
@@ -78,7 +78,7 @@ func (dr *DatasetResolver) LoadDsref(ctx context.Context, refstr string) (*datas
 	var ds *dataset.Dataset
 	if defaultPath && info.FSIPath != "" {
 		// Has an FSI Path, load from working directory
-		if ds, err = fsi.ReadDir(info.FSIPath); err != nil {
+		if ds, err = fsi.ReadWorkingDataset(ctx, info.FSIPath, dr.Store, info.Path); err != nil {
 			return nil, "", ref, nil, err
 		}
 	} else {
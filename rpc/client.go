@@ -0,0 +1,259 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/qri-io/qri/logging"
+)
+
+// Client makes calls to a Server. It's safe for concurrent use
+type Client struct {
+	// OnEvent, if set, is called with events the daemon publishes while a
+	// call runs. It must be set before making calls
+	OnEvent func(e Event)
+
+	ctx  context.Context
+	conn io.ReadWriteCloser
+
+	sendLk sync.Mutex
+	enc    *json.Encoder
+
+	lk       sync.Mutex
+	nextID   uint64
+	calls    map[uint64]*call
+	shutdown error
+}
+
+// call is a call waiting for a response
+type call struct {
+	streams map[string]io.Writer
+	done    chan response
+}
+
+// Dial connects to the daemon socket at path, authenticating with the token
+// stored next to it. Calls made without a context use ctx
+func Dial(ctx context.Context, path string) (*Client, error) {
+	token, err := readToken(filepath.Join(filepath.Dir(path), TokenFilename))
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, conn, token)
+}
+
+// NewClient authenticates with a server over conn
+func NewClient(ctx context.Context, conn io.ReadWriteCloser, token string) (*Client, error) {
+	c := &Client{
+		ctx:   ctx,
+		conn:  conn,
+		enc:   json.NewEncoder(conn),
+		calls: map[uint64]*call{},
+	}
+	dec := json.NewDecoder(bufio.NewReader(conn))
+
+	if err := c.enc.Encode(hello{Token: token}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	w := welcome{}
+	if err := dec.Decode(&w); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if w.Error != "" {
+		conn.Close()
+		if w.Error == ErrUnauthorized.Error() {
+			return nil, ErrUnauthorized
+		}
+		return nil, errors.New(w.Error)
+	}
+
+	go c.read(dec)
+	return c, nil
+}
+
+// Call invokes the named method, waiting for it to complete. It matches the
+// signature of net/rpc's Client.Call
+func (c *Client) Call(method string, args, reply interface{}) error {
+	return c.CallContext(c.ctx, method, args, reply)
+}
+
+// CallContext invokes the named method, waiting for it to complete.
+// Cancelling ctx cancels the call in the daemon. io.Writer fields of args
+// receive output the method writes to them while it runs
+func (c *Client) CallContext(ctx context.Context, method string, args, reply interface{}) error {
+	params, streams, err := encodeParams(args)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(streams))
+	for name := range streams {
+		names = append(names, name)
+	}
+
+	cl := &call{streams: streams, done: make(chan response, 1)}
+	c.lk.Lock()
+	if c.shutdown != nil {
+		c.lk.Unlock()
+		return c.shutdown
+	}
+	c.nextID++
+	id := c.nextID
+	c.calls[id] = cl
+	c.lk.Unlock()
+
+	reqID := logging.RequestID(ctx)
+	if reqID == "" {
+		reqID = logging.NewRequestID()
+	}
+
+	if err := c.send(request{ID: id, Method: method, Params: params, Streams: names, RequestID: reqID}); err != nil {
+		c.remove(id)
+		return err
+	}
+
+	select {
+	case res := <-cl.done:
+		return decodeResult(res, reply)
+	case <-ctx.Done():
+		c.send(request{ID: id, Cancel: true})
+		// wait for the daemon to finish so no more output arrives
+		res := <-cl.done
+		if res.Error == "" {
+			return decodeResult(res, reply)
+		}
+		return ctx.Err()
+	}
+}
+
+// Close shuts down the connection. Calls still running in the daemon are
+// cancelled
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) send(req request) error {
+	c.sendLk.Lock()
+	defer c.sendLk.Unlock()
+	return c.enc.Encode(req)
+}
+
+func (c *Client) remove(id uint64) *call {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	cl := c.calls[id]
+	delete(c.calls, id)
+	return cl
+}
+
+// read delivers responses to calls until the connection fails
+func (c *Client) read(dec *json.Decoder) {
+	var err error
+	for {
+		res := response{}
+		if err = dec.Decode(&res); err != nil {
+			break
+		}
+
+		if res.Done {
+			if cl := c.remove(res.ID); cl != nil {
+				cl.done <- res
+			}
+			continue
+		}
+
+		c.lk.Lock()
+		cl := c.calls[res.ID]
+		c.lk.Unlock()
+		if cl == nil {
+			continue
+		}
+		switch {
+		case res.Stream != "":
+			if w := cl.streams[res.Stream]; w != nil {
+				w.Write(res.Data)
+			}
+		case res.Event != nil:
+			if c.OnEvent != nil {
+				c.OnEvent(*res.Event)
+			}
+		}
+	}
+
+	if err == io.EOF {
+		err = ErrShutdown
+	}
+	c.lk.Lock()
+	c.shutdown = ErrShutdown
+	calls := c.calls
+	c.calls = map[uint64]*call{}
+	c.lk.Unlock()
+	for _, cl := range calls {
+		cl.done <- response{Done: true, Error: err.Error()}
+	}
+}
+
+// encodeParams encodes args as JSON, leaving out io.Writer fields, which
+// are returned by name
+func encodeParams(args interface{}) (json.RawMessage, map[string]io.Writer, error) {
+	if args == nil {
+		return nil, nil, nil
+	}
+	v := reflect.ValueOf(args)
+	st := reflect.Indirect(v)
+	if st.Kind() != reflect.Struct || !hasWriterFields(st.Type()) {
+		data, err := json.Marshal(args)
+		return data, nil, err
+	}
+
+	// copy the struct so the caller's params are left untouched
+	cpy := reflect.New(st.Type()).Elem()
+	cpy.Set(st)
+	streams := map[string]io.Writer{}
+	for i := 0; i < cpy.NumField(); i++ {
+		f := cpy.Field(i)
+		sf := st.Type().Field(i)
+		if sf.PkgPath != "" || sf.Type != typeOfWriter || f.IsNil() {
+			continue
+		}
+		streams[sf.Name] = f.Interface().(io.Writer)
+		f.Set(reflect.Zero(sf.Type))
+	}
+	data, err := json.Marshal(cpy.Addr().Interface())
+	if err != nil {
+		return nil, nil, fmt.Errorf("rpc: encoding params: %s", err)
+	}
+	return data, streams, nil
+}
+
+func hasWriterFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == typeOfWriter {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeResult(res response, reply interface{}) error {
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+	if reply == nil || len(res.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(res.Result, reply)
+}
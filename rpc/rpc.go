@@ -0,0 +1,126 @@
+// Package rpc connects qri commands to a running qri daemon. Calls are sent
+// as newline-delimited JSON over a unix domain socket in the repo directory,
+// which only the repo owner can access. Clients authenticate with a token
+// the daemon writes next to the socket each time it starts.
+//
+// Unlike net/rpc, a call can stream output & events back to the caller
+// while it runs, and cancelling a call's context (or dropping the
+// connection) cancels the context of the method running in the daemon.
+// Params fields that are io.Writers are streamed: the daemon writes to a
+// stand-in writer, and the client copies what it receives to the original
+package rpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logging"
+)
+
+var (
+	log    = golog.Logger("rpc")
+	reqLog = logging.NewLogger("rpc")
+)
+
+const (
+	// DefaultSocket is the filename of the socket within a repo directory
+	DefaultSocket = config.DefaultRPCSocket
+	// TokenFilename is the name of the file holding the token clients present
+	// to authenticate, stored next to the socket
+	TokenFilename = "rpc.token"
+)
+
+// ErrUnauthorized is returned when a client presents an invalid token
+var ErrUnauthorized = fmt.Errorf("rpc: unauthorized")
+
+// ErrShutdown is returned by calls on a closed client
+var ErrShutdown = fmt.Errorf("rpc: connection is shut down")
+
+// SocketPath resolves the socket path for a repo. socket may be empty for
+// the default, and relative paths are relative to the repo
+func SocketPath(repoPath, socket string) string {
+	if socket == "" {
+		socket = DefaultSocket
+	}
+	if filepath.IsAbs(socket) {
+		return socket
+	}
+	return filepath.Join(repoPath, socket)
+}
+
+// hello is the first message a client sends
+type hello struct {
+	Token string `json:"token"`
+}
+
+// welcome answers hello
+type welcome struct {
+	Error string `json:"error,omitempty"`
+}
+
+// request is sent from client to server, either to start or cancel a call
+type request struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	// Streams names params fields that are io.Writers the server should
+	// stream back to the client
+	Streams []string `json:"streams,omitempty"`
+	// Cancel the call with ID
+	Cancel bool `json:"cancel,omitempty"`
+	// RequestID tags log lines in the daemon with the request that made the
+	// call
+	RequestID string `json:"requestID,omitempty"`
+}
+
+// response is sent from server to client. A call gets any number of stream
+// & event responses, then a single final response with Done set
+type response struct {
+	ID     uint64          `json:"id"`
+	Stream string          `json:"stream,omitempty"`
+	Data   []byte          `json:"data,omitempty"`
+	Event  *Event          `json:"event,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Done   bool            `json:"done,omitempty"`
+}
+
+// Event is a bus event published in the daemon while a call runs
+type Event struct {
+	Topic   event.Topic     `json:"topic"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// newToken creates a random token & writes it to path, readable only by the
+// current user
+func newToken(path string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	// remove any previous token first so permissions always apply
+	os.Remove(path)
+	if err := ioutil.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// readToken reads a token file
+func readToken(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
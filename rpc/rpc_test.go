@@ -0,0 +1,229 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logging"
+)
+
+type EchoParams struct {
+	Msg    string
+	Output io.Writer
+}
+
+type Echo struct {
+	bus     event.Bus
+	started chan struct{}
+}
+
+func (e *Echo) Echo(p *EchoParams, res *string) error {
+	return fmt.Errorf("Echo should be replaced by EchoContext")
+}
+
+func (e *Echo) EchoContext(ctx context.Context, p *EchoParams, res *string) error {
+	if p.Msg == "" {
+		return fmt.Errorf("empty message")
+	}
+	if p.Output != nil {
+		fmt.Fprintf(p.Output, "echoing %s\n", p.Msg)
+	}
+	if e.bus != nil {
+		// events from other requests aren't sent to the caller
+		e.bus.PublishContext(logging.WithRequestID(ctx, "other"), event.Topic("echo"), "other request")
+		e.bus.Publish(event.Topic("echo"), "no request")
+		e.bus.PublishContext(ctx, event.Topic("echo"), p.Msg)
+		// the bus publishes asynchronously, give the event time to be sent
+		time.Sleep(50 * time.Millisecond)
+	}
+	*res = p.Msg
+	return nil
+}
+
+func (e *Echo) Wait(p *EchoParams, res *string) error {
+	return fmt.Errorf("Wait should be replaced by WaitContext")
+}
+
+func (e *Echo) WaitContext(ctx context.Context, p *EchoParams, res *string) error {
+	close(e.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (e *Echo) RequestID(p *EchoParams, res *string) error {
+	return fmt.Errorf("RequestID should be replaced by RequestIDContext")
+}
+
+func (e *Echo) RequestIDContext(ctx context.Context, p *EchoParams, res *string) error {
+	*res = logging.RequestID(ctx)
+	return nil
+}
+
+func startServer(t *testing.T, rcvr *Echo) (path string, stop func()) {
+	dir, err := ioutil.TempDir("", "qri_rpc_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path = filepath.Join(dir, DefaultSocket)
+	l, s, err := Listen(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Bus = rcvr.bus
+	s.Topics = []event.Topic{"echo"}
+	if err := s.Register(rcvr); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.Serve(ctx, l)
+	return path, func() {
+		cancel()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCall(t *testing.T) {
+	ctx := context.Background()
+	bus := event.NewBus(ctx)
+	path, stop := startServer(t, &Echo{bus: bus})
+	defer stop()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected socket permissions 0600, got %o", perm)
+	}
+
+	cli, err := Dial(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	var (
+		lk     sync.Mutex
+		events []Event
+	)
+	cli.OnEvent = func(e Event) {
+		lk.Lock()
+		events = append(events, e)
+		lk.Unlock()
+	}
+
+	out := &bytes.Buffer{}
+	res := ""
+	if err := cli.Call("Echo.Echo", &EchoParams{Msg: "hello", Output: out}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res != "hello" {
+		t.Errorf("expected result hello, got %q", res)
+	}
+	if out.String() != "echoing hello\n" {
+		t.Errorf("expected output to stream to the caller, got %q", out.String())
+	}
+	lk.Lock()
+	defer lk.Unlock()
+	if len(events) != 1 || string(events[0].Payload) != `"hello"` {
+		t.Errorf("expected only the echo event of the call, got %v", events)
+	}
+
+	if err := cli.Call("Echo.Echo", &EchoParams{}, &res); err == nil || err.Error() != "empty message" {
+		t.Errorf("expected method errors to be returned, got %v", err)
+	}
+	if err := cli.Call("Echo.Missing", &EchoParams{}, &res); err == nil {
+		t.Errorf("expected an error calling an unknown method")
+	}
+}
+
+func TestCallRequestID(t *testing.T) {
+	ctx := context.Background()
+	path, stop := startServer(t, &Echo{})
+	defer stop()
+
+	cli, err := Dial(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	res := ""
+	if err := cli.CallContext(logging.WithRequestID(ctx, "abc123"), "Echo.RequestID", &EchoParams{}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res != "abc123" {
+		t.Errorf("expected the call context to carry request ID abc123, got %q", res)
+	}
+
+	if err := cli.Call("Echo.RequestID", &EchoParams{}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !logging.ValidRequestID(res) || res == "abc123" {
+		t.Errorf("expected calls without a request ID to be given a new one, got %q", res)
+	}
+}
+
+func TestCallCancel(t *testing.T) {
+	rcvr := &Echo{started: make(chan struct{})}
+	path, stop := startServer(t, rcvr)
+	defer stop()
+
+	cli, err := Dial(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-rcvr.started
+		cancel()
+	}()
+
+	errs := make(chan error)
+	go func() {
+		res := ""
+		errs <- cli.CallContext(ctx, "Echo.Wait", &EchoParams{}, &res)
+	}()
+
+	select {
+	case err := <-errs:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the cancelled call to return")
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	path, stop := startServer(t, &Echo{})
+	defer stop()
+
+	tokenPath := filepath.Join(filepath.Dir(path), TokenFilename)
+	if err := ioutil.WriteFile(tokenPath, []byte("wrong"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Dial(context.Background(), path); err != ErrUnauthorized {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestListenTwice(t *testing.T) {
+	path, stop := startServer(t, &Echo{})
+	defer stop()
+
+	if _, _, err := Listen(path); err == nil {
+		t.Errorf("expected an error listening on a socket that's in use")
+	}
+}
@@ -0,0 +1,378 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logging"
+)
+
+var (
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeOfWriter  = reflect.TypeOf((*io.Writer)(nil)).Elem()
+)
+
+// method is a registered method. Methods have the signature
+// func(args *T, reply *R) error, like net/rpc methods. A method named
+// NameContext with the signature func(ctx, args *T, reply *R) error is
+// used in place of Name, receiving a context that's cancelled with the call
+type method struct {
+	rcvr     reflect.Value
+	fn       reflect.Value
+	withCtx  bool
+	argType  reflect.Type
+	replyPtr reflect.Type
+}
+
+// Server answers calls on registered receivers
+type Server struct {
+	// Bus events on Topics are sent to clients while their calls run
+	Bus    event.Bus
+	Topics []event.Topic
+
+	token   string
+	methods map[string]*method
+}
+
+// NewServer creates a server. Clients must present token to make calls
+func NewServer(token string) *Server {
+	return &Server{token: token, methods: map[string]*method{}}
+}
+
+// Register publishes the methods of rcvr under its type name, as
+// "TypeName.Method"
+func (s *Server) Register(rcvr interface{}) error {
+	v := reflect.ValueOf(rcvr)
+	name := reflect.Indirect(v).Type().Name()
+	if name == "" {
+		return fmt.Errorf("rpc: can't register unnamed type %s", v.Type())
+	}
+
+	registered := 0
+	t := v.Type()
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		key := name + "." + strings.TrimSuffix(m.Name, "Context")
+		mt, ok := methodType(m)
+		if !ok {
+			continue
+		}
+		// context methods replace their plain counterpart
+		if prev, exists := s.methods[key]; exists && prev.withCtx {
+			continue
+		}
+		mt.rcvr = v
+		s.methods[key] = mt
+		registered++
+	}
+	if registered == 0 {
+		return fmt.Errorf("rpc: type %s has no methods of suitable type", name)
+	}
+	return nil
+}
+
+func methodType(m reflect.Method) (*method, bool) {
+	mt := m.Type
+	if m.PkgPath != "" || mt.NumOut() != 1 || mt.Out(0) != typeOfError {
+		return nil, false
+	}
+	// inputs include the receiver
+	switch {
+	case mt.NumIn() == 3:
+		if mt.In(2).Kind() != reflect.Ptr || strings.HasSuffix(m.Name, "Context") {
+			return nil, false
+		}
+		return &method{fn: m.Func, argType: mt.In(1), replyPtr: mt.In(2)}, true
+	case mt.NumIn() == 4 && mt.In(1) == typeOfContext && strings.HasSuffix(m.Name, "Context"):
+		if mt.In(3).Kind() != reflect.Ptr {
+			return nil, false
+		}
+		return &method{fn: m.Func, withCtx: true, argType: mt.In(2), replyPtr: mt.In(3)}, true
+	}
+	return nil, false
+}
+
+// Listen creates a unix domain socket at path that only the current user
+// can connect to, replacing any stale socket left by a previous daemon.
+// Clients authenticate with a token written to TokenFilename in the same
+// directory
+func Listen(path string) (net.Listener, *Server, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("rpc: a daemon is already listening on %s", path)
+	}
+	os.Remove(path)
+
+	token, err := newToken(filepath.Join(filepath.Dir(path), TokenFilename))
+	if err != nil {
+		return nil, nil, fmt.Errorf("rpc: writing token: %s", err)
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, nil, err
+	}
+	return l, NewServer(token), nil
+}
+
+// Serve accepts connections until ctx is cancelled or the listener fails
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.ServeConn(ctx, conn)
+	}
+}
+
+// ServeConn answers calls on a single connection. Calls still running when
+// the connection closes are cancelled
+func (s *Server) ServeConn(ctx context.Context, conn io.ReadWriteCloser) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer conn.Close()
+
+	c := &serverConn{
+		server: s,
+		enc:    json.NewEncoder(conn),
+		calls:  map[uint64]context.CancelFunc{},
+	}
+	dec := json.NewDecoder(bufio.NewReader(conn))
+
+	h := hello{}
+	if err := dec.Decode(&h); err != nil {
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(h.Token), []byte(s.token)) != 1 {
+		log.Infof("rejecting rpc connection with an invalid token")
+		c.enc.Encode(welcome{Error: ErrUnauthorized.Error()})
+		return
+	}
+	if err := c.enc.Encode(welcome{}); err != nil {
+		return
+	}
+
+	for {
+		req := request{}
+		if err := dec.Decode(&req); err != nil {
+			if err != io.EOF {
+				log.Debugf("reading rpc request: %s", err)
+			}
+			return
+		}
+		if req.Cancel {
+			c.cancel(req.ID)
+			continue
+		}
+		callCtx, cancelCall := context.WithCancel(logging.WithRequestID(ctx, req.RequestID))
+		c.start(req.ID, cancelCall)
+		go c.call(callCtx, req)
+	}
+}
+
+// serverConn tracks the calls running on a connection
+type serverConn struct {
+	server *Server
+
+	sendLk sync.Mutex
+	enc    *json.Encoder
+
+	callsLk sync.Mutex
+	calls   map[uint64]context.CancelFunc
+}
+
+func (c *serverConn) send(res response) error {
+	c.sendLk.Lock()
+	defer c.sendLk.Unlock()
+	return c.enc.Encode(res)
+}
+
+func (c *serverConn) start(id uint64, cancel context.CancelFunc) {
+	c.callsLk.Lock()
+	c.calls[id] = cancel
+	c.callsLk.Unlock()
+}
+
+func (c *serverConn) cancel(id uint64) {
+	c.callsLk.Lock()
+	cancel, ok := c.calls[id]
+	delete(c.calls, id)
+	c.callsLk.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (c *serverConn) call(ctx context.Context, req request) {
+	defer c.cancel(req.ID)
+	reqLog.Debugf(ctx, "rpc call %s", req.Method)
+
+	res := response{ID: req.ID, Done: true}
+	reply, err := c.invoke(ctx, req)
+	if err == nil {
+		res.Result, err = json.Marshal(reply)
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if err := c.send(res); err != nil {
+		log.Debugf("sending rpc response: %s", err)
+	}
+}
+
+func (c *serverConn) invoke(ctx context.Context, req request) (reply interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			reqLog.Errorf(ctx, "rpc: panic in %s: %v", req.Method, r)
+			err = fmt.Errorf("rpc: %s failed: %v", req.Method, r)
+		}
+	}()
+
+	m, ok := c.server.methods[req.Method]
+	if !ok {
+		return nil, fmt.Errorf("rpc: can't find method %s", req.Method)
+	}
+
+	// decode into a new value, passing pointer params as the pointer
+	var argv reflect.Value
+	if m.argType.Kind() == reflect.Ptr {
+		argv = reflect.New(m.argType.Elem())
+	} else {
+		argv = reflect.New(m.argType)
+	}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, argv.Interface()); err != nil {
+			return nil, fmt.Errorf("rpc: decoding params: %s", err)
+		}
+	}
+	if err := c.attachStreams(req.ID, argv.Elem(), req.Streams); err != nil {
+		return nil, err
+	}
+	if m.argType.Kind() != reflect.Ptr {
+		argv = argv.Elem()
+	}
+	replyv := reflect.New(m.replyPtr.Elem())
+
+	// only events the call causes are forwarded, calls without a request ID
+	// can't be matched with theirs
+	if reqID := logging.RequestID(ctx); c.server.Bus != nil && len(c.server.Topics) > 0 && reqID != "" {
+		stop := c.forwardEvents(ctx, req.ID, reqID)
+		defer stop()
+	}
+
+	args := []reflect.Value{m.rcvr}
+	if m.withCtx {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+	args = append(args, argv, replyv)
+
+	out := m.fn.Call(args)
+	if err, _ := out[0].Interface().(error); err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return replyv.Interface(), nil
+}
+
+// attachStreams sets the named io.Writer fields of params to writers that
+// stream to the client
+func (c *serverConn) attachStreams(id uint64, params reflect.Value, streams []string) error {
+	if len(streams) == 0 {
+		return nil
+	}
+	st := params
+	if st.Kind() != reflect.Struct {
+		return fmt.Errorf("rpc: params of type %s can't have streams", params.Type())
+	}
+	for _, name := range streams {
+		f := st.FieldByName(name)
+		if !f.IsValid() || f.Type() != typeOfWriter || !f.CanSet() {
+			return fmt.Errorf("rpc: %s is not a writer field of %s", name, st.Type())
+		}
+		f.Set(reflect.ValueOf(&streamWriter{conn: c, id: id, name: name}))
+	}
+	return nil
+}
+
+// forwardEvents sends bus events published with the call's request ID to the
+// client until the returned func is called. The bus publishes
+// asynchronously, so forwarding is best-effort: events published just as a
+// call returns may not be sent
+func (c *serverConn) forwardEvents(ctx context.Context, id uint64, reqID string) (stop func()) {
+	bus := c.server.Bus
+	events := bus.Subscribe(c.server.Topics...)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case e := <-events:
+				if e.RequestID != reqID {
+					continue
+				}
+				payload, err := json.Marshal(e.Payload)
+				if err != nil {
+					log.Debugf("encoding rpc event: %s", err)
+					continue
+				}
+				c.send(response{ID: id, Event: &Event{Topic: e.Topic, Payload: payload}})
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		// unsubscribing abandons deliveries still in flight
+		bus.Unsubscribe(events)
+	}
+}
+
+// streamWriter sends writes to the client as stream responses
+type streamWriter struct {
+	conn *serverConn
+	id   uint64
+	name string
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	if err := w.conn.send(response{ID: w.id, Stream: w.name, Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cube2222/octosql/execution"
+	"github.com/cube2222/octosql/output"
+)
+
+// ErrRowLimit is returned by Exec when a query produced more rows than the
+// service's MaxRows. Output is complete up to the limit
+var ErrRowLimit = fmt.Errorf("row limit reached")
+
+// csvOutput writes delimited rows as records arrive. octosql's csv output
+// holds every record in memory to build a header row, csvOutput takes the
+// header from the first record instead
+type csvOutput struct {
+	w      *csv.Writer
+	fields []execution.Field
+}
+
+func newCSVOutput(separator rune, w io.Writer) output.Output {
+	cw := csv.NewWriter(w)
+	cw.Comma = separator
+	return &csvOutput{w: cw}
+}
+
+func (o *csvOutput) WriteRecord(record *execution.Record) error {
+	if o.fields == nil {
+		o.fields = record.Fields()
+		header := make([]string, len(o.fields))
+		for i, f := range o.fields {
+			header[i] = f.Name.String()
+		}
+		if err := o.w.Write(header); err != nil {
+			return err
+		}
+	}
+
+	row := make([]string, len(o.fields))
+	for i, f := range o.fields {
+		row[i] = csvValue(record.Value(f.Name).ToRawValue())
+	}
+	if err := o.w.Write(row); err != nil {
+		return err
+	}
+	o.w.Flush()
+	return o.w.Error()
+}
+
+// csvValue formats a raw value as a csv cell. Value.Show quotes strings,
+// which is right for tables but not for delimited data
+func csvValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func (o *csvOutput) Close() error {
+	o.w.Flush()
+	return o.w.Error()
+}
+
+// jsonOutput writes records as an array of objects as they arrive
+type jsonOutput struct {
+	w       io.Writer
+	written int
+}
+
+func newJSONOutput(w io.Writer) output.Output {
+	return &jsonOutput{w: w}
+}
+
+func (o *jsonOutput) WriteRecord(record *execution.Record) error {
+	obj := make(map[string]interface{})
+	for _, f := range record.Fields() {
+		obj[f.Name.String()] = record.Value(f.Name).ToRawValue()
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("encoding record as json: %s", err)
+	}
+
+	sep := []byte{','}
+	if o.written == 0 {
+		sep = []byte{'['}
+	}
+	o.written++
+	_, err = o.w.Write(append(sep, data...))
+	return err
+}
+
+func (o *jsonOutput) Close() error {
+	closing := "]"
+	if o.written == 0 {
+		closing = "[]"
+	}
+	_, err := io.WriteString(o.w, closing)
+	return err
+}
+
+// boundedOutput stops a query once its context is done or it has written
+// max records. max of 0 means no limit
+type boundedOutput struct {
+	output.Output
+	ctx     context.Context
+	max     int
+	written int
+}
+
+func (o *boundedOutput) WriteRecord(record *execution.Record) error {
+	if err := o.ctx.Err(); err != nil {
+		return err
+	}
+	if o.max > 0 && o.written >= o.max {
+		return ErrRowLimit
+	}
+	o.written++
+	return o.Output.WriteRecord(record)
+}
@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/cube2222/octosql"
+	"github.com/cube2222/octosql/execution"
+	"github.com/cube2222/octosql/output"
+)
+
+func testRecords() []*execution.Record {
+	fields := []octosql.VariableName{octosql.NewVariableName("title"), octosql.NewVariableName("duration")}
+	return []*execution.Record{
+		execution.NewRecordFromSlice(fields, []octosql.Value{octosql.MakeString("Avatar"), octosql.MakeInt(178)}),
+		execution.NewRecordFromSlice(fields, []octosql.Value{octosql.MakeString("Spectre"), octosql.MakeInt(148)}),
+	}
+}
+
+func writeRecords(t *testing.T, out output.Output, records []*execution.Record) {
+	for _, rec := range records {
+		if err := out.WriteRecord(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCSVOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := newCSVOutput('\t', buf)
+	if err := out.WriteRecord(testRecords()[0]); err != nil {
+		t.Fatal(err)
+	}
+	// rows are flushed as they're written
+	expect := "title\tduration\nAvatar\t178\n"
+	if buf.String() != expect {
+		t.Errorf("expected %q after first record, got %q", expect, buf.String())
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	writeRecords(t, newCSVOutput(',', buf), testRecords())
+	expect = "title,duration\nAvatar,178\nSpectre,148\n"
+	if buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeRecords(t, newJSONOutput(buf), testRecords())
+	expect := `[{"duration":178,"title":"Avatar"},{"duration":148,"title":"Spectre"}]`
+	if buf.String() != expect {
+		t.Errorf("expected %s, got %s", expect, buf.String())
+	}
+
+	buf.Reset()
+	writeRecords(t, newJSONOutput(buf), nil)
+	if buf.String() != "[]" {
+		t.Errorf("expected empty output to be an empty array, got %s", buf.String())
+	}
+}
+
+func TestBoundedOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := &boundedOutput{Output: newJSONOutput(buf), ctx: context.Background(), max: 1}
+	records := testRecords()
+	if err := out.WriteRecord(records[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.WriteRecord(records[1]); err != ErrRowLimit {
+		t.Errorf("expected ErrRowLimit, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out = &boundedOutput{Output: newJSONOutput(buf), ctx: ctx}
+	if err := out.WriteRecord(records[0]); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
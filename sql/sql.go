@@ -9,8 +9,6 @@ import (
 	"github.com/cube2222/octosql/app"
 	octosqlcfg "github.com/cube2222/octosql/config"
 	"github.com/cube2222/octosql/output"
-	csvoutput "github.com/cube2222/octosql/output/csv"
-	jsonoutput "github.com/cube2222/octosql/output/json"
 	"github.com/cube2222/octosql/output/table"
 	"github.com/cube2222/octosql/parser"
 	"github.com/cube2222/octosql/parser/sqlparser"
@@ -27,17 +25,32 @@ var log = golog.Logger("sql")
 
 // Service executes SQL queries against qri datasets
 type Service struct {
-	r repo.Repo
+	r       repo.Repo
+	maxRows int
+}
+
+// Options configure an SQL service
+type Options struct {
+	// MaxRows stops queries after they've returned this many rows. 0 means
+	// no limit
+	MaxRows int
 }
 
 // New creates an SQL service
-func New(r repo.Repo) *Service {
+func New(r repo.Repo, opts ...func(o *Options)) *Service {
+	o := &Options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return &Service{
-		r: r,
+		r:       r,
+		maxRows: o.MaxRows,
 	}
 }
 
-// Exec runs an SQL query against a given dataset mapping
+// Exec runs an SQL query against a given dataset mapping, writing results to
+// w as they're produced. Queries stop when ctx is done. Exec returns
+// ErrRowLimit after writing MaxRows rows if the query had more
 func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query string) error {
 	processedQuery, sources, err := preprocess.Query(query)
 	if err != nil {
@@ -79,18 +92,19 @@ func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query stri
 	case "table_row_separated":
 		out = table.NewOutput(w, true)
 	case "json":
-		out = jsonoutput.NewOutput(w)
+		out = newJSONOutput(w)
 	case "csv":
-		out = csvoutput.NewOutput(',', w)
+		out = newCSVOutput(',', w)
 	case "tabbed":
-		out = csvoutput.NewOutput('\t', w)
+		out = newCSVOutput('\t', w)
 	default:
-		err = fmt.Errorf("invalid output type: %s", w)
+		err = fmt.Errorf("invalid output type: %s", outFormat)
 		log.Error(err)
 		return err
 	}
 
-	app := app.NewApp(cfg, dataSourceRespository, out, false)
+	bounded := &boundedOutput{Output: out, ctx: ctx, max: svc.maxRows}
+	app := app.NewApp(cfg, dataSourceRespository, bounded, false)
 
 	// Parse query
 	stmt, err := sqlparser.Parse(processedQuery)
@@ -119,6 +133,16 @@ Error:
 
 	// Run query
 	err = app.RunPlan(ctx, plan)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Cause(err) == ErrRowLimit {
+		// hitting the row limit truncates results, finish writing output
+		if err := out.Close(); err != nil {
+			return err
+		}
+		return ErrRowLimit
+	}
 	return unwrapErr(err)
 }
 
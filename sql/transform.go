@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/sql/preprocess"
+	"github.com/qri-io/qri/startf"
+)
+
+// TransformSyntax is the name of the SQL transform language
+const TransformSyntax = "sql"
+
+// TransformVersion is the version of the SQL transform runner, recorded as
+// the syntax version of transforms it runs
+var TransformVersion = "0.1.0"
+
+func init() {
+	startf.RegisterRunner(TransformSyntax, startf.RunnerFunc(ExecTransform), ".sql")
+}
+
+// ExecTransform runs a transform script that is a single SQL SELECT statement
+// over other datasets in the repo, setting the next dataset body to the query
+// results. SQL transforms are sandboxed: they can only read datasets from the
+// repo provided in exec options, can't make network requests, and can't modify
+// any component other than body and structure
+func ExecTransform(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *startf.ExecOpts)) error {
+	if next.Transform == nil || next.Transform.ScriptFile() == nil {
+		return fmt.Errorf("no script to execute")
+	}
+
+	o := &startf.ExecOpts{}
+	startf.DefaultExecOpts(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.Repo == nil {
+		return fmt.Errorf("sql transforms require a repo to query")
+	}
+
+	if o.MutateFieldCheck != nil {
+		for _, path := range []string{"body", "structure"} {
+			if err := o.MutateFieldCheck(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	script := next.Transform.ScriptFile()
+	data, err := ioutil.ReadAll(script)
+	if err != nil {
+		return err
+	}
+	// restore consumed script file
+	next.Transform.SetScriptFile(qfs.NewMemfileBytes(script.FileName(), data))
+
+	query := strings.TrimSpace(string(data))
+	if query == "" {
+		return fmt.Errorf("sql transform script is empty")
+	}
+
+	next.Transform.Syntax = TransformSyntax
+	next.Transform.SyntaxVersion = TransformVersion
+
+	// record queried datasets as transform dependencies
+	_, sources, err := preprocess.Query(query)
+	if err != nil {
+		return err
+	}
+	startf.ClearDatasetResources(next.Transform)
+	for _, refStr := range sources {
+		ref, err := repo.ParseDatasetRef(refStr)
+		if err != nil {
+			return err
+		}
+		if err := repo.CanonicalizeDatasetRef(o.Repo, &ref); err != nil {
+			return fmt.Errorf("%s: %s", refStr, err)
+		}
+		startf.RecordDatasetResource(next.Transform, ref)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := New(o.Repo).Exec(ctx, buf, "json", query); err != nil {
+		return err
+	}
+
+	body := bytes.TrimSpace(buf.Bytes())
+
+	if next.Structure == nil {
+		next.Structure = &dataset.Structure{}
+	}
+	next.Structure.Format = "json"
+	next.Structure.FormatConfig = nil
+	next.Structure.Schema = dataset.BaseSchemaArray
+	next.SetBodyFile(qfs.NewMemfileBytes("body.json", body))
+	return nil
+}
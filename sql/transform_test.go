@@ -0,0 +1,91 @@
+package sql
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base"
+	repotest "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/startf"
+)
+
+func TestExecTransform(t *testing.T) {
+	ctx := context.Background()
+	r, err := repotest.NewTestRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte("SELECT t1.title FROM me/movies t1 LIMIT 1")))
+
+	if err := startf.Run(ctx, ds, nil, startf.AddQriRepo(r)); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Transform.Syntax != TransformSyntax {
+		t.Errorf("syntax mismatch. expected: %q, got: %q", TransformSyntax, ds.Transform.Syntax)
+	}
+	if ds.Transform.SyntaxVersion != TransformVersion {
+		t.Errorf("syntax version mismatch. expected: %q, got: %q", TransformVersion, ds.Transform.SyntaxVersion)
+	}
+	if ds.Structure == nil || ds.Structure.Format != "json" {
+		t.Fatalf("expected a json structure, got: %v", ds.Structure)
+	}
+
+	if deps := startf.DatasetResources(ds.Transform); len(deps) != 1 || deps[0].Name != "movies" {
+		t.Errorf("expected movies to be recorded as a dependency, got: %v", deps)
+	}
+
+	body, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "[{\"t1.title\":\"Avatar \"}\n]"
+	if diff := cmp.Diff(expect, string(body)); diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+
+	script, err := ioutil.ReadAll(ds.Transform.ScriptFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(script) != "SELECT t1.title FROM me/movies t1 LIMIT 1" {
+		t.Errorf("expected script file to be restored, got: %q", string(script))
+	}
+}
+
+func TestExecTransformDryRun(t *testing.T) {
+	ctx := context.Background()
+	r, err := repotest.NewTestRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dry-runs save to an empty in-memory repo, queries must still read the
+	// real one
+	ds := &dataset.Dataset{Peername: "me", Name: "movie_titles", Transform: &dataset.Transform{}}
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte("SELECT t1.title FROM me/movies t1 LIMIT 1")))
+	streams := ioes.NewDiscardIOStreams()
+	if _, err := base.SaveDataset(ctx, r, streams, ds, nil, nil, base.SaveSwitches{DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecTransformErrors(t *testing.T) {
+	ctx := context.Background()
+
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	if err := ExecTransform(ctx, ds, nil); err == nil {
+		t.Error("expected a transform without a script to error")
+	}
+
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.sql", []byte("SELECT * FROM me/movies")))
+	if err := ExecTransform(ctx, ds, nil); err == nil {
+		t.Error("expected a transform without a repo to error")
+	}
+}
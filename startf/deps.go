@@ -0,0 +1,57 @@
+package startf
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// RecordDatasetResource adds a dataset loaded during transform execution to
+// the transform's resources. Dataset resources are keyed by the path of the
+// loaded version, with the full reference string as the resource path
+func RecordDatasetResource(tf *dataset.Transform, ref reporef.DatasetRef) {
+	if tf.Resources == nil {
+		tf.Resources = map[string]*dataset.TransformResource{}
+	}
+	tf.Resources[ref.Path] = &dataset.TransformResource{Path: ref.String()}
+}
+
+// DatasetResources returns references to every dataset a transform loaded,
+// sorted by reference string
+func DatasetResources(tf *dataset.Transform) []reporef.DatasetRef {
+	if tf == nil {
+		return nil
+	}
+	refs := []reporef.DatasetRef{}
+	for key, r := range tf.Resources {
+		if !isDatasetResource(key, r) {
+			continue
+		}
+		ref, err := repo.ParseDatasetRef(r.Path)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].String() < refs[j].String() })
+	return refs
+}
+
+// ClearDatasetResources removes datasets recorded by a previous run, leaving
+// other resources in place
+func ClearDatasetResources(tf *dataset.Transform) {
+	for key, r := range tf.Resources {
+		if isDatasetResource(key, r) {
+			delete(tf.Resources, key)
+		}
+	}
+}
+
+// isDatasetResource reports whether a transform resource records a loaded
+// dataset. dataset resources are keyed by store paths
+func isDatasetResource(key string, r *dataset.TransformResource) bool {
+	return r != nil && strings.HasPrefix(key, "/")
+}
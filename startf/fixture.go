@@ -0,0 +1,212 @@
+package startf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// HTTPFixture is a record of every http request & response made by a
+// transform script through the starlark http module. Fixtures make it possible
+// to re-run a transform without network access, producing the same results
+type HTTPFixture struct {
+	Interactions []*HTTPInteraction `json:"interactions"`
+
+	lk      sync.Mutex
+	cursors map[string]int
+}
+
+// HTTPInteraction is a single recorded request/response pair
+type HTTPInteraction struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// RequestBodyHash is the hex-encoded sha256 sum of the request body
+	RequestBodyHash string `json:"requestBodyHash,omitempty"`
+
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+
+	// anyBody answers requests whatever their body, used by mocks that don't
+	// set a request body
+	anyBody bool
+}
+
+// key identifies requests that should be answered with the same response
+func (i *HTTPInteraction) key() string {
+	return fmt.Sprintf("%s %s %s", i.Method, i.URL, i.RequestBodyHash)
+}
+
+// ParseHTTPFixture decodes a JSON-encoded fixture
+func ParseHTTPFixture(data []byte) (*HTTPFixture, error) {
+	f := &HTTPFixture{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("parsing http fixture: %w", err)
+	}
+	return f, nil
+}
+
+// Len returns the number of recorded interactions
+func (f *HTTPFixture) Len() int {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+	return len(f.Interactions)
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (f *HTTPFixture) MarshalJSON() ([]byte, error) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+	return json.Marshal(struct {
+		Interactions []*HTTPInteraction `json:"interactions"`
+	}{f.Interactions})
+}
+
+// RecordingClient returns an http client that performs requests with the
+// default transport, appending each interaction to the fixture
+func (f *HTTPFixture) RecordingClient() *http.Client {
+	return &http.Client{Transport: &recordingTransport{fixture: f, rt: http.DefaultTransport}}
+}
+
+// ReplayingClient returns an http client that answers requests from recorded
+// interactions, never touching the network
+func (f *HTTPFixture) ReplayingClient() *http.Client {
+	return &http.Client{Transport: &replayingTransport{fixture: f}}
+}
+
+func (f *HTTPFixture) add(i *HTTPInteraction) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+	f.Interactions = append(f.Interactions, i)
+}
+
+// match finds the next recorded interaction for a request. repeated identical
+// requests are answered in the order they were recorded, reusing the last
+// response once recordings are exhausted. Interactions that match any body
+// only answer requests no interaction matches exactly
+func (f *HTTPFixture) match(req *HTTPInteraction) (*HTTPInteraction, bool) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+	if f.cursors == nil {
+		f.cursors = map[string]int{}
+	}
+
+	key := req.key()
+	var found []*HTTPInteraction
+	for _, i := range f.Interactions {
+		if i.key() == key {
+			found = append(found, i)
+		}
+	}
+	if len(found) == 0 {
+		for _, i := range f.Interactions {
+			if i.anyBody && i.Method == req.Method && i.URL == req.URL {
+				found = append(found, i)
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil, false
+	}
+
+	idx := f.cursors[key]
+	if idx >= len(found) {
+		idx = len(found) - 1
+	}
+	f.cursors[key] = idx + 1
+	return found[idx], true
+}
+
+// requestInteraction reads a request into an interaction, restoring the
+// consumed request body
+func requestInteraction(req *http.Request) (*HTTPInteraction, error) {
+	i := &HTTPInteraction{
+		Method: req.Method,
+		URL:    req.URL.String(),
+	}
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		i.RequestBodyHash = bodyHash(data)
+	}
+	return i, nil
+}
+
+// bodyHash is the RequestBodyHash of a request body, empty for requests
+// without a body
+func bodyHash(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type recordingTransport struct {
+	fixture *HTTPFixture
+	rt      http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i, err := requestInteraction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	i.StatusCode = res.StatusCode
+	i.Header = res.Header
+	i.Body = data
+	t.fixture.add(i)
+	return res, nil
+}
+
+type replayingTransport struct {
+	fixture *HTTPFixture
+}
+
+// RoundTrip implements the http.RoundTripper interface
+func (t *replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i, err := requestInteraction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := t.fixture.match(i)
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s", i.Method, i.URL)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package startf
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"go.starlark.net/starlark"
+)
+
+func TestRecordReplayHTTP(t *testing.T) {
+	ctx := context.Background()
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"foo":["bar","baz","bat"]}`))
+	}))
+
+	record := &HTTPFixture{}
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/fetch.star"))
+	err := ExecScript(ctx, ds, nil, RecordHTTP(record), func(o *ExecOpts) {
+		o.Globals["test_server_url"] = starlark.String(s.URL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request to test server, got: %d", requests)
+	}
+	if record.Len() != 1 {
+		t.Fatalf("expected 1 recorded interaction, got: %d", record.Len())
+	}
+	expectBody, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round-trip through JSON to mimic storing the fixture
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ParseHTTPFixture(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// close the server to ensure replay never touches the network
+	s.Close()
+
+	ds = &dataset.Dataset{
+		Transform: &dataset.Transform{},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/fetch.star"))
+	err = ExecScript(ctx, ds, nil, ReplayHTTP(replay), func(o *ExecOpts) {
+		o.Globals["test_server_url"] = starlark.String(s.URL)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotBody, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(expectBody), string(gotBody)); diff != "" {
+		t.Errorf("replayed body mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReplayHTTPUnrecorded(t *testing.T) {
+	ctx := context.Background()
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{},
+	}
+	ds.Transform.SetScriptFile(scriptFile(t, "testdata/fetch.star"))
+	err := ExecScript(ctx, ds, nil, ReplayHTTP(&HTTPFixture{}), func(o *ExecOpts) {
+		o.Globals["test_server_url"] = starlark.String("http://example.com/unrecorded")
+	})
+	if err == nil {
+		t.Error("expected replaying an unrecorded request to error")
+	}
+}
+
+func TestRecordHTTPConcurrent(t *testing.T) {
+	ctx := context.Background()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"foo":["bar","baz","bat"]}`))
+	}))
+	defer s.Close()
+
+	records := make([]*HTTPFixture, 8)
+	errs := make(chan error, len(records))
+	for i := range records {
+		records[i] = &HTTPFixture{}
+		go func(record *HTTPFixture) {
+			ds := &dataset.Dataset{
+				Transform: &dataset.Transform{},
+			}
+			ds.Transform.SetScriptFile(scriptFile(t, "testdata/fetch.star"))
+			errs <- ExecScript(ctx, ds, nil, RecordHTTP(record), func(o *ExecOpts) {
+				o.Globals["test_server_url"] = starlark.String(s.URL)
+			})
+		}(records[i])
+	}
+	for range records {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, record := range records {
+		if record.Len() != 1 {
+			t.Errorf("record %d: expected 1 recorded interaction, got: %d", i, record.Len())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package startf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	starhttp "github.com/qri-io/starlib/http"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// loadHTTPModule creates an http module that makes requests with cli. It
+// mirrors the starlib http module, which only reads a package-level client,
+// so transforms that record or replay requests can run concurrently
+func loadHTTPModule(cli *http.Client) starlark.StringDict {
+	methods := starlark.StringDict{}
+	for _, method := range []string{"get", "put", "post", "delete", "patch", "options"} {
+		methods[method] = starlark.NewBuiltin(method, httpReqMethod(cli, method))
+	}
+	return starlark.StringDict{
+		"http": starlarkstruct.FromStringDict(starlarkstruct.Default, methods),
+	}
+}
+
+func httpReqMethod(cli *http.Client, method string) func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			urlv     starlark.String
+			params   = &starlark.Dict{}
+			headers  = &starlark.Dict{}
+			formBody = &starlark.Dict{}
+			auth     starlark.Tuple
+			body     starlark.String
+			jsonBody starlark.Value
+		)
+
+		if err := starlark.UnpackArgs(method, args, kwargs, "url", &urlv, "params?", &params, "headers", &headers, "body", &body, "form_body", &formBody, "json_body", &jsonBody, "auth", &auth); err != nil {
+			return nil, err
+		}
+
+		rawurl, err := setHTTPQueryParams(urlv.GoString(), params)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(strings.ToUpper(method), rawurl, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := httpGuard.Allowed(req); err != nil {
+			return nil, err
+		}
+
+		if err := eachStringItem(headers, func(k, v string) { req.Header.Add(k, v) }); err != nil {
+			return nil, err
+		}
+		if err := setHTTPAuth(req, auth); err != nil {
+			return nil, err
+		}
+		if err := setHTTPBody(req, body, formBody, jsonBody); err != nil {
+			return nil, err
+		}
+
+		res, err := cli.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		r := &starhttp.Response{Response: *res}
+		return r.Struct(), nil
+	}
+}
+
+func setHTTPQueryParams(rawurl string, params *starlark.Dict) (string, error) {
+	if params.Len() == 0 {
+		return rawurl, nil
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if err := eachStringItem(params, q.Set); err != nil {
+		return "", err
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func setHTTPAuth(req *http.Request, auth starlark.Tuple) error {
+	if len(auth) == 0 {
+		return nil
+	} else if len(auth) != 2 {
+		return fmt.Errorf("expected two values for auth params tuple")
+	}
+	username, ok := starlark.AsString(auth[0])
+	if !ok {
+		return fmt.Errorf("parsing auth username string: expected a string")
+	}
+	password, ok := starlark.AsString(auth[1])
+	if !ok {
+		return fmt.Errorf("parsing auth password string: expected a string")
+	}
+	req.SetBasicAuth(username, password)
+	return nil
+}
+
+func setHTTPBody(req *http.Request, body starlark.String, formData *starlark.Dict, jsondata starlark.Value) error {
+	if !util.IsEmptyString(body) {
+		req.Body = ioutil.NopCloser(strings.NewReader(body.GoString()))
+		return nil
+	}
+
+	if jsondata != nil && jsondata.String() != "" {
+		req.Header.Set("Content-Type", "application/json")
+		v, err := util.Unmarshal(jsondata)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+	}
+
+	if formData != nil && formData.Len() > 0 {
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "multipart/form-data")
+		}
+		if req.Form == nil {
+			req.Form = url.Values{}
+		}
+		return eachStringItem(formData, req.Form.Add)
+	}
+	return nil
+}
+
+// eachStringItem calls fn with each key & value of a dict of strings
+func eachStringItem(d *starlark.Dict, fn func(k, v string)) error {
+	for _, item := range d.Items() {
+		k, ok := starlark.AsString(item[0])
+		if !ok {
+			return fmt.Errorf("expected string keys, got: '%s'", item[0].Type())
+		}
+		v, ok := starlark.AsString(item[1])
+		if !ok {
+			return fmt.Errorf("expected value for key '%s' to be a string. got: '%s'", k, item[1].Type())
+		}
+		fn(k, v)
+	}
+	return nil
+}
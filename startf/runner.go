@@ -0,0 +1,112 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+)
+
+// DefaultSyntax is the transform language assumed when a transform doesn't
+// specify one
+const DefaultSyntax = "starlark"
+
+// Runner executes a transform script written in a single syntax. Like
+// ExecScript, a runner may modify the next dataset pointer while prev is
+// read-only. Runners must set next.Transform.Syntax on success
+type Runner interface {
+	Run(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error
+}
+
+// RunnerFunc adapts a function to the Runner interface
+type RunnerFunc func(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error
+
+// Run calls f
+func (f RunnerFunc) Run(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error {
+	return f(ctx, next, prev, opts...)
+}
+
+var (
+	runnersLk sync.Mutex
+	// runners maps syntax names to runners
+	runners = map[string]Runner{
+		DefaultSyntax: RunnerFunc(ExecScript),
+	}
+	// extensions maps script file extensions to syntax names
+	extensions = map[string]string{
+		".star": DefaultSyntax,
+	}
+)
+
+// RegisterRunner makes a transform runner available for a syntax, and
+// associates script files ending with any of the given extensions with that
+// syntax. Registering an existing syntax replaces the previous runner
+func RegisterRunner(syntax string, r Runner, exts ...string) {
+	runnersLk.Lock()
+	defer runnersLk.Unlock()
+	runners[syntax] = r
+	for _, ext := range exts {
+		extensions[strings.ToLower(ext)] = syntax
+	}
+}
+
+// GetRunner returns the runner registered for a syntax
+func GetRunner(syntax string) (Runner, error) {
+	runnersLk.Lock()
+	defer runnersLk.Unlock()
+	if r, ok := runners[syntax]; ok {
+		return r, nil
+	}
+
+	supported := make([]string, 0, len(runners))
+	for s := range runners {
+		supported = append(supported, s)
+	}
+	sort.Strings(supported)
+	return nil, fmt.Errorf("unsupported transform syntax %q. supported syntaxes are: %s", syntax, strings.Join(supported, ", "))
+}
+
+// SyntaxForFilename returns the syntax registered for the extension of a
+// script filename, and an empty string if the extension is unknown
+func SyntaxForFilename(filename string) string {
+	runnersLk.Lock()
+	defer runnersLk.Unlock()
+	return extensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// TransformSyntax determines the syntax of a transform, preferring an
+// explicitly set Syntax, then the extension of the script file or path,
+// falling back to DefaultSyntax
+func TransformSyntax(tf *dataset.Transform) string {
+	if tf == nil {
+		return DefaultSyntax
+	}
+	if tf.Syntax != "" {
+		return tf.Syntax
+	}
+	if f := tf.ScriptFile(); f != nil {
+		if syntax := SyntaxForFilename(f.FileName()); syntax != "" {
+			return syntax
+		}
+	}
+	if syntax := SyntaxForFilename(tf.ScriptPath); syntax != "" {
+		return syntax
+	}
+	return DefaultSyntax
+}
+
+// Run executes a transform with the runner registered for its syntax
+func Run(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error {
+	if next.Transform == nil {
+		return fmt.Errorf("no script to execute")
+	}
+	r, err := GetRunner(TransformSyntax(next.Transform))
+	if err != nil {
+		return err
+	}
+	return r.Run(ctx, next, prev, opts...)
+}
@@ -0,0 +1,49 @@
+package startf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func TestTransformSyntax(t *testing.T) {
+	RegisterRunner("test_syntax", RunnerFunc(func(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o *ExecOpts)) error {
+		next.Transform.Syntax = "test_syntax"
+		return nil
+	}), ".test")
+
+	withFile := &dataset.Transform{}
+	withFile.SetScriptFile(qfs.NewMemfileBytes("transform.test", nil))
+
+	cases := []struct {
+		tf     *dataset.Transform
+		expect string
+	}{
+		{nil, DefaultSyntax},
+		{&dataset.Transform{}, DefaultSyntax},
+		{&dataset.Transform{Syntax: "test_syntax", ScriptPath: "transform.star"}, "test_syntax"},
+		{&dataset.Transform{ScriptPath: "/path/to/transform.star"}, DefaultSyntax},
+		{&dataset.Transform{ScriptPath: "/path/to/TRANSFORM.TEST"}, "test_syntax"},
+		{&dataset.Transform{ScriptPath: "transform.unknown"}, DefaultSyntax},
+		{withFile, "test_syntax"},
+	}
+	for i, c := range cases {
+		if got := TransformSyntax(c.tf); got != c.expect {
+			t.Errorf("case %d syntax mismatch. expected: %q, got: %q", i, c.expect, got)
+		}
+	}
+
+	ds := &dataset.Dataset{Transform: &dataset.Transform{ScriptPath: "transform.test"}}
+	if err := Run(context.Background(), ds, nil); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Transform.Syntax != "test_syntax" {
+		t.Errorf("expected registered runner to execute, got syntax: %q", ds.Transform.Syntax)
+	}
+
+	if _, err := GetRunner("unregistered"); err == nil {
+		t.Error("expected getting an unregistered runner to error")
+	}
+}
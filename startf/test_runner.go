@@ -0,0 +1,383 @@
+package startf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	skyds "github.com/qri-io/qri/startf/ds"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// TestFuncPrefix is the name prefix of functions RunTests will execute
+const TestFuncPrefix = "test_"
+
+// TestResult is the outcome of running a single transform test function
+type TestResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Error holds the failure message & starlark backtrace of a failed test
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// RunTests executes every test_ function defined in a test script against a
+// transform script. Tests never touch a repo or the network. Instead test
+// scripts have access to the following builtins:
+//   mock_dataset(ref, body=None, meta=None) - respond to load_dataset(ref)
+//   mock_http(url, body="", method="GET", status=200, request_body=None) -
+//     respond to http requests, only those with request_body when it's set
+//   set_prev(body=None, meta=None) - set the previous version of the dataset
+//   run_transform(config=None) - execute the transform, returning the result
+//   assert.eq(a, b), assert.ne(a, b), assert.true(cond, msg=""), assert.fails(fn)
+// Mocks are reset before each test function runs
+func RunTests(ctx context.Context, script, testScript qfs.File, opts ...func(o *ExecOpts)) ([]*TestResult, error) {
+	if script == nil {
+		return nil, fmt.Errorf("no transform script to test")
+	}
+	if testScript == nil {
+		return nil, fmt.Errorf("no test script to run")
+	}
+
+	o := &ExecOpts{}
+	DefaultExecOpts(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	setupEnvironment(o)
+
+	scriptData, err := ioutil.ReadAll(script)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &testRunner{
+		ctx:    ctx,
+		opts:   o,
+		script: scriptData,
+	}
+
+	thread := &starlark.Thread{
+		Load: o.ModuleLoader,
+		Print: func(thread *starlark.Thread, msg string) {
+			_, _ = o.ErrWriter.Write([]byte(msg + "\n"))
+		},
+	}
+
+	globals, err := starlark.ExecFile(thread, testScript.FileName(), testScript, tr.predeclared())
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return nil, fmt.Errorf(evalErr.Backtrace())
+		}
+		return nil, err
+	}
+
+	names := []string{}
+	for name, val := range globals {
+		if _, ok := val.(*starlark.Function); ok && strings.HasPrefix(name, TestFuncPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]*TestResult, 0, len(names))
+	for _, name := range names {
+		tr.reset()
+		res := &TestResult{Name: name}
+		start := time.Now()
+		_, err := starlark.Call(thread, globals[name], nil, nil)
+		res.Duration = time.Since(start)
+		if err != nil {
+			if evalErr, ok := err.(*starlark.EvalError); ok {
+				res.Error = evalErr.Backtrace()
+			} else {
+				res.Error = err.Error()
+			}
+		} else {
+			res.Passed = true
+		}
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// testRunner holds the mocked state a single test function runs against
+type testRunner struct {
+	ctx    context.Context
+	opts   *ExecOpts
+	script []byte
+
+	datasets map[string]*mockDataset
+	fixture  *HTTPFixture
+	prev     *mockDataset
+}
+
+func (tr *testRunner) reset() {
+	tr.datasets = map[string]*mockDataset{}
+	tr.fixture = &HTTPFixture{}
+	tr.prev = nil
+}
+
+func (tr *testRunner) predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"mock_dataset":  starlark.NewBuiltin("mock_dataset", tr.mockDataset),
+		"mock_http":     starlark.NewBuiltin("mock_http", tr.mockHTTP),
+		"set_prev":      starlark.NewBuiltin("set_prev", tr.setPrev),
+		"run_transform": starlark.NewBuiltin("run_transform", tr.runTransform),
+		"assert": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"eq":    starlark.NewBuiltin("eq", assertEq),
+			"ne":    starlark.NewBuiltin("ne", assertNe),
+			"true":  starlark.NewBuiltin("true", assertTrue),
+			"fails": starlark.NewBuiltin("fails", assertFails),
+		}),
+	}
+}
+
+func (tr *testRunner) mockDataset(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		ref        starlark.String
+		body, meta starlark.Value
+	)
+	if err := starlark.UnpackArgs("mock_dataset", args, kwargs, "ref", &ref, "body?", &body, "meta?", &meta); err != nil {
+		return starlark.None, err
+	}
+
+	ds, err := newMockDataset(body, meta)
+	if err != nil {
+		return starlark.None, err
+	}
+	tr.datasets[ref.GoString()] = ds
+	return starlark.None, nil
+}
+
+func (tr *testRunner) setPrev(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var body, meta starlark.Value
+	if err := starlark.UnpackArgs("set_prev", args, kwargs, "body?", &body, "meta?", &meta); err != nil {
+		return starlark.None, err
+	}
+
+	ds, err := newMockDataset(body, meta)
+	if err != nil {
+		return starlark.None, err
+	}
+	tr.prev = ds
+	return starlark.None, nil
+}
+
+func (tr *testRunner) mockHTTP(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		url     starlark.String
+		body    starlark.Value
+		method  = starlark.String("GET")
+		status  = 200
+		reqBody starlark.Value
+	)
+	if err := starlark.UnpackArgs("mock_http", args, kwargs, "url", &url, "body?", &body, "method?", &method, "status?", &status, "request_body?", &reqBody); err != nil {
+		return starlark.None, err
+	}
+
+	data, err := mockBodyBytes(body)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	i := &HTTPInteraction{
+		Method:     strings.ToUpper(method.GoString()),
+		URL:        url.GoString(),
+		StatusCode: status,
+		Body:       data,
+		anyBody:    reqBody == nil || reqBody == starlark.None,
+	}
+	if !i.anyBody {
+		reqData, err := mockBodyBytes(reqBody)
+		if err != nil {
+			return starlark.None, err
+		}
+		i.RequestBodyHash = bodyHash(reqData)
+	}
+	tr.fixture.add(i)
+	return starlark.None, nil
+}
+
+// mockBodyBytes encodes a mocked body. Strings are used as-is, other values
+// are encoded as JSON, the way the http module encodes json_body
+func mockBodyBytes(body starlark.Value) ([]byte, error) {
+	switch b := body.(type) {
+	case nil, starlark.NoneType:
+		return nil, nil
+	case starlark.String:
+		return []byte(b.GoString()), nil
+	default:
+		val, err := util.Unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(val)
+	}
+}
+
+func (tr *testRunner) runTransform(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var config starlark.Value
+	if err := starlark.UnpackArgs("run_transform", args, kwargs, "config?", &config); err != nil {
+		return starlark.None, err
+	}
+
+	next := &dataset.Dataset{Transform: &dataset.Transform{}}
+	if config != nil && config != starlark.None {
+		val, err := util.Unmarshal(config)
+		if err != nil {
+			return starlark.None, err
+		}
+		cfg, ok := val.(map[string]interface{})
+		if !ok {
+			return starlark.None, fmt.Errorf("run_transform config must be a dict")
+		}
+		next.Transform.Config = cfg
+	}
+	next.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", tr.script))
+
+	var prev *dataset.Dataset
+	if tr.prev != nil {
+		prev = tr.prev.dataset()
+	}
+
+	err := ExecScript(tr.ctx, next, prev,
+		SetErrWriter(tr.opts.ErrWriter),
+		ReplayHTTP(tr.fixture),
+		func(o *ExecOpts) {
+			o.ModuleLoader = tr.opts.ModuleLoader
+			o.Secrets = tr.opts.Secrets
+			o.DatasetLoader = tr.loadDataset
+		},
+	)
+	if err != nil {
+		return starlark.None, err
+	}
+
+	return skyds.NewDataset(next, nil).Methods(), nil
+}
+
+func (tr *testRunner) loadDataset(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+	ds, ok := tr.datasets[refstr]
+	if !ok {
+		return nil, fmt.Errorf("no mock dataset for %q. use mock_dataset to define one", refstr)
+	}
+	return ds.dataset(), nil
+}
+
+// mockDataset is a dataset defined by a test script
+type mockDataset struct {
+	meta   *dataset.Meta
+	schema map[string]interface{}
+	body   []byte
+}
+
+// dataset creates a dataset from the mock with an unread body file
+func (m *mockDataset) dataset() *dataset.Dataset {
+	ds := &dataset.Dataset{Meta: m.meta}
+	if m.body != nil {
+		ds.Structure = &dataset.Structure{Format: "json", Schema: m.schema}
+		ds.SetBodyFile(qfs.NewMemfileBytes("body.json", m.body))
+	}
+	return ds
+}
+
+// newMockDataset builds a dataset with a JSON body from starlark values
+func newMockDataset(body, meta starlark.Value) (*mockDataset, error) {
+	ds := &mockDataset{}
+
+	if meta != nil && meta != starlark.None {
+		val, err := util.Unmarshal(meta)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+		ds.meta = &dataset.Meta{}
+		if err = json.Unmarshal(data, ds.meta); err != nil {
+			return nil, fmt.Errorf("invalid meta: %s", err)
+		}
+	}
+
+	if body != nil && body != starlark.None {
+		val, err := util.Unmarshal(body)
+		if err != nil {
+			return nil, err
+		}
+		ds.schema = dataset.BaseSchemaArray
+		if _, ok := val.(map[string]interface{}); ok {
+			ds.schema = dataset.BaseSchemaObject
+		}
+		if ds.body, err = json.Marshal(val); err != nil {
+			return nil, err
+		}
+	}
+
+	return ds, nil
+}
+
+func assertEq(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var a, b starlark.Value
+	if err := starlark.UnpackPositionalArgs("assert.eq", args, kwargs, 2, &a, &b); err != nil {
+		return starlark.None, err
+	}
+	eq, err := starlark.Equal(a, b)
+	if err != nil {
+		return starlark.None, err
+	}
+	if !eq {
+		return starlark.None, fmt.Errorf("assertion failed: %s != %s", a, b)
+	}
+	return starlark.None, nil
+}
+
+func assertNe(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var a, b starlark.Value
+	if err := starlark.UnpackPositionalArgs("assert.ne", args, kwargs, 2, &a, &b); err != nil {
+		return starlark.None, err
+	}
+	eq, err := starlark.Equal(a, b)
+	if err != nil {
+		return starlark.None, err
+	}
+	if eq {
+		return starlark.None, fmt.Errorf("assertion failed: %s == %s", a, b)
+	}
+	return starlark.None, nil
+}
+
+func assertTrue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		cond starlark.Value
+		msg  = starlark.String("assertion failed")
+	)
+	if err := starlark.UnpackArgs("assert.true", args, kwargs, "cond", &cond, "msg?", &msg); err != nil {
+		return starlark.None, err
+	}
+	if !cond.Truth() {
+		return starlark.None, fmt.Errorf("%s", msg.GoString())
+	}
+	return starlark.None, nil
+}
+
+func assertFails(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fn starlark.Callable
+	if err := starlark.UnpackPositionalArgs("assert.fails", args, kwargs, 1, &fn); err != nil {
+		return starlark.None, err
+	}
+	if _, err := starlark.Call(thread, fn, nil, nil); err == nil {
+		return starlark.None, fmt.Errorf("assertion failed: expected %s to fail", fn.Name())
+	}
+	return starlark.None, nil
+}
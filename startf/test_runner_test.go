@@ -0,0 +1,55 @@
+package startf
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunTests(t *testing.T) {
+	ctx := context.Background()
+	results, err := RunTests(ctx, scriptFile(t, "testdata/mock_tf.star"), scriptFile(t, "testdata/mock_tf_test.star"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []struct {
+		name   string
+		passed bool
+	}{
+		{"test_combines_download_and_dataset", true},
+		{"test_fails_without_mocks", true},
+		{"test_wrong_body", false},
+	}
+	if len(results) != len(expect) {
+		t.Fatalf("expected %d results, got: %d", len(expect), len(results))
+	}
+	for i, e := range expect {
+		if results[i].Name != e.name {
+			t.Errorf("result %d name mismatch. expected: %q, got: %q", i, e.name, results[i].Name)
+		}
+		if results[i].Passed != e.passed {
+			t.Errorf("result %d (%s) passed mismatch. expected: %t, got: %t. error: %s", i, e.name, e.passed, results[i].Passed, results[i].Error)
+		}
+	}
+
+	if !strings.Contains(results[2].Error, "mock_tf_test.star") {
+		t.Errorf("expected failure to include a backtrace, got: %q", results[2].Error)
+	}
+}
+
+func TestRunTestsMockRequestBody(t *testing.T) {
+	ctx := context.Background()
+	results, err := RunTests(ctx, scriptFile(t, "testdata/post_tf.star"), scriptFile(t, "testdata/post_tf_test.star"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if !r.Passed {
+			t.Errorf("%s failed: %s", r.Name, r.Error)
+		}
+	}
+	if len(results) != 3 {
+		t.Errorf("expected 3 results, got: %d", len(results))
+	}
+}
@@ -8,16 +8,19 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
 	skyctx "github.com/qri-io/qri/startf/context"
 	skyds "github.com/qri-io/qri/startf/ds"
 	skyqri "github.com/qri-io/qri/startf/qri"
 	"github.com/qri-io/qri/version"
 	"github.com/qri-io/starlib"
+	starhttp "github.com/qri-io/starlib/http"
 	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
 )
@@ -47,6 +50,15 @@ type ExecOpts struct {
 	ErrWriter io.Writer
 	// starlark module loader function
 	ModuleLoader ModuleLoader
+	// record all http interactions made through the http module to this fixture
+	HTTPRecord *HTTPFixture
+	// answer http requests from this fixture instead of the network
+	HTTPReplay *HTTPFixture
+	// DatasetLoader replaces loading datasets from the repo when set
+	DatasetLoader func(ctx context.Context, refstr string) (*dataset.Dataset, error)
+	// PinnedDatasets are versions load_dataset loads instead of the head of
+	// the same dataset
+	PinnedDatasets []reporef.DatasetRef
 }
 
 // AddQriRepo adds a qri repo to execution options, providing scripted access
@@ -89,6 +101,31 @@ func SetSecrets(secrets map[string]string) func(o *ExecOpts) {
 	}
 }
 
+// RecordHTTP captures every http request & response made by the script
+// into the provided fixture
+func RecordHTTP(f *HTTPFixture) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.HTTPRecord = f
+	}
+}
+
+// ReplayHTTP answers http requests made by the script with responses
+// recorded in the provided fixture, disallowing network access
+func ReplayHTTP(f *HTTPFixture) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.HTTPReplay = f
+	}
+}
+
+// PinDatasets makes load_dataset load the given versions of datasets instead
+// of their current heads, usually the dependencies a previous run recorded.
+// Datasets without a pinned version load their head
+func PinDatasets(refs []reporef.DatasetRef) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.PinnedDatasets = refs
+	}
+}
+
 // DefaultExecOpts applies default options to an ExecOpts pointer
 func DefaultExecOpts(o *ExecOpts) {
 	o.AllowFloat = true
@@ -110,6 +147,9 @@ type transform struct {
 	bodyFile     qfs.File
 	stderr       io.Writer
 	moduleLoader ModuleLoader
+	httpClient   *http.Client
+	loadFunc     func(ctx context.Context, refstr string) (*dataset.Dataset, error)
+	pinned       []reporef.DatasetRef
 
 	download starlark.Iterable
 }
@@ -139,21 +179,13 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		opt(o)
 	}
 
-	// hoist execution settings to resolve package settings
-	resolve.AllowFloat = o.AllowFloat
-	resolve.AllowSet = o.AllowSet
-	resolve.AllowLambda = o.AllowLambda
-	resolve.AllowNestedDef = o.AllowNestedDef
-
-	// add error func to starlark environment
-	starlark.Universe["error"] = starlark.NewBuiltin("error", Error)
-	for key, val := range o.Globals {
-		starlark.Universe[key] = val
-	}
+	setupEnvironment(o)
 
 	// set transform details
 	next.Transform.Syntax = "starlark"
 	next.Transform.SyntaxVersion = Version
+	// datasets loaded by a previous run may no longer be dependencies
+	ClearDatasetResources(next.Transform)
 
 	script := next.Transform.ScriptFile()
 	// "tee" the script reader to avoid losing script data, as starlark.ExecFile
@@ -171,6 +203,14 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		checkFunc:    o.MutateFieldCheck,
 		stderr:       o.ErrWriter,
 		moduleLoader: o.ModuleLoader,
+		loadFunc:     o.DatasetLoader,
+		pinned:       o.PinnedDatasets,
+	}
+
+	if o.HTTPReplay != nil {
+		t.httpClient = o.HTTPReplay.ReplayingClient()
+	} else if o.HTTPRecord != nil {
+		t.httpClient = o.HTTPRecord.RecordingClient()
 	}
 
 	skyCtx := skyctx.NewContext(next.Transform.Config, o.Secrets)
@@ -221,6 +261,21 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 	return err
 }
 
+// setupEnvironment hoists execution settings to the resolve package &
+// starlark universe
+func setupEnvironment(o *ExecOpts) {
+	resolve.AllowFloat = o.AllowFloat
+	resolve.AllowSet = o.AllowSet
+	resolve.AllowLambda = o.AllowLambda
+	resolve.AllowNestedDef = o.AllowNestedDef
+
+	// add error func to starlark environment
+	starlark.Universe["error"] = starlark.NewBuiltin("error", Error)
+	for key, val := range o.Globals {
+		starlark.Universe[key] = val
+	}
+}
+
 // Error halts program execution with an error
 func Error(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var msg starlark.Value
@@ -324,6 +379,12 @@ func (t *transform) ModuleLoader(thread *starlark.Thread, module string) (dict s
 		return nil, fmt.Errorf("couldn't load module: %s", module)
 	}
 
+	if module == starhttp.ModuleName && t.httpClient != nil {
+		// build the http module around this transform's recording or replaying
+		// client
+		return loadHTTPModule(t.httpClient), nil
+	}
+
 	return t.moduleLoader(thread, module)
 }
 
@@ -334,7 +395,12 @@ func (t *transform) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, ar
 		return starlark.None, err
 	}
 
-	ds, err := t.loadDataset(t.ctx, refstr.GoString())
+	load := t.loadDataset
+	if t.loadFunc != nil {
+		load = t.loadFunc
+	}
+
+	ds, err := load(t.ctx, refstr.GoString())
 	if err != nil {
 		return starlark.None, err
 	}
@@ -351,7 +417,9 @@ func (t *transform) loadDataset(ctx context.Context, refstr string) (*dataset.Da
 	if err != nil {
 		return nil, err
 	}
-	if err := repo.CanonicalizeDatasetRef(t.repo, &ref); err != nil {
+	if pin, ok := t.pinnedVersion(ref); ok {
+		ref = pin
+	} else if err := repo.CanonicalizeDatasetRef(t.repo, &ref); err != nil {
 		return nil, err
 	}
 
@@ -366,14 +434,28 @@ func (t *transform) loadDataset(ctx context.Context, refstr string) (*dataset.Da
 		}
 	}
 
-	if t.next.Transform.Resources == nil {
-		t.next.Transform.Resources = map[string]*dataset.TransformResource{}
-	}
-	t.next.Transform.Resources[ref.Path] = &dataset.TransformResource{Path: ref.String()}
+	RecordDatasetResource(t.next.Transform, ref)
 
 	return ds, nil
 }
 
+// pinnedVersion returns the pinned version of a dataset reference that
+// doesn't name a version itself
+func (t *transform) pinnedVersion(ref reporef.DatasetRef) (reporef.DatasetRef, bool) {
+	if ref.Path != "" || len(t.pinned) == 0 {
+		return ref, false
+	}
+	if err := repo.CanonicalizeProfile(t.repo, &ref); err != nil {
+		return ref, false
+	}
+	for _, pin := range t.pinned {
+		if pin.Name == ref.Name && (pin.Peername == ref.Peername || (ref.ProfileID != "" && pin.ProfileID == ref.ProfileID)) {
+			return pin, true
+		}
+	}
+	return ref, false
+}
+
 // MutatedComponentsFunc returns a function for checking if a field has been
 // modified. it's a kind of data structure mutual exclusion lock
 // TODO (b5) - this should be refactored & expanded
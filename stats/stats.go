@@ -12,6 +12,7 @@ import (
 	logger "github.com/ipfs/go-log"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/metrics"
 	gonumfloats "gonum.org/v1/gonum/floats"
 	gonumstat "gonum.org/v1/gonum/stat"
 )
@@ -53,7 +54,9 @@ func (s *Stats) JSON(ctx context.Context, ds *dataset.Dataset) (r io.Reader, err
 	// `dataset.BodyFile()` since we must have a bodyFile in order to
 	// calculate the stats
 	if ds.Path != "" {
-		if r, err := s.cache.JSON(ctx, ds.Path); err == nil {
+		r, err := s.cache.JSON(ctx, ds.Path)
+		metrics.ObserveStatsCache(err == nil)
+		if err == nil {
 			return r, nil
 		}
 	}
@@ -2,8 +2,14 @@ package watchfs
 
 import (
 	"time"
+
+	"github.com/qri-io/qri/event"
 )
 
+// ETFilesysEvent type for changes to files in a watched working directory.
+// payload is a FilesysEvent
+var ETFilesysEvent = event.Topic("watchfs:filesysEvent")
+
 // EventType represents the type of event
 type EventType string
 
@@ -2,16 +2,24 @@ package watchfs
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	golog "github.com/ipfs/go-log"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/fsi"
 )
 
 var log = golog.Logger("watchfs")
 
+// DefaultDebounce is how long a file must go without new filesystem events
+// before the watcher reports a change to it
+const DefaultDebounce = 100 * time.Millisecond
+
 // EventPath stores information about a path that is capable of generating events
 type EventPath struct {
 	Path     string
@@ -19,6 +27,17 @@ type EventPath struct {
 	Dsname   string
 }
 
+// alias returns the dataset reference alias for the path
+func (ep EventPath) alias() string {
+	return ep.Username + "/" + ep.Dsname
+}
+
+// LinkModifier changes the directory a dataset is linked to. *fsi.FSI
+// satisfies this interface
+type LinkModifier interface {
+	ModifyLinkDirectory(dirPath, refStr string) error
+}
+
 // FilesysWatcher will watch a set of directory paths, and send messages on a channel for events
 // concerning those paths. These events are:
 // * A new file in one of those paths was created
@@ -26,11 +45,43 @@ type EventPath struct {
 // * An existing file was deleted
 // * One of the folders being watched was renamed, but that folder is still being watched
 // * One of the folders was removed, which makes it no longer watched
-// TODO(dlong): Folder rename and remove are not supported yet.
+// Bursts of events for the same file are coalesced into a single event once
+// the file has been quiet for the Debounce duration. Folder renames are
+// detected by watching the parent of each folder, and are only followed within
+// the same parent
 type FilesysWatcher struct {
 	Watcher *fsnotify.Watcher
 	Sender  chan FilesysEvent
 	Assoc   map[string]EventPath
+	// Debounce is the quiet period that ends a burst of events for a file
+	Debounce time.Duration
+
+	lk      sync.Mutex
+	parents map[string]int
+	pending map[string]*pendingEvent
+	// files known to exist in watched folders
+	files map[string]bool
+	moves map[string]*pendingMove
+	links LinkModifier
+
+	// ctx ends the watcher, no more events are queued or sent once it's done
+	ctx context.Context
+	// events waiting to be sent on Sender, in the order they happened. A
+	// single goroutine sends them so callers never block on a reader
+	outLk  sync.Mutex
+	out    []FilesysEvent
+	outSig chan struct{}
+}
+
+// pendingEvent is a burst of events for a single file that hasn't been sent
+type pendingEvent struct {
+	timer *time.Timer
+}
+
+// pendingMove is a watched folder that was renamed, waiting to reappear
+type pendingMove struct {
+	ep    EventPath
+	timer *time.Timer
 }
 
 // NewFilesysWatcher returns a new FilesysWatcher
@@ -40,13 +91,31 @@ func NewFilesysWatcher(ctx context.Context, bus event.Bus) *FilesysWatcher {
 		log.Fatal(err)
 	}
 
-	w := FilesysWatcher{Watcher: watcher}
+	w := FilesysWatcher{
+		Watcher:  watcher,
+		ctx:      ctx,
+		Assoc:    map[string]EventPath{},
+		Debounce: DefaultDebounce,
+		parents:  map[string]int{},
+		pending:  map[string]*pendingEvent{},
+		files:    map[string]bool{},
+		moves:    map[string]*pendingMove{},
+		outSig:   make(chan struct{}, 1),
+	}
 	if bus != nil {
 		w.subscribe(ctx, bus)
 	}
 	return &w
 }
 
+// FollowMoves updates the linked directory of a dataset with m each time a
+// watched folder is renamed
+func (w *FilesysWatcher) FollowMoves(m LinkModifier) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	w.links = m
+}
+
 func (w *FilesysWatcher) subscribe(ctx context.Context, bus event.Bus) {
 	eventsCh := bus.Subscribe(event.ETFSICreateLinkEvent)
 	go func() {
@@ -77,41 +146,26 @@ func (w *FilesysWatcher) subscribe(ctx context.Context, bus event.Bus) {
 
 // Begin will start watching the given directory paths
 func (w *FilesysWatcher) Begin(paths []EventPath) chan FilesysEvent {
-	// Associate paths with additional information
-	assoc := make(map[string]EventPath)
+	messages := make(chan FilesysEvent)
+	w.Sender = messages
+	go w.deliver()
 
 	for _, p := range paths {
-		err := w.Watcher.Add(p.Path)
-		if err != nil {
-			log.Errorf("%s", err)
-		}
-		assoc[p.Path] = p
+		w.Add(p)
 	}
 
-	messages := make(chan FilesysEvent)
-	w.Sender = messages
-	w.Assoc = assoc
-
 	// Dispatch filesystem events
 	go func() {
 		for {
 			select {
+			case <-w.ctx.Done():
+				return
 			case event, ok := <-w.Watcher.Events:
 				if !ok {
 					log.Debugf("error getting event")
-					continue
-				}
-
-				if event.Op == fsnotify.Chmod {
-					// Don't care about CHMOD, skip it
-					continue
-				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					w.sendEvent(ModifyFileEvent, event.Name, "")
-				}
-				if event.Op&fsnotify.Create == fsnotify.Create {
-					w.sendEvent(CreateNewFileEvent, event.Name, "")
+					return
 				}
+				w.handle(event)
 			}
 		}
 	}()
@@ -121,15 +175,229 @@ func (w *FilesysWatcher) Begin(paths []EventPath) chan FilesysEvent {
 
 // Add starts watching an additional path
 func (w *FilesysWatcher) Add(path EventPath) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	w.add(path)
+}
+
+// Remove stops watching a path
+func (w *FilesysWatcher) Remove(path string) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+	w.remove(path)
+}
+
+func (w *FilesysWatcher) add(path EventPath) {
+	if _, ok := w.Assoc[path.Path]; ok {
+		return
+	}
+	if err := w.Watcher.Add(path.Path); err != nil {
+		log.Errorf("%s", err)
+	}
 	w.Assoc[path.Path] = path
-	w.Watcher.Add(path.Path)
+	if finfos, err := ioutil.ReadDir(path.Path); err == nil {
+		for _, fi := range finfos {
+			w.files[filepath.Join(path.Path, fi.Name())] = true
+		}
+	}
+
+	// watch the parent to see the folder itself get renamed
+	parent := filepath.Dir(path.Path)
+	if w.parents[parent] == 0 {
+		if _, watched := w.Assoc[parent]; !watched {
+			if err := w.Watcher.Add(parent); err != nil {
+				log.Debugf("watching parent directory %q: %s", parent, err)
+			}
+		}
+	}
+	w.parents[parent]++
+}
+
+func (w *FilesysWatcher) remove(path string) {
+	if _, ok := w.Assoc[path]; !ok {
+		return
+	}
+	delete(w.Assoc, path)
+	for f := range w.files {
+		if filepath.Dir(f) == path {
+			delete(w.files, f)
+		}
+	}
+	// the watch is already gone if the folder was removed or renamed
+	_ = w.Watcher.Remove(path)
+
+	parent := filepath.Dir(path)
+	if w.parents[parent]--; w.parents[parent] <= 0 {
+		delete(w.parents, parent)
+		if _, watched := w.Assoc[parent]; !watched {
+			_ = w.Watcher.Remove(parent)
+		}
+	}
+}
+
+// handle processes a single raw filesystem event
+func (w *FilesysWatcher) handle(e fsnotify.Event) {
+	if e.Op == fsnotify.Chmod {
+		// Don't care about CHMOD, skip it
+		return
+	}
+
+	w.lk.Lock()
+	moved := w.route(e)
+	links := w.links
+	w.lk.Unlock()
+
+	if moved != nil {
+		// update the link outside the lock, fsi may take a while
+		if links != nil {
+			if err := links.ModifyLinkDirectory(moved.to.Path, moved.to.alias()); err != nil {
+				log.Errorf("following %s to %q: %s", moved.to.alias(), moved.to.Path, err)
+			}
+		}
+		w.sendEvent(RenameFolderEvent, moved.to, moved.from, moved.to.Path)
+	}
+}
+
+// folderMove is a watched folder that was followed to a new path
+type folderMove struct {
+	from string
+	to   EventPath
 }
 
-// sendEvent sends a message on the channel about an event
-func (w *FilesysWatcher) sendEvent(etype EventType, sour, dest string) {
+// route updates watcher state for an event, returning the folder move it
+// completes, if any. Callers must hold the watcher lock
+func (w *FilesysWatcher) route(e fsnotify.Event) *folderMove {
+	// events for a watched folder itself
+	if ep, ok := w.Assoc[e.Name]; ok {
+		if e.Op&fsnotify.Remove == fsnotify.Remove {
+			if _, moving := w.moves[e.Name]; !moving {
+				w.remove(e.Name)
+				w.sendEvent(RemoveFolderEvent, ep, e.Name, "")
+			}
+		} else if e.Op&fsnotify.Rename == fsnotify.Rename {
+			w.beginMove(ep)
+		}
+		return nil
+	}
+
+	// a folder created next to a renamed folder may be its new location
+	if e.Op&fsnotify.Create == fsnotify.Create && len(w.moves) > 0 {
+		if fi, err := os.Stat(e.Name); err == nil && fi.IsDir() {
+			if moved := w.completeMove(e.Name); moved != nil {
+				return moved
+			}
+		}
+	}
+
+	// ignore everything else that happens in parent folders
+	ep, ok := w.Assoc[filepath.Dir(e.Name)]
+	if !ok {
+		return nil
+	}
+
+	w.debounce(ep, e.Name)
+	return nil
+}
+
+// debounce adds an event to the pending burst for a file, sending the burst
+// once the file goes quiet
+func (w *FilesysWatcher) debounce(ep EventPath, path string) {
+	if p, ok := w.pending[path]; ok {
+		p.timer.Reset(w.Debounce)
+		return
+	}
+	w.pending[path] = &pendingEvent{
+		timer: time.AfterFunc(w.Debounce, func() { w.flush(ep, path) }),
+	}
+}
+
+// flush sends the pending burst for a file. Instead of replaying each event,
+// the burst is summarized by comparing whether the file existed before the
+// burst with whether it exists now. An editor that saves by renaming a temp
+// file over the original produces a single modify event, and a temp file
+// that comes & goes produces none
+func (w *FilesysWatcher) flush(ep EventPath, path string) {
+	_, err := os.Stat(path)
+	exists := err == nil
+
+	w.lk.Lock()
+	_, ok := w.pending[path]
+	delete(w.pending, path)
+	existed := w.files[path]
+	if exists {
+		w.files[path] = true
+	} else {
+		delete(w.files, path)
+	}
+	w.lk.Unlock()
+
+	if !ok {
+		return
+	}
+
+	switch {
+	case !existed && exists:
+		w.sendEvent(CreateNewFileEvent, ep, path, "")
+	case existed && exists:
+		w.sendEvent(ModifyFileEvent, ep, path, "")
+	case existed && !exists:
+		w.sendEvent(DeleteFileEvent, ep, path, "")
+	}
+}
+
+// beginMove records a watched folder that was renamed away. If it doesn't
+// reappear nearby before the move window closes, it's treated as removed
+func (w *FilesysWatcher) beginMove(ep EventPath) {
+	if _, ok := w.moves[ep.Path]; ok {
+		return
+	}
+	window := w.Debounce * 10
+	w.moves[ep.Path] = &pendingMove{
+		ep: ep,
+		timer: time.AfterFunc(window, func() {
+			w.lk.Lock()
+			_, ok := w.moves[ep.Path]
+			delete(w.moves, ep.Path)
+			if ok {
+				w.remove(ep.Path)
+			}
+			w.lk.Unlock()
+
+			if ok {
+				w.sendEvent(RemoveFolderEvent, ep, ep.Path, "")
+			}
+		}),
+	}
+}
+
+// completeMove checks if a new folder is the destination of a pending move,
+// watching it in place of the old path if so
+func (w *FilesysWatcher) completeMove(dir string) *folderMove {
+	alias, ok := fsi.GetLinkedFilesysRef(dir)
+	if !ok {
+		return nil
+	}
+
+	for from, m := range w.moves {
+		if m.ep.alias() != alias {
+			continue
+		}
+		m.timer.Stop()
+		delete(w.moves, from)
+
+		w.remove(from)
+		to := m.ep
+		to.Path = dir
+		w.add(to)
+		return &folderMove{from: from, to: to}
+	}
+	return nil
+}
+
+// sendEvent queues a message about an event to be sent on the channel. It
+// never blocks, so it's safe to call while holding the watcher lock
+func (w *FilesysWatcher) sendEvent(etype EventType, ep EventPath, sour, dest string) {
 	log.Debugf("filesystem event %q %s -> %s\n", etype, sour, dest)
-	dir := filepath.Dir(sour)
-	ep := w.Assoc[dir]
 	event := FilesysEvent{
 		Type:        etype,
 		Username:    ep.Username,
@@ -138,5 +406,48 @@ func (w *FilesysWatcher) sendEvent(etype EventType, sour, dest string) {
 		Destination: dest,
 		Time:        time.Now(),
 	}
-	w.Sender <- event
+
+	if w.ctx.Err() != nil {
+		return
+	}
+	w.outLk.Lock()
+	w.out = append(w.out, event)
+	w.outLk.Unlock()
+	select {
+	case w.outSig <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends queued events on the channel one at a time, in order, until
+// the watcher's context is done
+func (w *FilesysWatcher) deliver() {
+	defer func() {
+		w.outLk.Lock()
+		w.out = nil
+		w.outLk.Unlock()
+	}()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-w.outSig:
+		}
+		for {
+			w.outLk.Lock()
+			if len(w.out) == 0 {
+				w.outLk.Unlock()
+				break
+			}
+			event := w.out[0]
+			w.out = w.out[1:]
+			w.outLk.Unlock()
+
+			select {
+			case w.Sender <- event:
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}
 }
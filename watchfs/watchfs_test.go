@@ -5,7 +5,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -50,3 +52,153 @@ func TestFilesysWatcher(t *testing.T) {
 		t.Errorf("filesys event (-want +got):\n%s", diff)
 	}
 }
+
+func TestFilesysWatcherDebounce(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "watchfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	watchdir := filepath.Join(tmpdir, "watch_me")
+	_ = os.Mkdir(watchdir, 0755)
+	target := filepath.Join(watchdir, "meta.json")
+	if err := ioutil.WriteFile(target, []byte("{}"), os.FileMode(0644)); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewFilesysWatcher(context.Background(), nil)
+	messages := w.Begin([]EventPath{{Username: "test_peer", Dsname: "ds_name", Path: watchdir}})
+
+	// save the way editors do, writing a temp file & renaming it over the
+	// original. expect a single modify event
+	tmp := filepath.Join(watchdir, ".meta.json.swp")
+	if err := ioutil.WriteFile(tmp, []byte(`{"title":"new"}`), os.FileMode(0644)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		t.Fatal(err)
+	}
+	got := <-messages
+	if got.Type != ModifyFileEvent || got.Source != target {
+		t.Errorf("expected modify event for %s, got: %s %s", target, got.Type, got.Source)
+	}
+
+	if err := os.Remove(target); err != nil {
+		t.Fatal(err)
+	}
+	got = <-messages
+	if got.Type != DeleteFileEvent || got.Source != target {
+		t.Errorf("expected delete event for %s, got: %s %s", target, got.Type, got.Source)
+	}
+}
+
+type fakeLinks struct {
+	dir, ref string
+}
+
+func (f *fakeLinks) ModifyLinkDirectory(dir, ref string) error {
+	f.dir, f.ref = dir, ref
+	return nil
+}
+
+func TestFilesysWatcherFolderEvents(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "watchfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	watchdir := filepath.Join(tmpdir, "watch_me")
+	_ = os.Mkdir(watchdir, 0755)
+	if err := ioutil.WriteFile(filepath.Join(watchdir, ".qri-ref"), []byte("test_peer/ds_name"), os.FileMode(0644)); err != nil {
+		t.Fatal(err)
+	}
+
+	links := &fakeLinks{}
+	w := NewFilesysWatcher(context.Background(), nil)
+	w.FollowMoves(links)
+	messages := w.Begin([]EventPath{{Username: "test_peer", Dsname: "ds_name", Path: watchdir}})
+
+	moved := filepath.Join(tmpdir, "moved")
+	if err := os.Rename(watchdir, moved); err != nil {
+		t.Fatal(err)
+	}
+	got := <-messages
+	expect := FilesysEvent{
+		Type:        RenameFolderEvent,
+		Username:    "test_peer",
+		Dsname:      "ds_name",
+		Source:      watchdir,
+		Destination: moved,
+		Time:        got.Time,
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("rename event (-want +got):\n%s", diff)
+	}
+	if links.dir != moved || links.ref != "test_peer/ds_name" {
+		t.Errorf("expected link to follow move to %q, got: %q %q", moved, links.dir, links.ref)
+	}
+
+	if err := os.RemoveAll(moved); err != nil {
+		t.Fatal(err)
+	}
+	for got = range messages {
+		if got.Type == RemoveFolderEvent {
+			break
+		}
+	}
+	if got.Source != moved {
+		t.Errorf("expected remove event for %s, got: %s", moved, got.Source)
+	}
+}
+
+func TestFilesysWatcherEventOrder(t *testing.T) {
+	w := NewFilesysWatcher(context.Background(), nil)
+	messages := w.Begin(nil)
+
+	ep := EventPath{Username: "test_peer", Dsname: "ds_name", Path: "/watch_me"}
+	expect := []EventType{CreateNewFileEvent, ModifyFileEvent, DeleteFileEvent, RenameFolderEvent, RemoveFolderEvent}
+	// queue every event before reading any, sending must not block
+	for i, et := range expect {
+		w.sendEvent(et, ep, strconv.Itoa(i), "")
+	}
+	for i, et := range expect {
+		got := <-messages
+		if got.Type != et || got.Source != strconv.Itoa(i) {
+			t.Errorf("event %d: expected %s %d, got: %s %s", i, et, i, got.Type, got.Source)
+		}
+	}
+}
+
+func TestFilesysWatcherStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewFilesysWatcher(ctx, nil)
+	defer w.Watcher.Close()
+	w.Begin(nil)
+
+	ep := EventPath{Username: "test_peer", Dsname: "ds_name", Path: "/watch_me"}
+	// nothing reads these, so delivery blocks until the context ends
+	for i := 0; i < 3; i++ {
+		w.sendEvent(ModifyFileEvent, ep, strconv.Itoa(i), "")
+	}
+	cancel()
+
+	queued := func() int {
+		w.outLk.Lock()
+		defer w.outLk.Unlock()
+		return len(w.out)
+	}
+	deadline := time.Now().Add(time.Second)
+	for queued() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected queued events to be dropped, %d remain", queued())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w.sendEvent(ModifyFileEvent, ep, "after", "")
+	if n := queued(); n != 0 {
+		t.Errorf("expected no events queued after context ends, got: %d", n)
+	}
+}